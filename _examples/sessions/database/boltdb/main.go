@@ -35,7 +35,7 @@ func main() {
 	// 重要：
 	// IMPORTANT:
 	//
-	sess.UseDatabase(db)
+	sess.UseDatabaseV2(db)
 	//其余代码保持不变
 
 	// the rest of the code stays the same.
@@ -1,7 +1,9 @@
 package cache_test
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -102,3 +104,66 @@ func TestETag(t *testing.T) {
 	r.Header("ETag").Equal("/") // test if header set.
 	r.Body().Equal("__")
 }
+
+func TestDynamicETag(t *testing.T) {
+	t.Parallel()
+
+	app := iris.New()
+	var (
+		user    = iris.Map{"username": "kataras"}
+		version = 1
+		gets    = 0
+	)
+
+	api := app.Party("/api", cache.DynamicETag(false, func(ctx iris.Context) (string, bool) {
+		return fmt.Sprintf(`"v%d"`, version), true
+	}))
+	api.Get("/user", func(ctx iris.Context) {
+		gets++
+		ctx.JSON(user)
+	})
+	api.Put("/user", func(ctx iris.Context) {
+		ctx.ReadJSON(&user)
+		version++
+		ctx.StatusCode(iris.StatusNoContent)
+	})
+
+	weak := app.Party("/weak", cache.DynamicETag(true))
+	weak.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("weak")
+	})
+	weak.Put("/", func(ctx iris.Context) {
+		ctx.StatusCode(iris.StatusNoContent)
+	})
+
+	e := httptest.New(t, app)
+
+	etag := e.GET("/api/user").Expect().Status(httptest.StatusOK).Header("ETag").Equal(`"v1"`).Raw()
+	e.GET("/api/user").WithHeader("If-None-Match", etag).Expect().
+		Status(httptest.StatusNotModified).Body().Equal("")
+	e.GET("/api/user").WithHeader("If-None-Match", `"other"`).Expect().
+		Status(httptest.StatusOK).Body().NotEmpty()
+
+	// lost update, the resource has been modified by someone else.
+	e.PUT("/api/user").WithHeader("If-Match", `"other"`).WithJSON(iris.Map{"username": "makis"}).Expect().
+		Status(httptest.StatusPreconditionFailed)
+	e.PUT("/api/user").WithHeader("If-Match", etag).WithJSON(iris.Map{"username": "makis"}).Expect().
+		Status(httptest.StatusNoContent)
+	e.PUT("/api/user").WithHeader("If-Match", etag).WithJSON(iris.Map{"username": "kataras"}).Expect().
+		Status(httptest.StatusPreconditionFailed)
+
+	e.GET("/api/user").Expect().Status(httptest.StatusOK).Header("ETag").Equal(`"v2"`)
+	// the preconditions are evaluated without executing the GET handler.
+	if gets != 4 {
+		t.Fatalf("expected the GET handler to be executed 4 times but executed %d", gets)
+	}
+
+	weakETag := e.GET("/weak").Expect().Status(httptest.StatusOK).Header("ETag").Raw()
+	if !strings.HasPrefix(weakETag, "W/") {
+		t.Fatalf("expected a weak ETag but got: %s", weakETag)
+	}
+	e.GET("/weak").WithHeader("If-None-Match", strings.TrimPrefix(weakETag, "W/")).Expect().
+		Status(httptest.StatusNotModified)
+	// without a current ETag function the "If-Match" is not evaluated.
+	e.PUT("/weak").WithHeader("If-Match", `"other"`).Expect().Status(httptest.StatusNoContent)
+}
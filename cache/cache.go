@@ -44,7 +44,9 @@ import (
 // Use it for server-side caching, see the `iris#Cache304` for an alternative approach that
 // may be more suited to your needs.
 //
-// You can add validators with this function.
+// You can add validators with this function
+// and change the storage backend of the cached responses through its `Store` method,
+// see the `cache/store` package for the built-in stores.
 func Cache(expiration time.Duration) *client.Handler {
 	return client.NewHandler(expiration)
 }
@@ -3,6 +3,7 @@ package cache_test
 import (
 	"fmt"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/kataras/iris/v12/cache"
 	"github.com/kataras/iris/v12/cache/client"
 	"github.com/kataras/iris/v12/cache/client/rule"
+	"github.com/kataras/iris/v12/cache/store"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/context"
@@ -210,3 +212,289 @@ func TestCacheValidator(t *testing.T) {
 		t.Fatalf("%s: %v", t.Name(), &testError{3, counter})
 	}
 }
+
+func TestCacheStore(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	s := store.NewMemory(1)
+	h := func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.WriteString(ctx.Path())
+	}
+
+	app.Get("/{name}", cache.Cache(cacheDuration).Store(s).ServeHTTP, h)
+
+	e := httptest.New(t, app)
+	e.GET("/a").Expect().Status(http.StatusOK).Body().Equal("/a")
+	e.GET("/a").Expect().Status(http.StatusOK).Body().Equal("/a")
+	if counter := atomic.LoadUint32(&n); counter != 1 {
+		t.Fatalf("%s: %v", t.Name(), &testError{1, counter})
+	}
+
+	// "/a" is evicted, the store can hold a single entry.
+	e.GET("/b").Expect().Status(http.StatusOK).Body().Equal("/b")
+	e.GET("/a").Expect().Status(http.StatusOK).Body().Equal("/a")
+	if counter := atomic.LoadUint32(&n); counter != 3 {
+		t.Fatalf("%s: %v", t.Name(), &testError{3, counter})
+	}
+
+	if expected, got := 1, s.Len(); expected != got {
+		t.Fatalf("%s: expected store length to be: %d but got: %d", t.Name(), expected, got)
+	}
+
+	s.Purge()
+	e.GET("/a").Expect().Status(http.StatusOK).Body().Equal("/a")
+	if counter := atomic.LoadUint32(&n); counter != 4 {
+		t.Fatalf("%s: %v", t.Name(), &testError{4, counter})
+	}
+}
+
+func TestCacheVary(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	c := cache.Cache(cacheDuration)
+	app.Get("/", c.ServeHTTP, func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.Header(context.VaryHeaderKey, "Accept-Language")
+		ctx.WriteString(ctx.GetHeader("Accept-Language"))
+	}).Name = "vary"
+
+	app.Get("/any", cache.Handler(cacheDuration), func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.Header(context.VaryHeaderKey, "*")
+		ctx.WriteString("any")
+	})
+
+	e := httptest.New(t, app)
+	for i := 0; i < 2; i++ {
+		e.GET("/").WithHeader("Accept-Language", "en-US").Expect().Status(http.StatusOK).
+			Header(context.VaryHeaderKey).Equal("Accept-Language")
+		e.GET("/").WithHeader("Accept-Language", "en-US").Expect().Status(http.StatusOK).Body().Equal("en-US")
+		e.GET("/").WithHeader("Accept-Language", "el-GR").Expect().Status(http.StatusOK).Body().Equal("el-GR")
+	}
+
+	if counter := atomic.LoadUint32(&n); counter != 2 {
+		t.Fatalf("%s: %v", t.Name(), &testError{2, counter})
+	}
+
+	// the index entry of the variants is not counted.
+	if removed := c.Manager().PurgeRoute("vary"); removed != 2 {
+		t.Fatalf("%s: expected to purge the two variants but purged: %d", t.Name(), removed)
+	}
+	e.GET("/").WithHeader("Accept-Language", "en-US").Expect().Status(http.StatusOK).Body().Equal("en-US")
+	if counter := atomic.LoadUint32(&n); counter != 3 {
+		t.Fatalf("%s: %v", t.Name(), &testError{3, counter})
+	}
+
+	e.GET("/any").Expect().Status(http.StatusOK).Body().Equal("any")
+	e.GET("/any").Expect().Status(http.StatusOK).Body().Equal("any")
+	if counter := atomic.LoadUint32(&n); counter != 5 {
+		t.Fatalf("%s: %v", t.Name(), &testError{5, counter})
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	// ignore the url query.
+	c := cache.Cache(cacheDuration).Key(func(ctx context.Context) string {
+		return ctx.Path()
+	})
+	app.Get("/", c.ServeHTTP, func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.WriteString(expectedBodyStr)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").WithQuery("page", 1).Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+	e.GET("/").WithQuery("page", 2).Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+	if counter := atomic.LoadUint32(&n); counter != 1 {
+		t.Fatalf("%s: %v", t.Name(), &testError{1, counter})
+	}
+}
+
+func TestCacheManager(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	c := cache.Cache(cacheDuration)
+	app.Get("/products/{id}", c.ServeHTTP, func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		client.Tag(ctx, "products", "product:"+ctx.Params().Get("id"))
+		ctx.WriteString(ctx.Params().Get("id"))
+	}).Name = "product"
+	app.Get("/about", c.ServeHTTP, func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.WriteString("about")
+	}).Name = "about"
+
+	e := httptest.New(t, app)
+	expectCounter := func(expected uint32) {
+		t.Helper()
+		if counter := atomic.LoadUint32(&n); counter != expected {
+			t.Fatalf("%s: %v", t.Name(), &testError{int(expected), counter})
+		}
+	}
+	request := func() {
+		e.GET("/products/1").Expect().Status(http.StatusOK).Body().Equal("1")
+		e.GET("/products/42").Expect().Status(http.StatusOK).Body().Equal("42")
+		e.GET("/about").Expect().Status(http.StatusOK).Body().Equal("about")
+	}
+
+	request()
+	request()
+	expectCounter(3)
+
+	m := c.Manager()
+	entries := m.Entries()
+	if expected, got := 3, len(entries); expected != got {
+		t.Fatalf("%s: expected %d entries but got %d", t.Name(), expected, got)
+	}
+	for _, entry := range entries {
+		if entry.Remaining <= 0 || entry.Remaining > cacheDuration {
+			t.Fatalf("%s: unexpected remaining lifetime of entry: %s: %s", t.Name(), entry.Key, entry.Remaining)
+		}
+	}
+
+	if removed := m.PurgeTag("product:42"); removed != 1 {
+		t.Fatalf("%s: expected to purge one entry by tag but purged: %d", t.Name(), removed)
+	}
+	request()
+	expectCounter(4)
+
+	if removed := m.PurgeRoute("about"); removed != 1 {
+		t.Fatalf("%s: expected to purge one entry by route but purged: %d", t.Name(), removed)
+	}
+	request()
+	expectCounter(5)
+
+	m.PurgeKey(entries[0].Key)
+	request()
+	expectCounter(6)
+
+	if removed := m.PurgeTag("products"); removed != 2 {
+		t.Fatalf("%s: expected to purge two entries by tag but purged: %d", t.Name(), removed)
+	}
+	request()
+	expectCounter(8)
+}
+
+func TestCacheCoalesce(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	app.Get("/", cache.Handler(cacheDuration), func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		time.Sleep(cacheDuration / 5)
+		ctx.WriteString(expectedBodyStr)
+	})
+
+	e := httptest.New(t, app)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.GET("/").Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+		}()
+	}
+	wg.Wait()
+
+	if counter := atomic.LoadUint32(&n); counter != 1 {
+		t.Fatalf("%s: %v", t.Name(), &testError{1, counter})
+	}
+}
+
+func TestCacheStale(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	var middleware uint32
+	swr := cache.Cache(cacheDuration).StaleWhileRevalidate(2 * cacheDuration)
+	app.Get("/swr", func(ctx context.Context) {
+		atomic.AddUint32(&middleware, 1)
+		ctx.Next()
+	}, swr.ServeHTTP, func(ctx context.Context) {
+		ctx.Writef("%d", atomic.AddUint32(&n, 1))
+	})
+
+	var failed uint32
+	sie := cache.Cache(cacheDuration).StaleIfError(2 * cacheDuration)
+	app.Get("/sie", sie.ServeHTTP, func(ctx context.Context) {
+		if atomic.LoadUint32(&failed) == 1 {
+			ctx.StatusCode(http.StatusServiceUnavailable)
+			return
+		}
+		ctx.WriteString(expectedBodyStr)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/swr").Expect().Status(http.StatusOK).Body().Equal("1")
+	e.GET("/sie").Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+	time.Sleep(cacheDuration + cacheDuration/5)
+
+	// expired, the stale response is served and a refresh is started on the background.
+	e.GET("/swr").Expect().Status(http.StatusOK).Body().Equal("1")
+	time.Sleep(cacheDuration / 5)
+	e.GET("/swr").Expect().Status(http.StatusOK).Body().Equal("2")
+	// the background refresh executes only the cached handler.
+	if got := atomic.LoadUint32(&middleware); got != 3 {
+		t.Fatalf("%s: expected the middleware to be executed 3 times but executed %d", t.Name(), got)
+	}
+
+	// expired and the original handler fails, the stale response is served.
+	atomic.StoreUint32(&failed, 1)
+	e.GET("/sie").Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+}
+
+func TestCacheStaleIfErrorCoalesce(t *testing.T) {
+	app := iris.New()
+	var (
+		n      uint32
+		failed uint32
+	)
+
+	sie := cache.Cache(cacheDuration).StaleIfError(2 * cacheDuration)
+	app.Get("/", sie.ServeHTTP, func(ctx context.Context) {
+		if atomic.LoadUint32(&failed) == 1 {
+			atomic.AddUint32(&n, 1)
+			time.Sleep(cacheDuration / 5)
+			ctx.StatusCode(http.StatusServiceUnavailable)
+			return
+		}
+		ctx.WriteString(expectedBodyStr)
+	})
+	app.Get("/error", sie.ServeHTTP, func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		time.Sleep(cacheDuration / 5)
+		ctx.StatusCode(http.StatusServiceUnavailable)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+	time.Sleep(cacheDuration + cacheDuration/5)
+	atomic.StoreUint32(&failed, 1)
+
+	// the waiting requests serve the stale response or the error of the single execution.
+	for path, expectedStatusCode := range map[string]int{"/": http.StatusOK, "/error": http.StatusServiceUnavailable} {
+		atomic.StoreUint32(&n, 0)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(path string, expectedStatusCode int) {
+				defer wg.Done()
+				e.GET(path).Expect().Status(expectedStatusCode)
+			}(path, expectedStatusCode)
+		}
+		wg.Wait()
+
+		if counter := atomic.LoadUint32(&n); counter != 1 {
+			t.Fatalf("%s: %s: %v", t.Name(), path, &testError{1, counter})
+		}
+	}
+}
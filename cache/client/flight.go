@@ -0,0 +1,133 @@
+package client
+
+import (
+	stdContext "context"
+	"net/http"
+	"sync"
+
+	"github.com/kataras/iris/v12/cache/entry"
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/memstore"
+)
+
+// flightGroup keeps track of the in-flight executions of a key.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg sync.WaitGroup
+	// response is the response of the leader that the waiters should serve as it is,
+	// it's set when the leader's response is not stored. Read it after `wait`.
+	response *flightResponse
+}
+
+// flightResponse is a copy of a recorded response.
+type flightResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+func newFlightResponse(recorder *context.ResponseRecorder) *flightResponse {
+	return &flightResponse{
+		statusCode: recorder.StatusCode(),
+		header:     recorder.Header().Clone(),
+		body:       append([]byte(nil), recorder.Body()...),
+	}
+}
+
+func (r *flightResponse) writeTo(ctx context.Context) {
+	entry.CopyHeaders(ctx.ResponseWriter().Header(), r.header)
+	ctx.StatusCode(r.statusCode)
+	ctx.Write(r.body)
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+// join returns the in-flight call of the "key".
+// If there is no in-flight call then a new one is started
+// and the caller is the leader, it should call `leave` when done.
+func (g *flightGroup) join(key string) (c *flightCall, leader bool) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return c, false
+	}
+
+	c = new(flightCall)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	return c, true
+}
+
+// leave marks the "c" call of the "key" as done and wakes up the waiters.
+func (g *flightGroup) leave(key string, c *flightCall) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	c.wg.Done()
+}
+
+// wait blocks until the leader of the call leaves.
+func (c *flightCall) wait() {
+	c.wg.Wait()
+}
+
+type revalidationContextKey struct{}
+
+// isRevalidation reports whether the request is
+// a background revalidation of a stale entry.
+func isRevalidation(ctx context.Context) bool {
+	v, _ := ctx.Request().Context().Value(revalidationContextKey{}).(bool)
+	return v
+}
+
+// revalidate refreshes the entry of the "key" on the background,
+// by executing the "handlers" (the cache handler and the next ones of the route)
+// with a copy of the current request, its route parameters and values.
+// The middleware that run before the cache handler are not executed again.
+func (h *Handler) revalidate(ctx context.Context, key string, handlers context.Handlers) {
+	c, leader := h.revalidations.join(key)
+	if !leader {
+		// already in progress.
+		return
+	}
+
+	r := ctx.Request().Clone(stdContext.WithValue(stdContext.Background(), revalidationContextKey{}, true))
+	r.Body = http.NoBody
+	var (
+		app       = ctx.Application()
+		routeName = ctx.RouteName()
+		params    = append(memstore.Store(nil), ctx.Params().Store...)
+		values    = append(memstore.Store(nil), *ctx.Values()...)
+	)
+	handlers = append(context.Handlers(nil), handlers...)
+
+	go func() {
+		defer h.revalidations.leave(key, c)
+
+		refreshCtx := context.NewContext(app)
+		refreshCtx.BeginRequest(&discardResponseWriter{header: make(http.Header)}, r)
+		refreshCtx.SetCurrentRouteName(routeName)
+		refreshCtx.Params().Store = params
+		*refreshCtx.Values() = values
+		refreshCtx.Do(handlers)
+		refreshCtx.EndRequest()
+	}()
+}
+
+// discardResponseWriter is the http.ResponseWriter of the background revalidations,
+// the response is stored by the cache handler, there is no client to send it to.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
@@ -1,11 +1,14 @@
 package client
 
 import (
-	"sync"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/kataras/iris/v12/cache/client/rule"
 	"github.com/kataras/iris/v12/cache/entry"
+	"github.com/kataras/iris/v12/cache/store"
 	"github.com/kataras/iris/v12/context"
 )
 
@@ -19,9 +22,37 @@ type Handler struct {
 	rule rule.Rule
 	// when expires.
 	expiration time.Duration
-	// entries the memory cache stored responses.
-	entries map[string]*entry.Entry
-	mu      sync.RWMutex
+	// store the storage backend of the cached responses.
+	store store.Store
+	// key the function which generates the entry's key for a request.
+	key KeyFunc
+
+	// coalesce reports whether concurrent requests of the same key
+	// should wait for a single execution of the original handler.
+	coalesce bool
+	inflight *flightGroup
+	// revalidations holds the keys that are being revalidated on the background.
+	revalidations *flightGroup
+
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+}
+
+// KeyFunc is the function which generates
+// the cache entry's key for the incoming request.
+// Requests with the same key share the same cached response,
+// see `Handler.Key`.
+type KeyFunc func(ctx context.Context) string
+
+// DefaultKey is the default `KeyFunc`,
+// the key is unique per scheme, host (subdomains too) and request URI (paths with different url query).
+func DefaultKey(ctx context.Context) string {
+	scheme := "http"
+	if ctx.Request().TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + ctx.Host() + ctx.Request().URL.RequestURI()
 }
 
 // NewHandler returns a new cached handler for the "bodyHandler"
@@ -30,10 +61,46 @@ func NewHandler(expiration time.Duration) *Handler {
 	return &Handler{
 		rule:       DefaultRuleSet,
 		expiration: expiration,
-		entries:    make(map[string]*entry.Entry),
+		store:      store.NewMemory(0),
+		key:        DefaultKey,
+		coalesce:   true,
+		inflight:   newFlightGroup(),
+
+		revalidations: newFlightGroup(),
 	}
 }
 
+// Key sets the function which generates the cache entry's key of a request,
+// i.e to ignore the url query or to include the user's role.
+// Note that the response's "Vary" header is always honored:
+// variants of the same key are stored separately based on the request header values it lists.
+// Defaults to `DefaultKey`.
+//
+// returns itself.
+func (h *Handler) Key(fn KeyFunc) *Handler {
+	if fn == nil {
+		fn = DefaultKey
+	}
+	h.key = fn
+
+	return h
+}
+
+// Store sets the storage backend of the cached responses,
+// i.e an entry-count bounded `store.NewMemory(1000)`, optionally byte bounded too
+// through its `MaxBytes`, or a `store.NewFileSystem("./cache")`.
+// Defaults to an unbounded in-memory store.
+//
+// returns itself.
+func (h *Handler) Store(s store.Store) *Handler {
+	if s == nil {
+		s = store.NewMemory(0)
+	}
+	h.store = s
+
+	return h
+}
+
 // Rule sets the ruleset for this handler.
 //
 // returns itself.
@@ -59,6 +126,48 @@ func (h *Handler) AddRule(r rule.Rule) *Handler {
 	return h
 }
 
+// Coalesce enables or disables the coalescing of concurrent requests.
+// When enabled, only one request per key executes the original handler
+// to regenerate an expired or missing entry, the rest of them wait for its result.
+// Defaults to true.
+//
+// returns itself.
+func (h *Handler) Coalesce(enable bool) *Handler {
+	h.coalesce = enable
+	return h
+}
+
+// StaleWhileRevalidate sets the duration that an expired entry
+// can be still served while it's being refreshed on the background, i.e 30 * time.Second.
+// Only one background refresh per key runs at the same time.
+// Defaults to zero, disabled.
+//
+// returns itself.
+func (h *Handler) StaleWhileRevalidate(d time.Duration) *Handler {
+	h.staleWhileRevalidate = d
+	return h
+}
+
+// StaleIfError sets the duration that an expired entry
+// can be still served when the original handler responds with a server error (status code >= 500).
+// Server error responses are never stored when it's enabled.
+// Defaults to zero, disabled.
+//
+// returns itself.
+func (h *Handler) StaleIfError(d time.Duration) *Handler {
+	h.staleIfError = d
+	return h
+}
+
+// maxStale returns the maximum duration that an expired entry can be still served.
+func (h *Handler) maxStale() time.Duration {
+	if h.staleWhileRevalidate > h.staleIfError {
+		return h.staleWhileRevalidate
+	}
+
+	return h.staleIfError
+}
+
 var emptyHandler = func(ctx context.Context) {
 	ctx.StatusCode(500)
 	ctx.WriteString("cache: empty body handler")
@@ -79,6 +188,8 @@ func (h *Handler) ServeHTTP(ctx context.Context) {
 		emptyHandler(ctx)
 		return
 	}
+	// this and the next handlers of the route, they are executed by a background revalidation.
+	handlers := ctx.Handlers()[ctx.HandlerIndex(-1):]
 	// skip prepares the context to move to the next handler if the "nextHandler" has a ctx.Next() inside it,
 	// even if it's not executed because it's cached.
 	ctx.Skip()
@@ -88,84 +199,191 @@ func (h *Handler) ServeHTTP(ctx context.Context) {
 		return
 	}
 
-	scheme := "http"
-	if ctx.Request().TLS != nil {
-		scheme = "https"
-	}
-
 	var (
-		response *entry.Response
-		valid    = false
-		// unique per subdomains and paths with different url query.
-		key = scheme + ctx.Host() + ctx.Request().URL.RequestURI()
+		key = h.key(ctx)
+		// a background revalidation of a stale entry should always execute the original handler.
+		revalidation = isRevalidation(ctx)
+		e            = h.get(ctx, key)
 	)
 
-	h.mu.RLock()
-	e, found := h.entries[key]
-	h.mu.RUnlock()
-
-	if found {
+	if e != nil && !revalidation {
 		// the entry is here, .Response will give us
 		// if it's expired or no
-		response, valid = e.Response()
-	} else {
-		// create the entry now.
-		// fmt.Printf("create new cache entry\n")
-		// fmt.Printf("key: %s\n", key)
-
-		e = entry.NewEntry(h.expiration)
-		h.mu.Lock()
-		h.entries[key] = e
-		h.mu.Unlock()
-	}
-
-	if !valid {
-		// if it's expired, then execute the original handler
-		// with our custom response recorder response writer
-		// because the net/http doesn't give us
-		// a builtin way to get the status code & body
-		recorder := ctx.Recorder()
-		bodyHandler(ctx)
-
-		// now that we have recordered the response,
-		// we are ready to check if that specific response is valid to be stored.
-
-		// check if it's a valid response, if it's not then just return.
-		if !h.rule.Valid(ctx) {
+		if response, valid := e.Response(); valid {
+			writeResponse(ctx, e, response)
 			return
 		}
 
-		// no need to copy the body, its already done inside
-		body := recorder.Body()
-		if len(body) == 0 {
-			// if no body then just exit.
+		if response, ok := e.StaleResponse(h.staleWhileRevalidate); ok {
+			h.revalidate(ctx, key, handlers)
+			writeResponse(ctx, e, response)
 			return
 		}
+	}
 
-		// check for an expiration time if the
-		// given expiration was not valid then check for GetMaxAge &
-		// update the response & release the recorder
-		e.Reset(
-			recorder.StatusCode(),
-			recorder.Header(),
-			body,
-			parseLifeChanger(ctx),
-		)
-
-		// fmt.Printf("reset cache entry\n")
-		// fmt.Printf("key: %s\n", key)
-		// fmt.Printf("content type: %s\n", recorder.Header().Get(cfg.ContentTypeHeader))
-		// fmt.Printf("body len: %d\n", len(body))
+	var call *flightCall
+	if h.coalesce {
+		// only one request per key executes the original handler,
+		// the rest of them wait and serve its result.
+		c, leader := h.inflight.join(key)
+		if !leader {
+			c.wait()
+
+			if e := h.get(ctx, key); e != nil {
+				if response, valid := e.Response(); valid {
+					writeResponse(ctx, e, response)
+					return
+				}
+			}
+
+			// the server error (or the stale response) of the leader, see `StaleIfError`.
+			if c.response != nil {
+				c.response.writeTo(ctx)
+				return
+			}
+			// the result was not cached, execute the original handler as usual.
+		} else {
+			call = c
+			defer h.inflight.leave(key, c)
+		}
+	}
+
+	// if it's expired, then execute the original handler
+	// with our custom response recorder response writer
+	// because the net/http doesn't give us
+	// a builtin way to get the status code & body
+	recorder := ctx.Recorder()
+	bodyHandler(ctx)
+
+	if h.staleIfError > 0 && recorder.StatusCode() >= http.StatusInternalServerError {
+		// don't replace a good response with an error one.
+		if e != nil && !revalidation {
+			if response, ok := e.StaleResponse(h.staleIfError); ok {
+				recorder.Reset()
+				writeResponse(ctx, e, response)
+			}
+		}
+
+		// the waiting requests serve the same response instead of executing the original handler.
+		if call != nil {
+			call.response = newFlightResponse(recorder)
+		}
+
+		return
+	}
+
+	// now that we have recordered the response,
+	// we are ready to check if that specific response is valid to be stored.
+
+	// check if it's a valid response, if it's not then just return.
+	if !h.rule.Valid(ctx) {
+		return
+	}
+
+	// no need to copy the body, its already done inside
+	body := recorder.Body()
+	if len(body) == 0 {
+		// if no body then just exit.
+		return
+	}
+
+	vary := varyKeys(recorder.Header())
+	if len(vary) > 0 && vary[0] == "*" {
+		// varies on things other than request headers, can't be served from cache.
 		return
 	}
 
-	// if it's valid then just write the cached results
+	// check for an expiration time if the
+	// given expiration was not valid then check for GetMaxAge &
+	// update the response & release the recorder.
+	// A new entry is created so readers of the previous one are not affected.
+	e = entry.NewEntry(h.expiration)
+	e.Reset(
+		recorder.StatusCode(),
+		recorder.Header(),
+		body,
+		parseLifeChanger(ctx),
+	)
+	e.Route = ctx.RouteName()
+	e.Tags = Tags(ctx)
+
+	// keep the entry for as long as it can be served stale too.
+	ttl := e.Lifetime() + h.maxStale()
+	if len(vary) > 0 {
+		h.store.Set(variantKey(key, vary, ctx.Request()), e, ttl)
+	}
+	h.store.Set(key, e, ttl)
+}
+
+// get returns the stored entry of the "key" for this request,
+// even if it's expired but it can be still served stale.
+// Returns nil if not found.
+func (h *Handler) get(ctx context.Context, key string) *entry.Entry {
+	e := h.store.Get(key)
+	if e == nil {
+		return nil
+	}
+
+	response, ok := e.StaleResponse(h.maxStale())
+	if !ok {
+		return nil
+	}
+
+	// the cached response varies based on request headers,
+	// the entry under the "key" is just the index of its variants.
+	if vary := varyKeys(response.Headers()); len(vary) > 0 {
+		return h.store.Get(variantKey(key, vary, ctx.Request()))
+	}
+
+	return e
+}
+
+// writeResponse writes the cached "response" of the "e" entry to the client.
+func writeResponse(ctx context.Context, e *entry.Entry, response *entry.Response) {
 	entry.CopyHeaders(ctx.ResponseWriter().Header(), response.Headers())
 	ctx.SetLastModified(e.LastModified)
 	ctx.StatusCode(response.StatusCode())
 	ctx.Write(response.Body())
+}
+
+// varyKeys returns the sorted, canonical, header keys of the "Vary" header.
+// If the list contains the "*" then it's the only element of the result.
+func varyKeys(headers http.Header) []string {
+	var keys []string
+	for _, v := range headers[context.VaryHeaderKey] {
+		for _, k := range strings.Split(v, ",") {
+			k = strings.TrimSpace(k)
+			if k == "" {
+				continue
+			}
+
+			if k == "*" {
+				return []string{k}
+			}
+
+			keys = append(keys, http.CanonicalHeaderKey(k))
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// variantKey returns the key of a "Vary" response
+// based on the request's values of the "vary" header keys.
+func variantKey(key string, vary []string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for i, k := range vary {
+		if i > 0 && vary[i-1] == k {
+			continue // skip duplicates.
+		}
+
+		b.WriteString("\n")
+		b.WriteString(k)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header[k], ","))
+	}
 
-	// fmt.Printf("key: %s\n", key)
-	// fmt.Printf("write content type: %s\n", response.Headers()["ContentType"])
-	// fmt.Printf("write body len: %d\n", len(response.Body()))
+	return b.String()
 }
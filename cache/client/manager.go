@@ -0,0 +1,162 @@
+package client
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/cache/entry"
+	"github.com/kataras/iris/v12/cache/store"
+	"github.com/kataras/iris/v12/context"
+)
+
+// tagsContextKey is the context's values key of the tags
+// that should be attached to the cached entry of the current request.
+const tagsContextKey = "iris.cache.tags"
+
+// Tag attaches the "tags" to the cache entry of the current request,
+// i.e `client.Tag(ctx, "products", "product:42")`.
+// Tagged entries can be invalidated at once through the `Manager.PurgeTag`.
+// It should be called from inside the cached handler, before or after the response is written.
+func Tag(ctx context.Context, tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	existing, _ := ctx.Values().Get(tagsContextKey).([]string)
+	ctx.Values().Set(tagsContextKey, append(existing, tags...))
+}
+
+// Tags returns the tags attached to the current request through the `Tag` function.
+func Tags(ctx context.Context) []string {
+	tags, _ := ctx.Values().Get(tagsContextKey).([]string)
+	return tags
+}
+
+// EntryInfo holds the information of a cached entry, see `Manager.Entries`.
+type EntryInfo struct {
+	Key        string        `json:"key"`
+	Route      string        `json:"route,omitempty"`
+	Tags       []string      `json:"tags,omitempty"`
+	StatusCode int           `json:"statusCode"`
+	ExpiresAt  time.Time     `json:"expiresAt"`
+	Remaining  time.Duration `json:"remaining"`
+}
+
+// Manager provides programmatic access to the entries of a cache `store.Store`.
+// It can be used to invalidate cached responses when the underlying data changes.
+//
+// Use `NewManager` to manage a store shared by many handlers
+// or the `Handler.Manager` to manage the entries of a single handler.
+type Manager struct {
+	store store.Store
+}
+
+// NewManager returns a new cache manager for the "s" store.
+func NewManager(s store.Store) *Manager {
+	return &Manager{store: s}
+}
+
+// Manager returns a cache manager for the entries of this handler's store.
+func (h *Handler) Manager() *Manager {
+	return NewManager(h.store)
+}
+
+// PurgeKey removes the entry of the "key", including all of its "Vary" variants.
+// The "key" is the one generated by the handler's `KeyFunc`.
+func (m *Manager) PurgeKey(key string) {
+	m.store.Delete(key)
+	m.purge(func(k string, _ *entry.Entry) bool {
+		return strings.HasPrefix(k, key+"\n")
+	})
+}
+
+// PurgeTag removes all the entries that have at least one of the "tags" attached.
+// Returns the number of the removed entries.
+func (m *Manager) PurgeTag(tags ...string) int {
+	return m.purge(func(_ string, e *entry.Entry) bool {
+		for _, tag := range tags {
+			if e.HasTag(tag) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// PurgeRoute removes all the entries generated by the route of "routeName".
+// Returns the number of the removed entries.
+func (m *Manager) PurgeRoute(routeName string) int {
+	return m.purge(func(_ string, e *entry.Entry) bool {
+		return e.Route == routeName
+	})
+}
+
+// Purge removes all the entries of the store.
+func (m *Manager) Purge() {
+	m.store.Purge()
+}
+
+// purge removes the matched entries and returns the number of the removed responses.
+// A "Vary" response is stored under its variant key and its index key too (see `Handler.set`),
+// so the index is not counted when one of its variants is removed as well.
+func (m *Manager) purge(match func(key string, e *entry.Entry) bool) int {
+	var (
+		keys     []string
+		variants = make(map[string]struct{})
+	)
+
+	m.store.Visit(func(key string, e *entry.Entry) bool {
+		if match(key, e) {
+			m.store.Delete(key)
+			keys = append(keys, key)
+			if idx := strings.IndexByte(key, '\n'); idx > 0 {
+				variants[key[:idx]] = struct{}{}
+			}
+		}
+
+		return true
+	})
+
+	n := 0
+	for _, key := range keys {
+		if _, isIndex := variants[key]; !isIndex {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Entries returns the information of the current, non-expired, entries sorted by their keys.
+func (m *Manager) Entries() []EntryInfo {
+	var (
+		now   = time.Now()
+		infos []EntryInfo
+	)
+
+	m.store.Visit(func(key string, e *entry.Entry) bool {
+		r, ok := e.Response()
+		if !ok {
+			return true
+		}
+
+		infos = append(infos, EntryInfo{
+			Key:        key,
+			Route:      e.Route,
+			Tags:       e.Tags,
+			StatusCode: r.StatusCode(),
+			ExpiresAt:  e.ExpiresAt(),
+			Remaining:  e.ExpiresAt().Sub(now),
+		})
+
+		return true
+	})
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+
+	return infos
+}
@@ -18,11 +18,14 @@ type Entry struct {
 	// some clients may need it.
 	LastModified time.Time
 
+	// Route is the name of the route that this entry's response was generated from.
+	Route string
+	// Tags are the tags attached to this entry, they are used
+	// to invalidate a group of entries at once, i.e "product:42".
+	Tags []string
+
 	// Response the response should be served to the client
 	response *Response
-	// but we need the key to invalidate manually...xmm
-	// let's see for that later, maybe we make a slice instead
-	// of store map
 }
 
 // NewEntry returns a new cache entry
@@ -52,6 +55,54 @@ func (e *Entry) Response() (*Response, bool) {
 	return e.response, true
 }
 
+// StaleResponse same as `Response` but it returns the cache response contents
+// even if it's expired, as long as it's not expired for more than the "maxStale" duration.
+func (e *Entry) StaleResponse(maxStale time.Duration) (*Response, bool) {
+	if e.valid() || (maxStale > 0 && !time.Now().After(e.expiresAt.Add(maxStale))) {
+		return e.response, true
+	}
+
+	return nil, false
+}
+
+// ExpiresAt returns the time that this entry's response will be expired.
+func (e *Entry) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
+// Lifetime returns the life duration of the cached response.
+func (e *Entry) Lifetime() time.Duration {
+	return e.life
+}
+
+// HasTag reports whether the "tag" is attached to this entry.
+func (e *Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Size returns the number of bytes of the entry's response body and headers.
+func (e *Entry) Size() int {
+	if e.response == nil {
+		return 0
+	}
+
+	n := len(e.response.body)
+	for k, vv := range e.response.headers {
+		n += len(k)
+		for _, v := range vv {
+			n += len(v)
+		}
+	}
+
+	return n
+}
+
 // valid returns true if this entry's response is still valid
 // or false if the expiration time passed
 func (e *Entry) valid() bool {
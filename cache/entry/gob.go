@@ -0,0 +1,63 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/gob"
+	"net/http"
+	"time"
+)
+
+// entryGob is the serializable form of an `Entry`,
+// used by stores that keep their entries outside of the process' memory.
+type entryGob struct {
+	Life         time.Duration
+	ExpiresAt    time.Time
+	LastModified time.Time
+	Route        string
+	Tags         []string
+	StatusCode   int
+	Headers      http.Header
+	Body         []byte
+}
+
+// GobEncode implements the `gob.GobEncoder` interface.
+func (e *Entry) GobEncode() ([]byte, error) {
+	v := entryGob{
+		Life:         e.life,
+		ExpiresAt:    e.expiresAt,
+		LastModified: e.LastModified,
+		Route:        e.Route,
+		Tags:         e.Tags,
+	}
+
+	if r := e.response; r != nil {
+		v.StatusCode = r.statusCode
+		v.Headers = r.headers
+		v.Body = r.body
+	}
+
+	w := new(bytes.Buffer)
+	err := gob.NewEncoder(w).Encode(v)
+	return w.Bytes(), err
+}
+
+// GobDecode implements the `gob.GobDecoder` interface.
+func (e *Entry) GobDecode(b []byte) error {
+	var v entryGob
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v); err != nil {
+		return err
+	}
+
+	e.life = v.Life
+	e.expiresAt = v.ExpiresAt
+	e.LastModified = v.LastModified
+	e.Route = v.Route
+	e.Tags = v.Tags
+	e.response = &Response{
+		statusCode: v.StatusCode,
+		headers:    v.Headers,
+		body:       v.Body,
+	}
+
+	return nil
+}
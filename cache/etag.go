@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+const ifMatchHeaderKey = "If-Match"
+
+// DynamicETag is a middleware which generates the "ETag" header of a response
+// by hashing its body, that way any response, i.e `ctx.JSON`, supports conditional requests.
+// It should be registered before the route's main handler.
+//
+// If "weak" is true then a weak ETag (W/"...") is generated instead of a strong one,
+// weak ETags are cheaper to generate but they can't be used on "If-Match" preconditions.
+//
+// On GET and HEAD requests the response is recorded
+// and, if it matches the "If-None-Match" request header,
+// the body is discarded and a 304 (Not Modified) status code is sent instead.
+//
+// The optional "current" function returns the ETag of the current representation of the requested resource,
+// e.g. based on its version or last modification time, without generating its response.
+// When it's given, the GET and HEAD responses get their ETag from it instead of their body
+// and the "If-Match" request header of the unsafe methods (POST, PUT, PATCH and DELETE) is evaluated:
+// if it does not match a 412 (Precondition Failed) status code is sent instead,
+// the route's handler is not executed at all.
+// Useful to avoid the "lost update" problem.
+// Without it, the "If-Match" header is ignored.
+//
+// Responses that already have an "ETag" header are not modified.
+//
+// Usage:
+//
+//	api := app.Party("/api", cache.DynamicETag(false, func(ctx iris.Context) (string, bool) {
+//		version, ok := users.Version(ctx.Params().Get("id"))
+//		return `"` + version + `"`, ok
+//	}))
+//	api.Get("/users/{id}", getUser)
+//	api.Put("/users/{id}", updateUser)
+//
+// Read more at: https://tools.ietf.org/html/rfc7232
+var DynamicETag = func(weak bool, current ...ETagFunc) context.Handler {
+	var currentETag ETagFunc
+	if len(current) > 0 {
+		currentETag = current[0]
+	}
+
+	return func(ctx context.Context) {
+		switch ctx.Method() {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			if ifMatch := ctx.GetHeader(ifMatchHeaderKey); ifMatch != "" && currentETag != nil {
+				etag, exists := currentETag(ctx)
+				if !exists || !matchETag(ifMatch, etag, false) {
+					ctx.StopExecution()
+					ctx.StatusCode(http.StatusPreconditionFailed)
+					return
+				}
+			}
+
+			ctx.Next()
+			return
+		default:
+			ctx.Next()
+			return
+		}
+
+		recorder := ctx.Recorder()
+		ctx.Next()
+
+		if !isSuccess(recorder.StatusCode()) {
+			return
+		}
+
+		etag := recorder.Header().Get(context.ETagHeaderKey)
+		if etag == "" {
+			if currentETag != nil {
+				etag, _ = currentETag(ctx)
+			}
+			if etag == "" {
+				etag = ETagOf(recorder.Body(), weak)
+			}
+			recorder.Header().Set(context.ETagHeaderKey, etag)
+		}
+
+		if ifNoneMatch := ctx.GetHeader(ifNoneMatchHeaderKey); ifNoneMatch != "" && matchETag(ifNoneMatch, etag, true) {
+			recorder.ResetBody()
+			ctx.WriteNotModified()
+		}
+	}
+}
+
+// ETagFunc returns the ETag header value (quoted, i.e `"v42"` or `W/"v42"`)
+// of the current representation of the requested resource and reports whether the resource exists,
+// see `DynamicETag`.
+type ETagFunc func(ctx context.Context) (etag string, exists bool)
+
+// ETagOf returns the ETag header value of a response body, used by the `DynamicETag`.
+// A strong ETag is the hex-encoded sha1 sum of the body,
+// a weak one is its length and its 64-bit FNV-1a hash.
+func ETagOf(body []byte, weak bool) string {
+	if weak {
+		h := fnv.New64a()
+		h.Write(body)
+		return `W/"` + strconv.FormatInt(int64(len(body)), 16) + "-" + strconv.FormatUint(h.Sum64(), 16) + `"`
+	}
+
+	sum := sha1.Sum(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// matchETag reports whether the "etag" matches
+// one of the comma-separated entity tags of an "If-Match" or "If-None-Match" header value.
+// Weak comparison ignores the weak indicator, strong comparison does not match weak tags at all.
+func matchETag(headerValue, etag string, weakComparison bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, v := range strings.Split(headerValue, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" {
+			return true
+		}
+
+		if weakComparison {
+			if strings.TrimPrefix(v, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+
+		if v == etag && !strings.HasPrefix(v, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
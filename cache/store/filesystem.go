@@ -0,0 +1,166 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/cache/entry"
+)
+
+// DefaultFileMode used as the default file system store's "fileMode"
+// for creating the cache directory path and writing the cache files.
+var DefaultFileMode = 0755
+
+// FileExtension is the extension of the files that the `FileSystem` store writes.
+const FileExtension = ".cache"
+
+// FileSystem is a `Store` which keeps each entry to a separate file
+// inside a directory, the cached responses survive server restarts.
+type FileSystem struct {
+	directory string
+}
+
+type fileItem struct {
+	Key       string
+	ExpiresAt time.Time
+	Entry     *entry.Entry
+}
+
+var _ Store = (*FileSystem)(nil)
+
+// NewFileSystem returns a new file system store
+// which writes its entries inside the "directory", i.e ./cache.
+// The directory is created if it does not exist.
+func NewFileSystem(directory string) (*FileSystem, error) {
+	if directory == "" {
+		return nil, errors.New("directory is empty")
+	}
+
+	if err := os.MkdirAll(directory, os.FileMode(DefaultFileMode)); err != nil {
+		return nil, err
+	}
+
+	return &FileSystem{directory: directory}, nil
+}
+
+func (s *FileSystem) filename(key string) string {
+	h := sha1.Sum([]byte(key))
+	return filepath.Join(s.directory, hex.EncodeToString(h[:])+FileExtension)
+}
+
+// Get returns the entry of the "key" or nil if not found, expired or unreadable.
+func (s *FileSystem) Get(key string) *entry.Entry {
+	item, ok := s.read(s.filename(key))
+	if !ok || item.Key != key {
+		return nil
+	}
+
+	return item.Entry
+}
+
+// read decodes the item of the "filename" file,
+// expired items are removed.
+func (s *FileSystem) read(filename string) (item fileItem, ok bool) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+
+	if err = gob.NewDecoder(bytes.NewReader(b)).Decode(&item); err != nil {
+		return
+	}
+
+	if expired(item.ExpiresAt) {
+		os.Remove(filename)
+		return
+	}
+
+	return item, true
+}
+
+// Set writes the "e" entry under the "key" for "expiration" duration.
+// The file is written to a temporary location first
+// so concurrent readers never see a partially written entry.
+func (s *FileSystem) Set(key string, e *entry.Entry, expiration time.Duration) {
+	w := new(bytes.Buffer)
+	err := gob.NewEncoder(w).Encode(fileItem{
+		Key:       key,
+		ExpiresAt: expiresAt(expiration),
+		Entry:     e,
+	})
+	if err != nil {
+		return
+	}
+
+	tmp, err := ioutil.TempFile(s.directory, "tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(w.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.filename(key))
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete removes the entry's file of the "key".
+func (s *FileSystem) Delete(key string) {
+	os.Remove(s.filename(key))
+}
+
+// Purge removes all the cache files of the store's directory.
+func (s *FileSystem) Purge() {
+	for _, filename := range s.files() {
+		os.Remove(filename)
+	}
+}
+
+// Visit calls the "visitor" for each one of the non-expired entries of the store's directory.
+// The iteration stops when the "visitor" returns false.
+func (s *FileSystem) Visit(visitor func(key string, e *entry.Entry) bool) {
+	for _, filename := range s.files() {
+		item, ok := s.read(filename)
+		if !ok {
+			continue
+		}
+
+		if !visitor(item.Key, item.Entry) {
+			return
+		}
+	}
+}
+
+// files returns the cache files of the store's directory.
+func (s *FileSystem) files() []string {
+	files, err := ioutil.ReadDir(s.directory)
+	if err != nil {
+		return nil
+	}
+
+	filenames := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), FileExtension) {
+			continue
+		}
+
+		filenames = append(filenames, filepath.Join(s.directory, f.Name()))
+	}
+
+	return filenames
+}
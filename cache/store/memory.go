@@ -0,0 +1,176 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/cache/entry"
+)
+
+// Memory is an in-memory, entry-count bounded, `Store`.
+// When the maximum number of entries, or the optional `MaxBytes` of the stored
+// response bodies and headers, is reached the least recently used entries are evicted.
+type Memory struct {
+	maxEntries int
+	maxBytes   int
+	bytes      int
+
+	ll    *list.List // front is the most recently used.
+	items map[string]*list.Element
+	mu    sync.Mutex
+}
+
+type memoryItem struct {
+	key       string
+	entry     *entry.Entry
+	expiresAt time.Time
+	size      int
+}
+
+var _ Store = (*Memory)(nil)
+
+// NewMemory returns a new in-memory LRU store
+// which holds up to "maxEntries" entries.
+// If "maxEntries" is <=0 then the store is unbounded.
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// MaxBytes sets the maximum total size, in bytes, of the stored response bodies and headers.
+// The least recently used entries are evicted to keep the store under it
+// and an entry larger than "n" is not stored at all.
+// If "n" is <=0 then the size of the entries is not bounded, this is the default behavior.
+//
+// Returns itself.
+func (s *Memory) MaxBytes(n int) *Memory {
+	s.mu.Lock()
+	s.maxBytes = n
+	s.evict()
+	s.mu.Unlock()
+	return s
+}
+
+// Get returns the entry of the "key" or nil if not found or expired.
+func (s *Memory) Get(key string) *entry.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+
+	item := el.Value.(*memoryItem)
+	if expired(item.expiresAt) {
+		s.removeElement(el)
+		return nil
+	}
+
+	s.ll.MoveToFront(el)
+	return item.entry
+}
+
+// Set stores the "e" entry under the "key" for "expiration" duration.
+// It evicts the least recently used entries if the store is full.
+func (s *Memory) Set(key string, e *entry.Entry, expiration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := e.Size()
+	if s.maxBytes > 0 && size > s.maxBytes {
+		// it would evict everything else and still not fit.
+		if el, ok := s.items[key]; ok {
+			s.removeElement(el)
+		}
+		return
+	}
+
+	if el, ok := s.items[key]; ok {
+		item := el.Value.(*memoryItem)
+		s.bytes += size - item.size
+		item.entry = e
+		item.expiresAt = expiresAt(expiration)
+		item.size = size
+		s.ll.MoveToFront(el)
+	} else {
+		s.items[key] = s.ll.PushFront(&memoryItem{
+			key:       key,
+			entry:     e,
+			expiresAt: expiresAt(expiration),
+			size:      size,
+		})
+		s.bytes += size
+	}
+
+	s.evict()
+}
+
+// evict removes the least recently used entries until the store is within its limits.
+func (s *Memory) evict() {
+	for (s.maxEntries > 0 && s.ll.Len() > s.maxEntries) || (s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		el := s.ll.Back()
+		if el == nil {
+			return
+		}
+		s.removeElement(el)
+	}
+}
+
+// Delete removes the entry of the "key".
+func (s *Memory) Delete(key string) {
+	s.mu.Lock()
+	if el, ok := s.items[key]; ok {
+		s.removeElement(el)
+	}
+	s.mu.Unlock()
+}
+
+// Purge removes all the stored entries.
+func (s *Memory) Purge() {
+	s.mu.Lock()
+	s.ll.Init()
+	s.items = make(map[string]*list.Element)
+	s.bytes = 0
+	s.mu.Unlock()
+}
+
+// Visit calls the "visitor" for each one of the non-expired entries,
+// from the most to the least recently used one.
+// The iteration stops when the "visitor" returns false.
+func (s *Memory) Visit(visitor func(key string, e *entry.Entry) bool) {
+	s.mu.Lock()
+	items := make([]*memoryItem, 0, s.ll.Len())
+	for el := s.ll.Front(); el != nil; el = el.Next() {
+		if item := el.Value.(*memoryItem); !expired(item.expiresAt) {
+			items = append(items, item)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, item := range items {
+		if !visitor(item.key, item.entry) {
+			return
+		}
+	}
+}
+
+// Len returns the number of the stored entries, including the expired ones
+// that are not yet evicted.
+func (s *Memory) Len() int {
+	s.mu.Lock()
+	n := s.ll.Len()
+	s.mu.Unlock()
+	return n
+}
+
+func (s *Memory) removeElement(el *list.Element) {
+	s.ll.Remove(el)
+	item := el.Value.(*memoryItem)
+	s.bytes -= item.size
+	delete(s.items, item.key)
+}
@@ -0,0 +1,45 @@
+// Package store provides the storage backends for the server-side cache entries.
+//
+// The `cache#Handler` keeps its entries to an in-memory `Store` by default,
+// see `cache/client#Handler.Store` to register a different one.
+package store
+
+import (
+	"time"
+
+	"github.com/kataras/iris/v12/cache/entry"
+)
+
+// Store is the interface which all cache storage backends should implement.
+//
+// A store is treated as a best-effort storage: a failure to read or write
+// an entry results to a cache miss, the original handler will be executed instead.
+// Synchronization is up to the store implementation, it is used by many goroutines at the same time.
+type Store interface {
+	// Get returns the entry of the "key" or nil if not found or its lifetime has been passed.
+	Get(key string) *entry.Entry
+	// Set stores the "e" entry under the "key".
+	// The "expiration" is the time-to-live of the stored entry,
+	// if <=0 then the store keeps the entry until it's removed manually or evicted.
+	Set(key string, e *entry.Entry, expiration time.Duration)
+	// Delete removes the entry of the "key".
+	Delete(key string)
+	// Purge removes all the stored entries.
+	Purge()
+	// Visit calls the "visitor" for each one of the non-expired stored entries,
+	// the iteration stops when the "visitor" returns false.
+	// It's safe to modify the store from inside the "visitor".
+	Visit(visitor func(key string, e *entry.Entry) bool)
+}
+
+func expiresAt(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(expiration)
+}
+
+func expired(t time.Time) bool {
+	return !t.IsZero() && time.Now().After(t)
+}
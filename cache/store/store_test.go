@@ -0,0 +1,139 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12/cache/entry"
+)
+
+func newTestEntry(body string) *entry.Entry {
+	e := entry.NewEntry(time.Minute)
+	e.Reset(200, map[string][]string{"Content-Type": {"text/plain"}}, []byte(body), nil)
+	return e
+}
+
+func expectBody(t *testing.T, s Store, key, expected string) {
+	t.Helper()
+
+	e := s.Get(key)
+	if e == nil {
+		if expected != "" {
+			t.Fatalf("expected entry of key: %s to exist", key)
+		}
+		return
+	}
+
+	if expected == "" {
+		t.Fatalf("expected entry of key: %s to be removed", key)
+	}
+
+	r, ok := e.Response()
+	if !ok {
+		t.Fatalf("expected entry of key: %s to be valid", key)
+	}
+
+	if got := string(r.Body()); got != expected {
+		t.Fatalf("expected body of key: %s to be: %s but got: %s", key, expected, got)
+	}
+}
+
+func TestMemoryLRU(t *testing.T) {
+	s := NewMemory(2)
+	s.Set("a", newTestEntry("a"), 0)
+	s.Set("b", newTestEntry("b"), 0)
+	expectBody(t, s, "a", "a") // "a" is now the most recently used.
+	s.Set("c", newTestEntry("c"), 0)
+
+	expectBody(t, s, "b", "")
+	expectBody(t, s, "a", "a")
+	expectBody(t, s, "c", "c")
+
+	s.Set("d", newTestEntry("d"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	expectBody(t, s, "d", "")
+
+	s.Delete("a")
+	expectBody(t, s, "a", "")
+
+	s.Purge()
+	if s.Len() != 0 {
+		t.Fatalf("expected an empty store after purge but got %d entries", s.Len())
+	}
+}
+
+func TestMemoryMaxBytes(t *testing.T) {
+	size := newTestEntry("aaaa").Size()
+	s := NewMemory(0).MaxBytes(2 * size)
+	s.Set("a", newTestEntry("aaaa"), 0)
+	s.Set("b", newTestEntry("bbbb"), 0)
+	expectBody(t, s, "a", "aaaa") // "a" is now the most recently used.
+	s.Set("c", newTestEntry("cccc"), 0)
+
+	expectBody(t, s, "b", "")
+	expectBody(t, s, "a", "aaaa")
+	expectBody(t, s, "c", "cccc")
+
+	// larger than the whole budget, not stored.
+	s.Set("d", newTestEntry(string(make([]byte, 2*size))), 0)
+	expectBody(t, s, "d", "")
+	if s.Len() != 2 {
+		t.Fatalf("expected 2 entries but got %d", s.Len())
+	}
+
+	// replacing an entry with a larger one evicts the least recently used.
+	s.Set("a", newTestEntry("aaaaaaaa"), 0)
+	expectBody(t, s, "c", "")
+	expectBody(t, s, "a", "aaaaaaaa")
+}
+
+func TestFileSystem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cache-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewFileSystem(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Set("a", newTestEntry("a"), 0)
+	s.Set("b", newTestEntry("b"), 10*time.Millisecond)
+
+	// a new store on the same directory should see the same entries.
+	s, err = NewFileSystem(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody(t, s, "a", "a")
+	if r, _ := s.Get("a").Response(); r.Headers().Get("Content-Type") != "text/plain" {
+		t.Fatalf("expected headers to be restored but got: %v", r.Headers())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	expectBody(t, s, "b", "")
+
+	s.Delete("a")
+	expectBody(t, s, "a", "")
+
+	s.Set("c", newTestEntry("c"), 0)
+	s.Set("d", newTestEntry("d"), 0)
+	visited := 0
+	s.Visit(func(key string, e *entry.Entry) bool {
+		if key != "c" && key != "d" {
+			t.Fatalf("unexpected visited key: %s", key)
+		}
+		visited++
+		return true
+	})
+	if visited != 2 {
+		t.Fatalf("expected to visit 2 entries but visited: %d", visited)
+	}
+
+	s.Purge()
+	expectBody(t, s, "c", "")
+}
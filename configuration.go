@@ -278,6 +278,13 @@ var WithFireMethodNotAllowed = func(app *Application) {
 	app.config.FireMethodNotAllowed = true
 }
 
+// WithOptionsHandling enables the EnableOptionsHandling setting.
+//
+// See `Configuration`.
+var WithOptionsHandling = func(app *Application) {
+	app.config.EnableOptionsHandling = true
+}
+
 // WithTimeFormat sets the TimeFormat setting.
 //
 // See `Configuration`.
@@ -772,6 +779,11 @@ type Configuration struct {
 	//  fires the 405 error instead of 404
 	// Defaults to false.
 	FireMethodNotAllowed bool `json:"fireMethodNotAllowed,omitempty" yaml:"FireMethodNotAllowed" toml:"FireMethodNotAllowed"`
+	// EnableOptionsHandling if it's true the router answers the OPTIONS requests
+	// of a path with the "Allow" header of its registered methods,
+	// when an OPTIONS route is not registered for that path.
+	// Defaults to false.
+	EnableOptionsHandling bool `json:"enableOptionsHandling,omitempty" yaml:"EnableOptionsHandling" toml:"EnableOptionsHandling"`
 
 	// DisableBodyConsumptionOnUnmarshal manages the reading behavior of the context's body readers/binders.
 	// If set to true then it
@@ -908,6 +920,11 @@ func (c Configuration) GetFireMethodNotAllowed() bool {
 	return c.FireMethodNotAllowed
 }
 
+// GetEnableOptionsHandling returns the Configuration#EnableOptionsHandling.
+func (c Configuration) GetEnableOptionsHandling() bool {
+	return c.EnableOptionsHandling
+}
+
 // GetDisableBodyConsumptionOnUnmarshal returns the Configuration#GetDisableBodyConsumptionOnUnmarshal,
 // manages the reading behavior of the context's body readers/binders.
 // If returns true then the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`
@@ -1046,6 +1063,10 @@ func WithConfiguration(c Configuration) Configurator {
 			main.FireMethodNotAllowed = v
 		}
 
+		if v := c.EnableOptionsHandling; v {
+			main.EnableOptionsHandling = v
+		}
+
 		if v := c.DisableBodyConsumptionOnUnmarshal; v {
 			main.DisableBodyConsumptionOnUnmarshal = v
 		}
@@ -1106,6 +1127,7 @@ func DefaultConfiguration() Configuration {
 		DisablePathCorrection:             false,
 		EnablePathEscape:                  false,
 		FireMethodNotAllowed:              false,
+		EnableOptionsHandling:             false,
 		DisableBodyConsumptionOnUnmarshal: false,
 		DisableAutoFireStatusCode:         false,
 		TimeFormat:                        "Mon, 02 Jan 2006 15:04:05 GMT",
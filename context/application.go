@@ -3,6 +3,7 @@ package context
 import (
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/kataras/golog"
 )
@@ -20,6 +21,11 @@ type Application interface {
 	// I18nReadOnly returns the i18n's read-only features.
 	I18nReadOnly() I18nReadOnly
 
+	// Validate validates a value, i.e a struct filled by the `Context.ReadJSON`,
+	// using the application's `Validator`.
+	// It returns nil if passed or no validator is registered.
+	Validate(interface{}) error
+
 	// View executes and write the result of a template file to the writer.
 	//
 	// Use context.View to render templates to the client instead.
@@ -45,6 +51,13 @@ type Application interface {
 	// Look core/router/APIBuilder#GetRoute for more.
 	GetRouteReadOnly(routeName string) RouteReadOnly
 
+	// URLFor returns the URL of a route based on its name, the values of its dynamic path parameters
+	// and an optional url query. The "params" can be a map or a struct, their values are checked against
+	// the route's macros. The scheme, the host and the subdomain are included when the virtual host is known.
+	//
+	// Look iris/Application#URLFor for more.
+	URLFor(routeName string, params interface{}, query url.Values) (string, error)
+
 	// GetRoutesReadOnly returns the registered "read-only" routes.
 	//
 	// Look core/router/APIBuilder#GetRoutes for more.
@@ -0,0 +1,501 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// DeflateHeaderValue is the header value of "deflate".
+	DeflateHeaderValue = "deflate"
+	// BrotliHeaderValue is the header value of "br".
+	BrotliHeaderValue = "br"
+	// ZstdHeaderValue is the header value of "zstd".
+	ZstdHeaderValue = "zstd"
+)
+
+// CompressWriter is the interface that a compression algorithm's writer should implement.
+// Its `Reset` is called to re-use the same writer for a different output,
+// writers are pooled per encoding.
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compressor is the function which creates a new `CompressWriter` that writes to "w",
+// see `RegisterCompressor`.
+type Compressor func(w io.Writer) (CompressWriter, error)
+
+type compressorEntry struct {
+	encoding string
+	new      Compressor
+	pool     sync.Pool
+}
+
+var (
+	compressors   []*compressorEntry // order is the server's preference.
+	compressorsMu sync.RWMutex
+)
+
+func init() {
+	RegisterCompressor(BrotliHeaderValue, func(w io.Writer) (CompressWriter, error) {
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression), nil
+	})
+	RegisterCompressor(ZstdHeaderValue, func(w io.Writer) (CompressWriter, error) {
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	})
+	RegisterCompressor(GzipHeaderValue, func(w io.Writer) (CompressWriter, error) {
+		return gzip.NewWriterLevel(w, gzip.DefaultCompression)
+	})
+	// the "deflate" content coding is the zlib format, see RFC 9110 section 8.4.1.2.
+	RegisterCompressor(DeflateHeaderValue, func(w io.Writer) (CompressWriter, error) {
+		return zlib.NewWriterLevel(w, zlib.DefaultCompression)
+	})
+}
+
+// RegisterCompressor registers a compression algorithm for the "encoding" content coding,
+// i.e "gzip", "br", "zstd", "deflate".
+// If the "encoding" is already registered then its compressor is replaced.
+// New encodings are appended to the list of the server's preferred encodings,
+// which is used when a client accepts more than one of them with the same quality.
+//
+// Built-in compressors: "br", "zstd", "gzip" and "deflate".
+func RegisterCompressor(encoding string, compressor Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+
+	e := &compressorEntry{encoding: encoding, new: compressor}
+	for i, existing := range compressors {
+		if existing.encoding == encoding {
+			compressors[i] = e
+			return
+		}
+	}
+
+	compressors = append(compressors, e)
+}
+
+// Compressors returns the registered content codings in order of the server's preference.
+func Compressors() []string {
+	compressorsMu.RLock()
+	encodings := make([]string, 0, len(compressors))
+	for _, e := range compressors {
+		encodings = append(encodings, e.encoding)
+	}
+	compressorsMu.RUnlock()
+
+	return encodings
+}
+
+func getCompressor(encoding string) *compressorEntry {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+
+	for _, e := range compressors {
+		if e.encoding == encoding {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// ErrCompressorNotFound is returned when an encoding has no registered compressor.
+var ErrCompressorNotFound = errors.New("compressor not found")
+
+// acquireCompressWriter returns a pooled writer of the "encoding" that writes to "w".
+//
+// see releaseCompressWriter too.
+func acquireCompressWriter(encoding string, w io.Writer) (CompressWriter, error) {
+	e := getCompressor(encoding)
+	if e == nil {
+		return nil, ErrCompressorNotFound
+	}
+
+	if v := e.pool.Get(); v != nil {
+		cw := v.(CompressWriter)
+		cw.Reset(w)
+		return cw, nil
+	}
+
+	return e.new(w)
+}
+
+// releaseCompressWriter closes the "cw" writer and puts it back to the pool of its "encoding".
+//
+// see acquireCompressWriter too.
+func releaseCompressWriter(encoding string, cw CompressWriter) {
+	cw.Close()
+	if e := getCompressor(encoding); e != nil {
+		e.pool.Put(cw)
+	}
+}
+
+// CompressOptions holds the settings of the response compression.
+// See `DefaultCompressOptions` and `CompressWith`.
+type CompressOptions struct {
+	// Encodings is the list of the allowed content codings,
+	// in order of the server's preference.
+	// Defaults to empty, all registered compressors are allowed.
+	Encodings []string
+	// MinSize is the minimum body length in bytes that should be compressed,
+	// smaller bodies are sent uncompressed.
+	// Defaults to 0, all bodies are compressed.
+	MinSize int
+	// ContentTypes is the allowlist of the content types that should be compressed,
+	// i.e "text/html", "application/json" or "text/*".
+	// Defaults to empty, all content types are compressed.
+	ContentTypes []string
+}
+
+// DefaultCompressOptions is the default compression settings
+// used by `Context.Compress`, `Context.Gzip`, `Context.ServeContent` and the `FileServer`.
+var DefaultCompressOptions = CompressOptions{}
+
+func (o CompressOptions) encodings() []string {
+	if len(o.Encodings) > 0 {
+		return o.Encodings
+	}
+
+	return Compressors()
+}
+
+// Allow reports whether a response of "contentType" and "size" (in bytes) should be compressed.
+// A negative "size" means unknown size.
+func (o CompressOptions) Allow(contentType string, size int) bool {
+	if size >= 0 && size < o.MinSize {
+		return false
+	}
+
+	if len(o.ContentTypes) == 0 {
+		return true
+	}
+
+	return matchContentType(contentType, o.ContentTypes)
+}
+
+// matchContentType reports whether the "contentType", with or without parameters,
+// is one of the "allowed", which may contain wildcard subtypes, i.e "image/*".
+func matchContentType(contentType string, allowed []string) bool {
+	// remove any parameters, i.e "; charset=utf-8".
+	if idx := strings.IndexByte(contentType, ';'); idx != -1 {
+		contentType = contentType[:idx]
+	}
+	contentType = strings.TrimSpace(contentType)
+
+	for _, a := range allowed {
+		if a == contentType {
+			return true
+		}
+
+		if strings.HasSuffix(a, "/*") && strings.HasPrefix(contentType, a[:len(a)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type acceptedEncoding struct {
+	name    string
+	quality float64
+}
+
+// parseAcceptEncoding parses the "Accept-Encoding" header value
+// and returns the accepted encodings sorted by their quality values, higher first.
+func parseAcceptEncoding(headerValue string) []acceptedEncoding {
+	var accepted []acceptedEncoding
+	for _, part := range strings.Split(headerValue, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		e := acceptedEncoding{quality: 1}
+		if idx := strings.IndexByte(part, ';'); idx != -1 {
+			params := part[idx+1:]
+			part = strings.TrimSpace(part[:idx])
+
+			for _, param := range strings.Split(params, ";") {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+						e.quality = q
+					}
+				}
+			}
+		}
+
+		e.name = strings.ToLower(part)
+		if e.name == "x-gzip" {
+			e.name = GzipHeaderValue
+		}
+
+		accepted = append(accepted, e)
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	return accepted
+}
+
+// negotiateEncoding returns the encoding of "available" (in order of the server's preference)
+// with the highest quality value based on the "Accept-Encoding" header value.
+// Returns empty string if none of them is acceptable.
+func negotiateEncoding(headerValue string, available []string) string {
+	if headerValue == "" {
+		return ""
+	}
+
+	accepted := parseAcceptEncoding(headerValue)
+
+	var (
+		best        string
+		bestQuality float64
+	)
+
+	for _, encoding := range available {
+		quality, found := 0.0, false
+		wildcard := -1.0
+		for _, a := range accepted {
+			if a.name == encoding {
+				quality, found = a.quality, true
+				break
+			}
+
+			if a.name == "*" && wildcard < 0 {
+				wildcard = a.quality
+			}
+		}
+
+		if !found && wildcard > 0 {
+			quality = wildcard
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+
+	return best
+}
+
+// AddCompressHeaders just adds the headers "Vary" to "Accept-Encoding"
+// and "Content-Encoding" to the "encoding".
+func AddCompressHeaders(h http.Header, encoding string) {
+	AddVaryHeader(h, AcceptEncodingHeaderKey)
+	h.Set(ContentEncodingHeaderKey, encoding)
+}
+
+var compresspool = sync.Pool{New: func() interface{} { return &CompressResponseWriter{} }}
+
+// AcquireCompressResponseWriter returns a new *CompressResponseWriter from the pool.
+// Releasing is done automatically when request and response is done.
+func AcquireCompressResponseWriter() *CompressResponseWriter {
+	w := compresspool.Get().(*CompressResponseWriter)
+	return w
+}
+
+func releaseCompressResponseWriter(w *CompressResponseWriter) {
+	compresspool.Put(w)
+}
+
+// CompressResponseWriter is an upgraded response writer which writes compressed data to the underline ResponseWriter.
+//
+// It's a separate response writer because iris gives you the ability to "fallback" and "roll-back" the compression if something
+// went wrong with the response, and write http errors in plain form instead.
+// The body is compressed on `FlushResponse`, if it matches the writer's `CompressOptions`,
+// otherwise it's written as it is.
+type CompressResponseWriter struct {
+	ResponseWriter
+	chunks   []byte
+	disabled bool
+	encoding string
+	options  CompressOptions
+
+	// started reports whether the body is being written to the underline writer,
+	// the compression can't be enabled or disabled after that.
+	started bool
+	// cw is the compressor of the body, nil if the body is written in plain form.
+	cw CompressWriter
+}
+
+var _ ResponseWriter = (*CompressResponseWriter)(nil)
+
+// BeginCompressResponse accepts a ResponseWriter
+// and prepares the new compress response writer for the "encoding", i.e "br".
+// It's being called per-handler, when caller decide
+// to change the response writer type.
+func (w *CompressResponseWriter) BeginCompressResponse(underline ResponseWriter, encoding string, options CompressOptions) {
+	w.ResponseWriter = underline
+
+	w.chunks = w.chunks[0:0]
+	w.disabled = false
+	w.encoding = encoding
+	w.options = options
+	w.started = false
+	w.cw = nil
+}
+
+// Encoding returns the content coding of this writer, i.e "gzip".
+func (w *CompressResponseWriter) Encoding() string {
+	return w.encoding
+}
+
+// EndResponse called right before the contents of this
+// response writer are flushed to the client.
+func (w *CompressResponseWriter) EndResponse() {
+	w.closeCompressWriter()
+	releaseCompressResponseWriter(w)
+	w.ResponseWriter.EndResponse()
+}
+
+// Write prepares the data write to the compress writer and finally to its
+// underline response writer, returns the uncompressed len(contents).
+func (w *CompressResponseWriter) Write(contents []byte) (int, error) {
+	// save the contents to serve them (only compressed data here)
+	w.chunks = append(w.chunks, contents...)
+	return len(contents), nil
+}
+
+// Writef formats according to a format specifier and writes to the response.
+//
+// Returns the number of bytes written and any write error encountered.
+func (w *CompressResponseWriter) Writef(format string, a ...interface{}) (n int, err error) {
+	n, err = fmt.Fprintf(w, format, a...)
+	if err == nil {
+		if w.ResponseWriter.Header()[ContentTypeHeaderKey] == nil {
+			w.ResponseWriter.Header().Set(ContentTypeHeaderKey, ContentTextHeaderValue)
+		}
+	}
+
+	return
+}
+
+// WriteString prepares the string data write to the compress writer and finally to its
+// underline response writer, returns the uncompressed len(contents).
+func (w *CompressResponseWriter) WriteString(s string) (n int, err error) {
+	n, err = w.Write([]byte(s))
+	if err == nil {
+		if w.ResponseWriter.Header()[ContentTypeHeaderKey] == nil {
+			w.ResponseWriter.Header().Set(ContentTypeHeaderKey, ContentTextHeaderValue)
+		}
+	}
+	return
+}
+
+// WriteNow compresses and writes that data to the underline response writer,
+// returns the compressed written len.
+//
+// Use `WriteNow` instead of `Write`
+// when you need to know the compressed written size before
+// the `FlushResponse`, note that you can't post any new headers
+// after that, so that information is not closed to the handler anymore.
+//
+// The first call decides whether the whole body is compressed,
+// the `CompressOptions.MinSize` is compared to the "Content-Length" header, if it's set.
+// The next calls continue the same compressed stream.
+func (w *CompressResponseWriter) WriteNow(contents []byte) (int, error) {
+	return w.writeNow(contents, -1)
+}
+
+// writeNow writes the "contents" to the underline response writer,
+// the "size" is the length of the whole body or -1 if it's not known yet.
+func (w *CompressResponseWriter) writeNow(contents []byte, size int) (int, error) {
+	if !w.started {
+		w.started = true
+
+		if !w.disabled && w.shouldCompress(contents, size) {
+			cw, err := acquireCompressWriter(w.encoding, w.ResponseWriter)
+			if err != nil {
+				return -1, err
+			}
+
+			AddCompressHeaders(w.ResponseWriter.Header(), w.encoding)
+			// the length of the uncompressed body is not the one that is sent.
+			w.ResponseWriter.Header().Del(ContentLengthHeaderKey)
+			w.cw = cw
+		}
+	}
+
+	if w.cw == nil {
+		return w.ResponseWriter.Write(contents)
+	}
+
+	n, err := w.cw.Write(contents)
+	if err != nil {
+		return -1, err
+	}
+
+	return n, w.cw.Flush()
+}
+
+func (w *CompressResponseWriter) shouldCompress(contents []byte, size int) bool {
+	h := w.ResponseWriter.Header()
+	if h.Get(ContentEncodingHeaderKey) != "" {
+		// already encoded by the handler.
+		return false
+	}
+
+	if size < 0 {
+		if contentLength, err := strconv.Atoi(h.Get(ContentLengthHeaderKey)); err == nil {
+			size = contentLength
+		}
+	}
+
+	contentType := h.Get(ContentTypeHeaderKey)
+	if contentType == "" && len(w.options.ContentTypes) > 0 {
+		contentType = http.DetectContentType(contents)
+	}
+
+	return w.options.Allow(contentType, size)
+}
+
+// closeCompressWriter completes the compressed stream, if any.
+func (w *CompressResponseWriter) closeCompressWriter() {
+	if w.cw != nil {
+		releaseCompressWriter(w.encoding, w.cw)
+		w.cw = nil
+	}
+}
+
+// FlushResponse validates the response headers in order to be compatible with the compressed written data
+// and writes the data to the underline ResponseWriter.
+func (w *CompressResponseWriter) FlushResponse() {
+	if !w.started {
+		// the whole body is here.
+		_, _ = w.writeNow(w.chunks, len(w.chunks))
+	} else if len(w.chunks) > 0 {
+		_, _ = w.writeNow(w.chunks, -1)
+	}
+	w.chunks = w.chunks[0:0]
+
+	w.closeCompressWriter()
+	w.ResponseWriter.FlushResponse()
+}
+
+// ResetBody resets the response body.
+func (w *CompressResponseWriter) ResetBody() {
+	w.chunks = w.chunks[0:0]
+}
+
+// Disable turns off the compression for the next .Write's data,
+// if called then the contents are being written in plain form.
+// It has no effect after the first `WriteNow`, the body is already being compressed.
+func (w *CompressResponseWriter) Disable() {
+	w.disabled = true
+}
@@ -0,0 +1,240 @@
+package context_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/httptest"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func decompress(t *testing.T, encoding string, b []byte) string {
+	t.Helper()
+
+	var (
+		r   io.Reader
+		err error
+	)
+
+	switch encoding {
+	case context.BrotliHeaderValue:
+		r = brotli.NewReader(bytes.NewReader(b))
+	case context.ZstdHeaderValue:
+		r, err = zstd.NewReader(bytes.NewReader(b))
+	case context.GzipHeaderValue:
+		r, err = gzip.NewReader(bytes.NewReader(b))
+	case context.DeflateHeaderValue:
+		r, err = zlib.NewReader(bytes.NewReader(b))
+	default:
+		return string(b)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s: %v", encoding, err)
+	}
+
+	return string(out)
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("compress me ", 100)
+
+	app := iris.New()
+	app.Get("/", iris.Compress, func(ctx iris.Context) {
+		ctx.WriteString(body)
+	})
+	app.Get("/small", context.CompressWith(context.CompressOptions{MinSize: 4096}), func(ctx iris.Context) {
+		ctx.WriteString(body)
+	})
+	app.Get("/json", context.CompressWith(context.CompressOptions{ContentTypes: []string{"text/*"}}), func(ctx iris.Context) {
+		ctx.JSON(iris.Map{"body": body})
+	})
+	app.Get("/content", func(ctx iris.Context) {
+		ctx.ServeContent(strings.NewReader(body), "body.txt", time.Now(), true)
+	})
+	app.Get("/negotiate", func(ctx iris.Context) {
+		ctx.Negotiation().Text(body).Encoding(context.BrotliHeaderValue, context.GzipHeaderValue)
+		ctx.Negotiate(nil)
+	})
+
+	app.Get("/write-now", iris.Compress, func(ctx iris.Context) {
+		w := ctx.ResponseWriter().(*context.CompressResponseWriter)
+		w.WriteNow([]byte("hello "))
+		w.WriteNow([]byte("world"))
+		ctx.WriteString("!") // written on flush, on the same stream.
+	})
+	app.Get("/write-now-small", context.CompressWith(context.CompressOptions{MinSize: 4096}), func(ctx iris.Context) {
+		ctx.Header("Content-Length", "11")
+		w := ctx.ResponseWriter().(*context.CompressResponseWriter)
+		w.WriteNow([]byte("hello "))
+		w.WriteNow([]byte("world"))
+	})
+
+	e := httptest.New(t, app)
+
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"", ""},
+		{"gzip", context.GzipHeaderValue},
+		{"gzip, deflate, br", context.BrotliHeaderValue},
+		{"gzip;q=1.0, br;q=0.5", context.GzipHeaderValue},
+		{"zstd", context.ZstdHeaderValue},
+		{"deflate", context.DeflateHeaderValue},
+		{"br;q=0, *;q=0.1", context.ZstdHeaderValue},
+		{"identity", ""},
+	}
+
+	for _, tt := range tests {
+		r := e.GET("/").WithHeader("Accept-Encoding", tt.acceptEncoding).Expect().Status(httptest.StatusOK)
+		if got := r.Raw().Header.Get("Content-Encoding"); got != tt.expected {
+			t.Fatalf("[%s] expected encoding: %q but got: %q", tt.acceptEncoding, tt.expected, got)
+		}
+
+		if got := decompress(t, tt.expected, []byte(r.Body().Raw())); got != body {
+			t.Fatalf("[%s] unexpected body: %s", tt.acceptEncoding, got)
+		}
+	}
+
+	for _, encoding := range []string{context.GzipHeaderValue, context.DeflateHeaderValue, context.BrotliHeaderValue, context.ZstdHeaderValue} {
+		r := e.GET("/write-now").WithHeader("Accept-Encoding", encoding).Expect().Status(httptest.StatusOK)
+		r.Header("Content-Encoding").Equal(encoding)
+		if got := decompress(t, encoding, []byte(r.Body().Raw())); got != "hello world!" {
+			t.Fatalf("write now: %s: unexpected body: %s", encoding, got)
+		}
+	}
+	e.GET("/write-now-small").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK).
+		Body().Equal("hello world")
+
+	e.GET("/small").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK).
+		Header("Content-Encoding").Empty()
+	e.GET("/json").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK).
+		Header("Content-Encoding").Empty()
+
+	r := e.GET("/content").WithHeader("Accept-Encoding", "zstd, gzip;q=0.5").Expect().Status(httptest.StatusOK)
+	r.Header("Content-Encoding").Equal(context.ZstdHeaderValue)
+	if got := decompress(t, context.ZstdHeaderValue, []byte(r.Body().Raw())); got != body {
+		t.Fatalf("content: unexpected body: %s", got)
+	}
+
+	r = e.GET("/negotiate").WithHeader("Accept-Encoding", "gzip;q=0.8, br").Expect().Status(httptest.StatusOK)
+	r.Header("Content-Encoding").Equal(context.BrotliHeaderValue)
+	if got := decompress(t, context.BrotliHeaderValue, []byte(r.Body().Raw())); got != body {
+		t.Fatalf("negotiate: unexpected body: %s", got)
+	}
+}
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+		err error
+	)
+
+	switch encoding {
+	case context.BrotliHeaderValue:
+		w = brotli.NewWriter(&buf)
+	case context.ZstdHeaderValue:
+		w, err = zstd.NewWriter(&buf)
+	case context.GzipHeaderValue:
+		w = gzip.NewWriter(&buf)
+	case context.DeflateHeaderValue:
+		w = zlib.NewWriter(&buf) // the standard zlib format of the "deflate" content coding.
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecompressBody(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	app := iris.New()
+	app.Post("/", iris.DecompressBody, func(ctx iris.Context) {
+		var p payload
+		if err := ctx.ReadJSON(&p); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.WriteString(p.Name)
+	})
+	app.Post("/form", iris.DecompressBody, func(ctx iris.Context) {
+		ctx.WriteString(ctx.FormValue("name"))
+	})
+	app.Post("/limit", iris.LimitRequestBodySize(1024), iris.DecompressBody, func(ctx iris.Context) {
+		b, err := ctx.GetBody()
+		if err != nil {
+			ctx.StatusCode(iris.StatusRequestEntityTooLarge)
+			return
+		}
+
+		ctx.Writef("%d", len(b))
+	})
+
+	e := httptest.New(t, app)
+
+	for _, encoding := range []string{"gzip", "deflate", "br", "zstd"} {
+		e.POST("/").WithHeader("Content-Type", "application/json").WithHeader("Content-Encoding", encoding).
+			WithBytes(compress(t, encoding, []byte(`{"name":"iris"}`))).Expect().
+			Status(httptest.StatusOK).Body().Equal("iris")
+	}
+
+	// multiple content codings.
+	e.POST("/").WithHeader("Content-Type", "application/json").WithHeader("Content-Encoding", "deflate, gzip").
+		WithBytes(compress(t, "gzip", compress(t, "deflate", []byte(`{"name":"iris"}`)))).Expect().
+		Status(httptest.StatusOK).Body().Equal("iris")
+
+	// not compressed.
+	e.POST("/").WithJSON(payload{Name: "iris"}).Expect().Status(httptest.StatusOK).Body().Equal("iris")
+
+	e.POST("/form").WithHeader("Content-Type", "application/x-www-form-urlencoded").WithHeader("Content-Encoding", "gzip").
+		WithBytes(compress(t, "gzip", []byte("name=iris"))).Expect().
+		Status(httptest.StatusOK).Body().Equal("iris")
+
+	e.POST("/").WithHeader("Content-Encoding", "compress").WithBytes([]byte("data")).Expect().
+		Status(httptest.StatusUnsupportedMediaType)
+	e.POST("/").WithHeader("Content-Encoding", "gzip").WithBytes([]byte("not gzipped")).Expect().
+		Status(httptest.StatusBadRequest)
+
+	// the limit is applied to the decompressed size.
+	bomb := compress(t, "gzip", bytes.Repeat([]byte("0"), 256<<10))
+	if len(bomb) > 1024 {
+		t.Fatalf("expected compressed body to be smaller than the limit but got %d bytes", len(bomb))
+	}
+	e.POST("/limit").WithHeader("Content-Encoding", "gzip").WithBytes(bomb).Expect().
+		Status(httptest.StatusRequestEntityTooLarge)
+	e.POST("/limit").WithHeader("Content-Encoding", "gzip").WithBytes(compress(t, "gzip", bytes.Repeat([]byte("0"), 512))).Expect().
+		Status(httptest.StatusOK).Body().Equal("512")
+}
@@ -35,6 +35,8 @@ type ConfigurationReadOnly interface {
 
 	// GetFireMethodNotAllowed returns the configuration.FireMethodNotAllowed.
 	GetFireMethodNotAllowed() bool
+	// GetEnableOptionsHandling returns the configuration.EnableOptionsHandling.
+	GetEnableOptionsHandling() bool
 	// GetDisableBodyConsumptionOnUnmarshal returns the configuration.GetDisableBodyConsumptionOnUnmarshal,
 	// manages the reading behavior of the context's body readers/binders.
 	// If returns true then the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`
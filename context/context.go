@@ -16,6 +16,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -553,6 +554,14 @@ type Context interface {
 	//
 	// Example: https://github.com/kataras/iris/tree/master/_examples/http_request/upload-files
 	UploadFormFiles(destDirectory string, before ...func(Context, *multipart.FileHeader)) (n int64, err error)
+	// MultipartReader returns a reader which streams the parts of a "multipart/form-data"
+	// request body one at a time, without buffering them to memory or temporary files,
+	// so an uploaded file can be piped directly to its storage.
+	// The "options" limit the size of each file and of all parts together,
+	// the allowed content types of files and report the read progress.
+	//
+	// It should be called before any other form reader, i.e `FormValue` or `FormFile`.
+	MultipartReader(options MultipartOptions) (*MultipartReader, error)
 
 	//  +------------------------------------------------------------+
 	//  | Custom HTTP Errors                                         |
@@ -573,6 +582,15 @@ type Context interface {
 	// SetMaxRequestBodySize sets a limit to the request body size
 	// should be called before reading the request body from the client.
 	SetMaxRequestBodySize(limitOverBytes int64)
+	// DecompressBody replaces the request body with a reader which decompresses it,
+	// based on the "Content-Encoding" request header, i.e "gzip", "deflate", "br" or "zstd",
+	// so the `GetBody`, `ReadJSON`, `ReadXML`, `ReadYAML` and `ReadForm` read the original data.
+	// The max request body size, if set, is applied to the decompressed data too.
+	// It does nothing if the request body is not compressed.
+	//
+	// Returns `ErrDecompressorNotFound` if the content coding is not supported,
+	// see `RegisterDecompressor` to register a custom one.
+	DecompressBody() error
 
 	// GetBody reads and returns the request body.
 	// The default behavior for the http request reader is to consume the data readen
@@ -588,6 +606,9 @@ type Context interface {
 	// UnmarshalBody does not check about gzipped data.
 	// Do not rely on compressed data incoming to your server. The main reason is: https://en.wikipedia.org/wiki/Zip_bomb
 	// However you are still free to read the `ctx.Request().Body io.Reader` manually.
+	//
+	// The "outPtr" is validated through the `Application.Validator`, if any,
+	// on failure use the `AsValidationErrors` to retrieve the field errors.
 	UnmarshalBody(outPtr interface{}, unmarshaler Unmarshaler) error
 	// ReadJSON reads JSON from request's body and binds it to a pointer of a value of any json-valid type.
 	//
@@ -603,15 +624,33 @@ type Context interface {
 	ReadYAML(outPtr interface{}) error
 	// ReadForm binds the formObject  with the form data
 	// it supports any kind of type, including custom structs.
-	// It will return nothing if request data are empty.
 	// The struct field tag is "form".
+	// The "formObject" is validated through the `Application.Validator`, if any.
 	//
 	// Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-form/main.go
 	ReadForm(formObject interface{}) error
 	// ReadQuery binds the "ptr" with the url query string. The struct field tag is "url".
+	// The "ptr" is validated through the `Application.Validator`, if any.
 	//
 	// Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-query/main.go
 	ReadQuery(ptr interface{}) error
+	// ReadHeaders binds the "ptr" with the request headers. The struct field tag is "header",
+	// header names are matched case-insensitively.
+	// The "ptr" is validated through the `Application.Validator`, if any.
+	ReadHeaders(ptr interface{}) error
+	// ReadParams binds the "ptr" with the route's dynamic path parameters. The struct field tag is "param",
+	// fields without it are not set.
+	// The values already resolved by the parameter types, i.e {id:uint64}, are set as they're
+	// when their types are assignable to the fields, otherwise they are converted from their string form.
+	// The "ptr" is validated through the `Application.Validator`, if any.
+	ReadParams(ptr interface{}) error
+	// ReadRequest binds the "ptr" with the request body (based on its content type: JSON, XML, YAML or form),
+	// the url query (tag "url"), the headers (tag "header") and the route's path parameters (tag "param"),
+	// in that order, so a later source overrides the same field of a previous one.
+	// Headers and path parameters are bound only to the fields with an explicit "header" or "param" tag.
+	// Unknown form and url query keys are ignored.
+	// The "ptr" is validated once, through the `Application.Validator`, if any.
+	ReadRequest(ptr interface{}) error
 	//  +------------------------------------------------------------+
 	//  | Body (raw) Writers                                         |
 	//  +------------------------------------------------------------+
@@ -719,6 +758,23 @@ type Context interface {
 	// supports gzip compression, so the following response data will
 	// be sent as compressed gzip data to the client.
 	Gzip(enable bool)
+	// NegotiateEncoding returns the content coding, of the "available" ones, with the highest quality value
+	// based on the client's "Accept-Encoding" request header, i.e "br".
+	// If "available" is empty then the `DefaultCompressOptions.Encodings` or all the registered compressors are used instead.
+	// Returns empty string if the client does not accept any of them.
+	NegotiateEncoding(available ...string) string
+	// CompressResponseWriter converts the current response writer into a response writer
+	// which when its .Write called it compress the data with the "encoding" algorithm, i.e "br",
+	// and writes them to the client.
+	// If the response writer is already a compress response writer then it's returned as it's.
+	//
+	// Can be also disabled with its .Disable and .ResetBody to rollback to the usual response writer.
+	CompressResponseWriter(encoding string) *CompressResponseWriter
+	// Compress enables or disables (if enabled before) the compress response writer,
+	// the best encoding is picked based on the client's "Accept-Encoding" quality values,
+	// so the following response data will be sent compressed to the client.
+	// The `DefaultCompressOptions` are used for the allowed encodings, minimum size and content types.
+	Compress(enable bool)
 
 	//  +------------------------------------------------------------+
 	//  | Rich Body Content Writers/Renderers                        |
@@ -864,11 +920,14 @@ type Context interface {
 	//
 	// This function doesn't support resuming (by range),
 	// use ctx.SendFile or router's `HandleDir` instead.
-	ServeContent(content io.ReadSeeker, filename string, modtime time.Time, gzipCompression bool) error
+	//
+	// If "compress" is true then the content is compressed with the best encoding
+	// that the client accepts, see `DefaultCompressOptions` too.
+	ServeContent(content io.ReadSeeker, filename string, modtime time.Time, compress bool) error
 	// ServeFile serves a file (to send a file, a zip for example to the client you should use the `SendFile` instead)
 	// receives two parameters
 	// filename/path (string)
-	// gzipCompression (bool)
+	// compress (bool)
 	//
 	// You can define your own "Content-Type" with `context#ContentType`, before this function call.
 	//
@@ -876,7 +935,7 @@ type Context interface {
 	// use ctx.SendFile or router's `HandleDir` instead.
 	//
 	// Use it when you want to serve dynamic files to the client.
-	ServeFile(filename string, gzipCompression bool) error
+	ServeFile(filename string, compress bool) error
 	// SendFile sends file for force-download to the client
 	//
 	// Use this instead of ServeFile to 'force-download' bigger files to the client.
@@ -1042,6 +1101,53 @@ var Gzip = func(ctx Context) {
 	ctx.Next()
 }
 
+// Compress is a middleware which enables writing
+// using the best compression algorithm that the client supports,
+// i.e brotli, zstd, gzip or deflate.
+//
+// See `CompressWith` to customize the allowed encodings, minimum size and content types.
+var Compress = func(ctx Context) {
+	ctx.Compress(true)
+	ctx.Next()
+}
+
+// DecompressBody is a middleware which decompresses the request body
+// based on its "Content-Encoding" header, so the next handlers can read it as usual.
+// Register it before the `LimitRequestBodySize` middleware or after, either way
+// the limit is applied to the decompressed request body.
+//
+// Fires 415 Unsupported Media Type on unsupported content codings
+// and 400 Bad Request on malformed compressed data.
+var DecompressBody = func(ctx Context) {
+	if err := ctx.DecompressBody(); err != nil {
+		if err == ErrDecompressorNotFound {
+			ctx.StatusCode(http.StatusUnsupportedMediaType)
+			ctx.StopExecution()
+			return
+		}
+
+		ctx.StatusCode(http.StatusBadRequest)
+		ctx.StopExecution()
+		return
+	}
+
+	ctx.Next()
+}
+
+// CompressWith same as `Compress` but it accepts custom compression options
+// instead of the `DefaultCompressOptions`.
+var CompressWith = func(options CompressOptions) Handler {
+	return func(ctx Context) {
+		if encoding := ctx.NegotiateEncoding(options.encodings()...); encoding != "" {
+			w := AcquireCompressResponseWriter()
+			w.BeginCompressResponse(ctx.ResponseWriter(), encoding, options)
+			ctx.ResetResponseWriter(w)
+		}
+
+		ctx.Next()
+	}
+}
+
 // Map is just a type alias of the map[string]interface{} type.
 type Map = map[string]interface{}
 
@@ -1071,6 +1177,8 @@ type context struct {
 	handlers Handlers
 	// the current position of the handler's chain
 	currentHandlerIndex int
+	// the max request body size, zero means no limit.
+	maxRequestBodySize int64
 }
 
 // NewContext returns the default, internal, context implementation.
@@ -1098,6 +1206,7 @@ func (ctx *context) BeginRequest(w http.ResponseWriter, r *http.Request) {
 	ctx.params.Store = ctx.params.Store[0:0]
 	ctx.request = r
 	ctx.currentHandlerIndex = 0
+	ctx.maxRequestBodySize = 0
 	ctx.writer = AcquireResponseWriter()
 	ctx.writer.BeginResponse(w)
 }
@@ -2406,6 +2515,42 @@ func (ctx *context) UploadFormFiles(destDirectory string, before ...func(Context
 	return 0, http.ErrMissingFile
 }
 
+// MultipartReader returns a reader which streams the parts of a "multipart/form-data"
+// request body one at a time, without buffering them to memory or temporary files,
+// so an uploaded file can be piped directly to its storage.
+// The "options" limit the size of each file and of all parts together,
+// the allowed content types of files and report the read progress.
+//
+// It should be called before any other form reader, i.e `FormValue` or `FormFile`.
+//
+// Example:
+//	r, err := ctx.MultipartReader(context.MultipartOptions{MaxFileSize: 10 << 20})
+//	if err != nil { ... }
+//	for {
+//		part, err := r.NextPart()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil { ... }
+//		if part.IsFile() {
+//			io.Copy(storage, part)
+//		}
+//	}
+func (ctx *context) MultipartReader(options MultipartOptions) (*MultipartReader, error) {
+	if options.MaxTotalSize > 0 && ctx.request.Body != nil {
+		ctx.request.Body = newMultipartBodyReader(ctx.request.Body, options.MaxTotalSize)
+	}
+
+	r, err := ctx.request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	mr := NewMultipartReader(r, options)
+	mr.bodyLimited = options.MaxTotalSize > 0
+	return mr, nil
+}
+
 func uploadTo(fh *multipart.FileHeader, destDirectory string) (int64, error) {
 	src, err := fh.Open()
 	if err != nil {
@@ -2515,9 +2660,22 @@ func (ctx *context) Redirect(urlToRedirect string, statusHeader ...int) {
 // SetMaxRequestBodySize sets a limit to the request body size
 // should be called before reading the request body from the client.
 func (ctx *context) SetMaxRequestBodySize(limitOverBytes int64) {
+	ctx.maxRequestBodySize = limitOverBytes
 	ctx.request.Body = http.MaxBytesReader(ctx.writer, ctx.request.Body, limitOverBytes)
 }
 
+// DecompressBody replaces the request body with a reader which decompresses it,
+// based on the "Content-Encoding" request header, i.e "gzip", "deflate", "br" or "zstd",
+// so the `GetBody`, `ReadJSON`, `ReadXML`, `ReadYAML` and `ReadForm` read the original data.
+// The max request body size, if set, is applied to the decompressed data too.
+// It does nothing if the request body is not compressed.
+//
+// Returns `ErrDecompressorNotFound` if the content coding is not supported,
+// see `RegisterDecompressor` to register a custom one.
+func (ctx *context) DecompressBody() error {
+	return decompressRequest(ctx.writer, ctx.request, ctx.maxRequestBodySize)
+}
+
 // GetBody reads and returns the request body.
 // The default behavior for the http request reader is to consume the data readen
 // but you can change that behavior by passing the `WithoutBodyConsumptionOnUnmarshal` iris option.
@@ -2551,7 +2709,18 @@ func GetBody(r *http.Request, resetBody bool) ([]byte, error) {
 // UnmarshalBody does not check about gzipped data.
 // Do not rely on compressed data incoming to your server. The main reason is: https://en.wikipedia.org/wiki/Zip_bomb
 // However you are still free to read the `ctx.Request().Body io.Reader` manually.
+//
+// The "outPtr" is validated through the `Application.Validator`, if any,
+// on failure use the `AsValidationErrors` to retrieve the field errors.
 func (ctx *context) UnmarshalBody(outPtr interface{}, unmarshaler Unmarshaler) error {
+	if err := ctx.unmarshalBody(outPtr, unmarshaler); err != nil {
+		return err
+	}
+
+	return validate(ctx.app, outPtr)
+}
+
+func (ctx *context) unmarshalBody(outPtr interface{}, unmarshaler Unmarshaler) error {
 	if ctx.request.Body == nil {
 		return fmt.Errorf("unmarshal: empty body: %w", ErrNotFound)
 	}
@@ -2588,11 +2757,15 @@ func (ctx *context) shouldOptimize() bool {
 //
 // Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-json/main.go
 func (ctx *context) ReadJSON(outPtr interface{}) error {
-	unmarshaler := json.Unmarshal
+	return ctx.UnmarshalBody(outPtr, ctx.jsonUnmarshaler())
+}
+
+func (ctx *context) jsonUnmarshaler() Unmarshaler {
 	if ctx.shouldOptimize() {
-		unmarshaler = jsoniter.Unmarshal
+		return UnmarshalerFunc(jsoniter.Unmarshal)
 	}
-	return ctx.UnmarshalBody(outPtr, UnmarshalerFunc(unmarshaler))
+
+	return UnmarshalerFunc(json.Unmarshal)
 }
 
 // ReadXML reads XML from request's body and binds it to a value of any xml-valid type.
@@ -2618,11 +2791,19 @@ var IsErrPath = schema.IsErrPath
 
 // ReadForm binds the formObject  with the form data
 // it supports any kind of type, including custom structs.
-// It will return nothing if request data are empty.
 // The struct field tag is "form".
+// The "formObject" is validated through the `Application.Validator`, if any.
 //
 // Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-form/main.go
 func (ctx *context) ReadForm(formObject interface{}) error {
+	if err := ctx.readForm(formObject); err != nil {
+		return err
+	}
+
+	return validate(ctx.app, formObject)
+}
+
+func (ctx *context) readForm(formObject interface{}) error {
 	values := ctx.FormValues()
 	if len(values) == 0 {
 		return nil
@@ -2632,9 +2813,18 @@ func (ctx *context) ReadForm(formObject interface{}) error {
 }
 
 // ReadQuery binds the "ptr" with the url query string. The struct field tag is "url".
+// The "ptr" is validated through the `Application.Validator`, if any.
 //
 // Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-query/main.go
 func (ctx *context) ReadQuery(ptr interface{}) error {
+	if err := ctx.readQuery(ptr); err != nil {
+		return err
+	}
+
+	return validate(ctx.app, ptr)
+}
+
+func (ctx *context) readQuery(ptr interface{}) error {
 	values := ctx.request.URL.Query()
 	if len(values) == 0 {
 		return nil
@@ -2643,6 +2833,211 @@ func (ctx *context) ReadQuery(ptr interface{}) error {
 	return schema.DecodeQuery(values, ptr)
 }
 
+var (
+	headersDecoder = newSchemaDecoder("header")
+	paramsDecoder  = newSchemaDecoder("param")
+)
+
+func newSchemaDecoder(tag string) *schema.Decoder {
+	d := schema.NewDecoder()
+	d.SetAliasTag(tag)
+	// a request has more headers or parameters than a struct needs.
+	d.IgnoreUnknownKeys(true)
+	return d
+}
+
+// ReadHeaders binds the "ptr" with the request headers. The struct field tag is "header",
+// header names are matched case-insensitively.
+// The "ptr" is validated through the `Application.Validator`, if any.
+//
+// Example:
+//	type requestHeaders struct {
+//		RequestID string   `header:"X-Request-Id,required"`
+//		Accept    []string `header:"accept"`
+//	}
+func (ctx *context) ReadHeaders(ptr interface{}) error {
+	if err := ctx.readHeaders(ptr, false); err != nil {
+		return err
+	}
+
+	return validate(ctx.app, ptr)
+}
+
+// readHeaders binds the "ptr" with the request headers.
+// When "taggedOnly" is true, only the fields with an explicit "header" tag are set,
+// so a header can't override a field of a different source with the same name.
+func (ctx *context) readHeaders(ptr interface{}, taggedOnly bool) error {
+	var tagged map[string]struct{}
+	if taggedOnly {
+		tagged = taggedFieldNames(ptr, "header")
+		if len(tagged) == 0 {
+			return nil
+		}
+	}
+
+	values := make(map[string][]string, len(ctx.request.Header)*2)
+	for key, value := range ctx.request.Header {
+		lowerKey := strings.ToLower(key)
+		if taggedOnly {
+			if _, ok := tagged[lowerKey]; !ok {
+				continue
+			}
+		}
+
+		values[key] = value
+		if lowerKey != key {
+			values[lowerKey] = value
+		}
+	}
+
+	return headersDecoder.Decode(ptr, values)
+}
+
+// ReadParams binds the "ptr" with the route's dynamic path parameters. The struct field tag is "param",
+// fields without it are not set.
+// The values already resolved by the parameter types, i.e {id:uint64}, are set as they're
+// when their types are assignable to the fields, otherwise they are converted from their string form.
+// The "ptr" is validated through the `Application.Validator`, if any.
+//
+// Example:
+//	// app.Get("/users/{id:uint64}/posts/{slug}", ...)
+//	type postParams struct {
+//		UserID uint64 `param:"id"`
+//		Slug   string `param:"slug"`
+//	}
+func (ctx *context) ReadParams(ptr interface{}) error {
+	if err := ctx.readParams(ptr); err != nil {
+		return err
+	}
+
+	return validate(ctx.app, ptr)
+}
+
+func (ctx *context) readParams(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		// let the decoder report the error.
+		return paramsDecoder.Decode(ptr, nil)
+	}
+
+	elem := v.Elem()
+	fields := make(map[string]int)
+	for i, n := 0, elem.NumField(); i < n; i++ {
+		field := elem.Type().Field(i)
+		if field.PkgPath != "" { // unexported.
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("param"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fields[name] = i
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	values := make(map[string][]string)
+	for _, entry := range ctx.params.Store {
+		idx, ok := fields[entry.Key]
+		if !ok {
+			continue
+		}
+
+		if entry.ValueRaw != nil {
+			if value := reflect.ValueOf(entry.ValueRaw); value.Type().AssignableTo(elem.Field(idx).Type()) {
+				elem.Field(idx).Set(value)
+				continue
+			}
+		}
+
+		values[entry.Key] = []string{entry.String()}
+	}
+
+	return paramsDecoder.Decode(ptr, values)
+}
+
+// ReadRequest binds the "ptr" with the request body (based on its content type: JSON, XML, YAML or form),
+// the url query (tag "url"), the headers (tag "header") and the route's path parameters (tag "param"),
+// in that order, so a later source overrides the same field of a previous one.
+// Headers and path parameters are bound only to the fields with an explicit "header" or "param" tag.
+// Unknown form and url query keys are ignored.
+// The "ptr" is validated once, through the `Application.Validator`, if any.
+func (ctx *context) ReadRequest(ptr interface{}) error {
+	if err := ctx.readBody(ptr); err != nil && !IsErrPath(err) {
+		return err
+	}
+
+	if err := ctx.readQuery(ptr); err != nil && !IsErrPath(err) {
+		return err
+	}
+
+	if err := ctx.readHeaders(ptr, true); err != nil {
+		return err
+	}
+
+	if err := ctx.readParams(ptr); err != nil {
+		return err
+	}
+
+	return validate(ctx.app, ptr)
+}
+
+// taggedFieldNames returns the lowercase names of the "tag" struct tags of the exported fields of "ptr".
+func taggedFieldNames(ptr interface{}, tag string) map[string]struct{} {
+	typ := reflect.TypeOf(ptr)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	typ = typ.Elem()
+	names := make(map[string]struct{})
+	for i, n := 0, typ.NumField(); i < n; i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		names[strings.ToLower(name)] = struct{}{}
+	}
+
+	return names
+}
+
+// readBody binds the "ptr" with the request body based on the request's content type.
+// It does nothing if the request has no body or its content type is not a known one.
+func (ctx *context) readBody(ptr interface{}) error {
+	if ctx.request.Body == nil || ctx.request.Body == http.NoBody {
+		return nil
+	}
+
+	contentType := ctx.GetContentTypeRequested()
+	if idx := strings.IndexByte(contentType, ';'); idx != -1 {
+		contentType = contentType[:idx]
+	}
+
+	switch strings.TrimSpace(contentType) {
+	case ContentJSONHeaderValue:
+		return ctx.unmarshalBody(ptr, ctx.jsonUnmarshaler())
+	case ContentXMLHeaderValue, ContentXMLUnreadableHeaderValue:
+		return ctx.unmarshalBody(ptr, UnmarshalerFunc(xml.Unmarshal))
+	case ContentYAMLHeaderValue:
+		return ctx.unmarshalBody(ptr, UnmarshalerFunc(yaml.Unmarshal))
+	case ContentFormHeaderValue, ContentFormMultipartHeaderValue:
+		return ctx.readForm(ptr)
+	default:
+		return nil
+	}
+}
+
 //  +------------------------------------------------------------+
 //  | Body (raw) Writers                                         |
 //  +------------------------------------------------------------+
@@ -2711,6 +3106,33 @@ const (
 	VaryHeaderKey = "Vary"
 )
 
+// AddVaryHeader appends the "keys" to the "Vary" header of the "h" response headers.
+// Keys that are already listed are skipped.
+//
+// A handler should call it when its response depends on a request header,
+// i.e "Accept-Language", so caches can keep a separate variant per header value.
+func AddVaryHeader(h http.Header, keys ...string) {
+	existing := h[VaryHeaderKey]
+	for _, key := range keys {
+		if !hasVaryKey(existing, key) {
+			h.Add(VaryHeaderKey, key)
+			existing = h[VaryHeaderKey]
+		}
+	}
+}
+
+func hasVaryKey(values []string, key string) bool {
+	for _, v := range values {
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k == "*" || strings.EqualFold(k, key) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 var unixEpochTime = time.Unix(0, 0)
 
 // IsZeroTime reports whether t is obviously unspecified (either zero or Unix()=0).
@@ -2871,14 +3293,7 @@ func (ctx *context) StreamWriter(writer func(w io.Writer) bool) {
 
 // ClientSupportsGzip retruns true if the client supports gzip compression.
 func (ctx *context) ClientSupportsGzip() bool {
-	if h := ctx.GetHeader(AcceptEncodingHeaderKey); h != "" {
-		for _, v := range strings.Split(h, ";") {
-			if strings.Contains(v, GzipHeaderValue) { // we do Contains because sometimes browsers has the q=, we don't use it atm. || strings.Contains(v,"deflate"){
-				return true
-			}
-		}
-	}
-	return false
+	return ctx.NegotiateEncoding(GzipHeaderValue) == GzipHeaderValue
 }
 
 // ErrGzipNotSupported may be returned from `WriteGzip` methods if
@@ -2917,16 +3332,7 @@ func (ctx *context) TryWriteGzip(b []byte) (int, error) {
 //
 // Can be also disabled with its .Disable and .ResetBody to rollback to the usual response writer.
 func (ctx *context) GzipResponseWriter() *GzipResponseWriter {
-	// if it's already a gzip response writer then just return it
-	if gzipResWriter, ok := ctx.writer.(*GzipResponseWriter); ok {
-		return gzipResWriter
-	}
-	// if it's not acquire a new from a pool
-	// and register that as the ctx.ResponseWriter.
-	gzipResWriter := AcquireGzipResponseWriter()
-	gzipResWriter.BeginGzipResponse(ctx.writer)
-	ctx.ResetResponseWriter(gzipResWriter)
-	return gzipResWriter
+	return ctx.CompressResponseWriter(GzipHeaderValue)
 }
 
 // Gzip enables or disables (if enabled before) the gzip response writer,if the client
@@ -2938,8 +3344,63 @@ func (ctx *context) Gzip(enable bool) {
 			_ = ctx.GzipResponseWriter()
 		}
 	} else {
-		if gzipResWriter, ok := ctx.writer.(*GzipResponseWriter); ok {
-			gzipResWriter.Disable()
+		if compressResWriter, ok := ctx.writer.(*CompressResponseWriter); ok {
+			compressResWriter.Disable()
+		}
+	}
+}
+
+// NegotiateEncoding returns the content coding, of the "available" ones, with the highest quality value
+// based on the client's "Accept-Encoding" request header, i.e "br".
+// If "available" is empty then the `DefaultCompressOptions.Encodings` or all the registered compressors are used instead.
+// Returns empty string if the client does not accept any of them.
+func (ctx *context) NegotiateEncoding(available ...string) string {
+	if len(available) == 0 {
+		available = DefaultCompressOptions.encodings()
+	}
+
+	// only the ones that can be handled.
+	supported := available[:0:0]
+	for _, encoding := range available {
+		if getCompressor(encoding) != nil {
+			supported = append(supported, encoding)
+		}
+	}
+
+	return negotiateEncoding(ctx.GetHeader(AcceptEncodingHeaderKey), supported)
+}
+
+// CompressResponseWriter converts the current response writer into a response writer
+// which when its .Write called it compress the data with the "encoding" algorithm, i.e "br",
+// and writes them to the client.
+// If the response writer is already a compress response writer then it's returned as it's.
+//
+// Can be also disabled with its .Disable and .ResetBody to rollback to the usual response writer.
+func (ctx *context) CompressResponseWriter(encoding string) *CompressResponseWriter {
+	// if it's already a compress response writer then just return it
+	if compressResWriter, ok := ctx.writer.(*CompressResponseWriter); ok {
+		return compressResWriter
+	}
+	// if it's not acquire a new from a pool
+	// and set that as the context's response writer.
+	compressResWriter := AcquireCompressResponseWriter()
+	compressResWriter.BeginCompressResponse(ctx.writer, encoding, DefaultCompressOptions)
+	ctx.ResetResponseWriter(compressResWriter)
+	return compressResWriter
+}
+
+// Compress enables or disables (if enabled before) the compress response writer,
+// the best encoding is picked based on the client's "Accept-Encoding" quality values,
+// so the following response data will be sent compressed to the client.
+// The `DefaultCompressOptions` are used for the allowed encodings, minimum size and content types.
+func (ctx *context) Compress(enable bool) {
+	if enable {
+		if encoding := ctx.NegotiateEncoding(); encoding != "" {
+			_ = ctx.CompressResponseWriter(encoding)
+		}
+	} else {
+		if compressResWriter, ok := ctx.writer.(*CompressResponseWriter); ok {
+			compressResWriter.Disable()
 		}
 	}
 }
@@ -3602,6 +4063,11 @@ func (ctx *context) Negotiation() *NegotiationBuilder {
 	acceptBuilder := NegotiationAcceptBuilder{}
 	acceptBuilder.accept = parseHeader(ctx.GetHeader("Accept"))
 	acceptBuilder.charset = parseHeader(ctx.GetHeader("Accept-Charset"))
+	for _, e := range parseAcceptEncoding(ctx.GetHeader(AcceptEncodingHeaderKey)) {
+		if e.quality > 0 {
+			acceptBuilder.encoding = append(acceptBuilder.encoding, e.name)
+		}
+	}
 
 	n := &NegotiationBuilder{Accept: acceptBuilder}
 
@@ -3655,7 +4121,8 @@ func parseHeader(headerValue string) []string {
 //
 // Read more at: https://github.com/kataras/iris/wiki/Content-negotiation
 func (ctx *context) Negotiate(v interface{}) (int, error) {
-	contentType, charset, encoding, content := ctx.Negotiation().Build()
+	builder := ctx.Negotiation()
+	contentType, charset, encoding, content := builder.Build()
 	if v == nil {
 		v = content
 	}
@@ -3671,8 +4138,19 @@ func (ctx *context) Negotiate(v interface{}) (int, error) {
 		charset = ctx.Application().ConfigurationReadOnly().GetCharset()
 	}
 
-	if encoding == "gzip" {
-		ctx.Gzip(true)
+	// the response depends on the client's accept headers,
+	// let caches know about it.
+	h := ctx.writer.Header()
+	AddVaryHeader(h, "Accept")
+	if len(builder.charset) > 0 {
+		AddVaryHeader(h, "Accept-Charset")
+	}
+	if len(builder.encoding) > 0 {
+		AddVaryHeader(h, AcceptEncodingHeaderKey)
+	}
+
+	if encoding != "" && ctx.NegotiateEncoding(encoding) == encoding {
+		ctx.CompressResponseWriter(encoding)
 	}
 
 	ctx.contentTypeOnce(contentType, charset)
@@ -3996,7 +4474,7 @@ func negotiationMatch(in []string, priorities []string) string {
 			// wildcard is */* or text/* and etc.
 			// so loop through each char.
 			for i, n := 0, len(accepted); i < n; i++ {
-				if accepted[i] != p[i] {
+				if i >= len(p) {
 					break
 				}
 
@@ -4004,6 +4482,10 @@ func negotiationMatch(in []string, priorities []string) string {
 					return p
 				}
 
+				if accepted[i] != p[i] {
+					break
+				}
+
 				if i == n-1 {
 					return p
 				}
@@ -4074,6 +4556,14 @@ func (n *NegotiationAcceptBuilder) MIME(mimeType ...string) *NegotiationAcceptBu
 	return n
 }
 
+// Accepts returns the first of the "mimeType" that matches the accepted client's mime types,
+// which are initialized with the "Accept" request header, or an empty string if none is accepted.
+// The mime types can be wildcards, i.e "*/*" or "application/*".
+// If the client does not declare accepted mime types, the first "mimeType" is returned.
+func (n *NegotiationAcceptBuilder) Accepts(mimeType ...string) string {
+	return negotiationMatch(n.accept, mimeType)
+}
+
 // Text adds the "text/plain" as accepted client content type.
 // Returns itself.
 func (n *NegotiationAcceptBuilder) Text() *NegotiationAcceptBuilder {
@@ -4162,7 +4652,10 @@ func (n *NegotiationAcceptBuilder) EncodingGzip() *NegotiationAcceptBuilder {
 //
 // You can define your own "Content-Type" header also, after this function call
 // Doesn't implements resuming (by range), use ctx.SendFile instead
-func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime time.Time, gzipCompression bool) error {
+//
+// If "compress" is true then the content is compressed with the best encoding
+// that the client accepts, see `DefaultCompressOptions` too.
+func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime time.Time, compress bool) error {
 	if modified, err := ctx.CheckIfModifiedSince(modtime); !modified && err == nil {
 		ctx.WriteNotModified()
 		return nil
@@ -4173,15 +4666,16 @@ func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime
 	}
 
 	ctx.SetLastModified(modtime)
-	var out io.Writer
-	if gzipCompression && ctx.ClientSupportsGzip() {
-		AddGzipHeaders(ctx.writer)
-
-		gzipWriter := acquireGzipWriter(ctx.writer)
-		defer releaseGzipWriter(gzipWriter)
-		out = gzipWriter
-	} else {
-		out = ctx.writer
+	var out io.Writer = ctx.writer
+	if _, ok := ctx.writer.(*CompressResponseWriter); !ok && compress {
+		if encoding := ctx.NegotiateEncoding(); encoding != "" && DefaultCompressOptions.Allow(ctx.GetContentType(), contentSize(content)) {
+			compressWriter, err := acquireCompressWriter(encoding, ctx.writer)
+			if err == nil {
+				AddCompressHeaders(ctx.writer.Header(), encoding)
+				defer releaseCompressWriter(encoding, compressWriter)
+				out = compressWriter
+			}
+		}
 	}
 	_, err := io.Copy(out, content)
 	return err ///TODO: add an int64 as return value for the content length written like other writers or let it as it's in order to keep the stable api?
@@ -4190,13 +4684,13 @@ func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime
 // ServeFile serves a view file, to send a file ( zip for example) to the client you should use the SendFile(serverfilename,clientfilename)
 // receives two parameters
 // filename/path (string)
-// gzipCompression (bool)
+// compress (bool)
 //
 // You can define your own "Content-Type" header also, after this function call
 // This function doesn't implement resuming (by range), use ctx.SendFile instead
 //
 // Use it when you want to serve css/js/... files to the client, for bigger files and 'force-download' use the SendFile.
-func (ctx *context) ServeFile(filename string, gzipCompression bool) error {
+func (ctx *context) ServeFile(filename string, compress bool) error {
 	f, err := os.Open(filename)
 	if err != nil {
 		return fmt.Errorf("%d", http.StatusNotFound)
@@ -4204,10 +4698,24 @@ func (ctx *context) ServeFile(filename string, gzipCompression bool) error {
 	defer f.Close()
 	fi, _ := f.Stat()
 	if fi.IsDir() {
-		return ctx.ServeFile(path.Join(filename, "index.html"), gzipCompression)
+		return ctx.ServeFile(path.Join(filename, "index.html"), compress)
+	}
+
+	return ctx.ServeContent(f, fi.Name(), fi.ModTime(), compress)
+}
+
+// contentSize returns the size of the "content" or -1 if it can't be found.
+func contentSize(content io.Seeker) int {
+	size, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1
+	}
+
+	if _, err = content.Seek(0, io.SeekStart); err != nil {
+		return -1
 	}
 
-	return ctx.ServeContent(f, fi.Name(), fi.ModTime(), gzipCompression)
+	return int(size)
 }
 
 // SendFile sends file for force-download to the client
@@ -0,0 +1,151 @@
+package context
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decompressor is the function which creates a new reader
+// that decompresses the data read from "r", see `RegisterDecompressor`.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressors   = make(map[string]Decompressor)
+	decompressorsMu sync.RWMutex
+)
+
+func init() {
+	RegisterDecompressor(GzipHeaderValue, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+	RegisterDecompressor("x-gzip", func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+	// the "deflate" content coding is the zlib format, see RFC 9110 section 8.4.1.2.
+	RegisterDecompressor(DeflateHeaderValue, func(r io.Reader) (io.ReadCloser, error) {
+		return zlib.NewReader(r)
+	})
+	RegisterDecompressor(BrotliHeaderValue, func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	})
+	RegisterDecompressor(ZstdHeaderValue, func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.IOReadCloser(), nil
+	})
+}
+
+// RegisterDecompressor registers a decompression algorithm for the "encoding" content coding
+// of incoming request bodies, i.e "gzip", "br", "zstd", "deflate".
+// If the "encoding" is already registered then its decompressor is replaced.
+//
+// Built-in decompressors: "gzip" (and "x-gzip"), "deflate", "br" and "zstd".
+func RegisterDecompressor(encoding string, decompressor Decompressor) {
+	decompressorsMu.Lock()
+	decompressors[strings.ToLower(encoding)] = decompressor
+	decompressorsMu.Unlock()
+}
+
+func getDecompressor(encoding string) Decompressor {
+	decompressorsMu.RLock()
+	d := decompressors[encoding]
+	decompressorsMu.RUnlock()
+	return d
+}
+
+// ErrDecompressorNotFound is returned when a request body's content coding
+// has no registered decompressor.
+var ErrDecompressorNotFound = errors.New("decompressor not found")
+
+// decompressReader reads the decompressed data of a request body.
+// Its `Close` closes every decoder and the original body.
+type decompressReader struct {
+	io.Reader
+	decoders []io.Closer
+	body     io.Closer
+}
+
+func (r *decompressReader) Close() error {
+	for i := len(r.decoders) - 1; i >= 0; i-- {
+		r.decoders[i].Close()
+	}
+
+	return r.body.Close()
+}
+
+// NewDecompressReader returns a reader which decompresses the "body"
+// based on the "contentEncoding" header value.
+// Multiple content codings are decoded in the reverse order they were applied.
+// It returns the "body" itself if "contentEncoding" is empty or "identity"
+// and `ErrDecompressorNotFound` if one of the content codings is not registered.
+func NewDecompressReader(body io.ReadCloser, contentEncoding string) (io.ReadCloser, error) {
+	var encodings []string
+	for _, encoding := range strings.Split(contentEncoding, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+
+		if getDecompressor(encoding) == nil {
+			return nil, ErrDecompressorNotFound
+		}
+
+		encodings = append(encodings, encoding)
+	}
+
+	if len(encodings) == 0 {
+		return body, nil
+	}
+
+	r := &decompressReader{Reader: body, body: body}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, err := getDecompressor(encodings[i])(r.Reader)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+
+		r.Reader = decoder
+		r.decoders = append(r.decoders, decoder)
+	}
+
+	return r, nil
+}
+
+// decompressRequest replaces the request's body with a decompressed one
+// and removes the content coding and length headers, as they describe the compressed data.
+func decompressRequest(w http.ResponseWriter, r *http.Request, maxBodySize int64) error {
+	contentEncoding := r.Header.Get(ContentEncodingHeaderKey)
+	if contentEncoding == "" || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := NewDecompressReader(r.Body, contentEncoding)
+	if err != nil {
+		return err
+	}
+
+	if maxBodySize > 0 {
+		// limit the decompressed size as well, a small compressed body
+		// can expand to a large one.
+		body = http.MaxBytesReader(w, body, maxBodySize)
+	}
+
+	r.Body = body
+	r.ContentLength = -1
+	r.Header.Del(ContentEncodingHeaderKey)
+	r.Header.Del(ContentLengthHeaderKey)
+	return nil
+}
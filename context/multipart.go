@@ -0,0 +1,201 @@
+package context
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+)
+
+var (
+	// ErrMultipartPartTooLarge is returned by a file `MultipartPart` read
+	// when its size exceeds the `MultipartOptions.MaxFileSize`.
+	ErrMultipartPartTooLarge = errors.New("multipart: file part too large")
+	// ErrMultipartTooLarge is returned (or wrapped) by a `MultipartPart` read or a `MultipartReader.NextPart`
+	// when the size of the body exceeds the `MultipartOptions.MaxTotalSize`.
+	ErrMultipartTooLarge = errors.New("multipart: total size too large")
+	// ErrMultipartContentTypeNotAllowed is returned by the `MultipartReader.NextPart`
+	// when a file part's content type is not one of the `MultipartOptions.ContentTypes`.
+	// The caller may continue with the next part.
+	ErrMultipartContentTypeNotAllowed = errors.New("multipart: file content type not allowed")
+)
+
+// MultipartOptions holds the limits of a `MultipartReader`.
+type MultipartOptions struct {
+	// MaxFileSize is the maximum size of a single file part, in bytes.
+	// Zero means no limit.
+	MaxFileSize int64
+	// MaxTotalSize is the maximum size of the request body, in bytes,
+	// every byte counts: the form values, the part headers and the skipped or not allowed parts too.
+	// Zero means no limit.
+	//
+	// Note that a `MultipartReader` created by `NewMultipartReader`
+	// can only count the data of the parts read through `MultipartPart.Read`.
+	MaxTotalSize int64
+	// ContentTypes is the list of the allowed content types of file parts,
+	// i.e "image/png" or "image/*". Empty means all.
+	ContentTypes []string
+	// Progress, if not nil, is called on each read of a part,
+	// "read" is the bytes read of that part so far
+	// and "total" is the bytes read of all parts so far.
+	Progress func(part *MultipartPart, read, total int64)
+}
+
+// MultipartReader streams the parts of a "multipart/form-data" request body,
+// one at a time, without buffering them to memory or temporary files.
+//
+// See `Context.MultipartReader`.
+type MultipartReader struct {
+	reader  *multipart.Reader
+	options MultipartOptions
+	total   int64
+	current *MultipartPart
+	// bodyLimited reports whether the `MultipartOptions.MaxTotalSize`
+	// is applied to the source of the reader, see `multipartBodyReader`.
+	bodyLimited bool
+}
+
+// NewMultipartReader returns a new `MultipartReader` which reads from "r" with the "options" limits.
+// The `MultipartOptions.MaxTotalSize` is checked against the data of the parts read through `MultipartPart.Read`,
+// use the `Context.MultipartReader` to limit the whole request body instead.
+func NewMultipartReader(r *multipart.Reader, options MultipartOptions) *MultipartReader {
+	return &MultipartReader{reader: r, options: options}
+}
+
+// NextPart returns the next part of the body, the previous part's data are discarded.
+// It returns io.EOF when there are no more parts and
+// `ErrMultipartContentTypeNotAllowed` when a file part is not allowed.
+func (r *MultipartReader) NextPart() (*MultipartPart, error) {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+
+	part, err := r.reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &MultipartPart{Part: part, reader: r}
+	if p.IsFile() && len(r.options.ContentTypes) > 0 && !matchContentType(p.ContentType(), r.options.ContentTypes) {
+		part.Close()
+		return nil, ErrMultipartContentTypeNotAllowed
+	}
+
+	r.current = p
+	return p, nil
+}
+
+// Total returns the bytes read of all parts so far.
+func (r *MultipartReader) Total() int64 {
+	return r.total
+}
+
+// MultipartPart is a single part of a `MultipartReader`.
+// Its `Read` method enforces the reader's size limits.
+type MultipartPart struct {
+	*multipart.Part
+
+	reader *MultipartReader
+	read   int64
+}
+
+// IsFile reports whether this part is a file one,
+// otherwise it's a form value.
+func (p *MultipartPart) IsFile() bool {
+	return p.FileName() != ""
+}
+
+// ContentType returns the part's "Content-Type" header value,
+// defaults to "application/octet-stream" for files and "text/plain" for form values.
+func (p *MultipartPart) ContentType() string {
+	if contentType := p.Header.Get(ContentTypeHeaderKey); contentType != "" {
+		return contentType
+	}
+
+	if p.IsFile() {
+		return ContentBinaryHeaderValue
+	}
+
+	return ContentTextHeaderValue
+}
+
+// Size returns the bytes read of this part so far.
+func (p *MultipartPart) Size() int64 {
+	return p.read
+}
+
+// Read reads the part's body.
+// It returns `ErrMultipartPartTooLarge` or `ErrMultipartTooLarge`
+// as soon as a limit is exceeded, the data after the limit are not returned.
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	var (
+		options        = p.reader.options
+		limit    int64 = -1
+		limitErr error
+	)
+
+	if options.MaxFileSize > 0 && p.IsFile() {
+		limit, limitErr = options.MaxFileSize-p.read, ErrMultipartPartTooLarge
+	}
+
+	if options.MaxTotalSize > 0 && !p.reader.bodyLimited {
+		if remaining := options.MaxTotalSize - p.reader.total; limit == -1 || remaining < limit {
+			limit, limitErr = remaining, ErrMultipartTooLarge
+		}
+	}
+
+	// read one more byte than the limit to know if it's exceeded.
+	if limit >= 0 && int64(len(b)) > limit+1 {
+		b = b[:limit+1]
+	}
+
+	n, err := p.Part.Read(b)
+	if limit >= 0 && int64(n) > limit {
+		n, err = int(limit), limitErr
+	}
+
+	p.read += int64(n)
+	p.reader.total += int64(n)
+
+	if n > 0 && options.Progress != nil {
+		options.Progress(p, p.read, p.reader.total)
+	}
+
+	return n, err
+}
+
+var _ io.Reader = (*MultipartPart)(nil)
+
+// multipartBodyReader limits the bytes read from a request body to the `MultipartOptions.MaxTotalSize`,
+// it's the source of the `mime/multipart.Reader` of the `Context.MultipartReader`.
+type multipartBodyReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func newMultipartBodyReader(body io.ReadCloser, limit int64) *multipartBodyReader {
+	return &multipartBodyReader{ReadCloser: body, remaining: limit}
+}
+
+// Read returns the `ErrMultipartTooLarge` as soon as the limit is exceeded.
+func (r *multipartBodyReader) Read(b []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ErrMultipartTooLarge
+	}
+
+	// read one more byte than the limit to know if it's exceeded.
+	if int64(len(b)) > r.remaining+1 {
+		b = b[:r.remaining+1]
+	}
+
+	n, err := r.ReadCloser.Read(b)
+	if int64(n) > r.remaining {
+		n, err = int(r.remaining), ErrMultipartTooLarge
+	}
+	r.remaining -= int64(n)
+	if err == ErrMultipartTooLarge {
+		r.remaining = -1
+	}
+
+	return n, err
+}
@@ -0,0 +1,139 @@
+package context_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func multipartBody(t *testing.T, files map[string]string, fileContentType string) (string, []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.WriteField("title", "uploads"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, contents := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="files"; filename="`+name+`"`)
+		h.Set("Content-Type", fileContentType)
+		part, err := w.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = part.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return w.FormDataContentType(), buf.Bytes()
+}
+
+func TestMultipartReader(t *testing.T) {
+	options := context.MultipartOptions{
+		MaxFileSize:  10,
+		ContentTypes: []string{"text/*"},
+	}
+
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		var lastProgress int64
+		opts := options
+		opts.MaxTotalSize = ctx.URLParamInt64Default("max", 0)
+		opts.Progress = func(part *context.MultipartPart, read, total int64) {
+			lastProgress = total
+		}
+
+		r, err := ctx.MultipartReader(opts)
+		if err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			return
+		}
+
+		var result []string
+		for {
+			part, err := r.NextPart()
+			if err == io.EOF {
+				break
+			}
+
+			if err == context.ErrMultipartContentTypeNotAllowed && ctx.URLParamExists("skip") {
+				continue
+			}
+
+			if errors.Is(err, context.ErrMultipartTooLarge) {
+				ctx.StatusCode(iris.StatusRequestEntityTooLarge)
+				ctx.WriteString(context.ErrMultipartTooLarge.Error())
+				return
+			}
+
+			if err != nil {
+				ctx.StatusCode(iris.StatusUnsupportedMediaType)
+				return
+			}
+
+			b, err := ioutil.ReadAll(part)
+			if err != nil {
+				ctx.StatusCode(iris.StatusRequestEntityTooLarge)
+				if errors.Is(err, context.ErrMultipartTooLarge) {
+					err = context.ErrMultipartTooLarge
+				}
+				ctx.WriteString(err.Error())
+				return
+			}
+
+			if part.IsFile() {
+				result = append(result, part.FileName()+"="+string(b))
+			} else {
+				result = append(result, part.FormName()+"="+string(b))
+			}
+		}
+
+		ctx.Writef("%s (%d)", strings.Join(result, ","), lastProgress)
+	})
+
+	e := httptest.New(t, app)
+
+	contentType, body := multipartBody(t, map[string]string{"a.txt": "0123456789"}, "text/plain")
+	e.POST("/").WithHeader("Content-Type", contentType).WithBytes(body).Expect().
+		Status(httptest.StatusOK).Body().Equal("title=uploads,a.txt=0123456789 (17)")
+
+	contentType, body = multipartBody(t, map[string]string{"a.txt": "0123456789a"}, "text/plain")
+	e.POST("/").WithHeader("Content-Type", contentType).WithBytes(body).Expect().
+		Status(httptest.StatusRequestEntityTooLarge).Body().Equal(context.ErrMultipartPartTooLarge.Error())
+
+	// the whole body counts.
+	contentType, body = multipartBody(t, map[string]string{"a.txt": "0123456789", "b.txt": "0123456789", "c.txt": "0123456789"}, "text/plain")
+	e.POST("/").WithQuery("max", len(body)).WithHeader("Content-Type", contentType).WithBytes(body).Expect().
+		Status(httptest.StatusOK)
+	e.POST("/").WithQuery("max", len(body)-1).WithHeader("Content-Type", contentType).WithBytes(body).Expect().
+		Status(httptest.StatusRequestEntityTooLarge).Body().Equal(context.ErrMultipartTooLarge.Error())
+
+	// the skipped parts count too.
+	contentType, body = multipartBody(t, map[string]string{"a.png": strings.Repeat("0", 4096)}, "image/png")
+	e.POST("/").WithQuery("max", 1024).WithQuery("skip", true).WithHeader("Content-Type", contentType).WithBytes(body).Expect().
+		Status(httptest.StatusRequestEntityTooLarge).Body().Equal(context.ErrMultipartTooLarge.Error())
+	e.POST("/").WithQuery("skip", true).WithHeader("Content-Type", contentType).WithBytes(body).Expect().
+		Status(httptest.StatusOK).Body().Equal("title=uploads (7)")
+
+	contentType, body = multipartBody(t, map[string]string{"a.png": "0123456789"}, "image/png")
+	e.POST("/").WithHeader("Content-Type", contentType).WithBytes(body).Expect().
+		Status(httptest.StatusUnsupportedMediaType)
+
+	e.POST("/").WithJSON(iris.Map{"title": "uploads"}).Expect().Status(httptest.StatusBadRequest)
+}
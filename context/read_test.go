@@ -0,0 +1,117 @@
+package context_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestReadHeaders(t *testing.T) {
+	type headers struct {
+		RequestID string   `header:"X-Request-Id,required"`
+		Accept    []string `header:"accept"`
+		Count     int      `header:"X-Count"`
+	}
+
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		var h headers
+		if err := ctx.ReadHeaders(&h); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.JSON(h)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").WithHeader("X-Request-Id", "373713f0").WithHeader("Accept", "text/html").WithHeader("X-Count", "3").
+		Expect().Status(httptest.StatusOK).JSON().Equal(iris.Map{
+		"RequestID": "373713f0",
+		"Accept":    []string{"text/html"},
+		"Count":     3,
+	})
+	e.GET("/").Expect().Status(httptest.StatusBadRequest)
+}
+
+func TestReadParams(t *testing.T) {
+	type params struct {
+		UserID uint64 `param:"id"`
+		Slug   string `param:"slug"`
+		Name   string `param:"name"`
+		Ignore string `param:"-"`
+		Other  string
+	}
+
+	app := iris.New()
+	app.Get("/users/{id:uint64}/posts/{slug}/{name}/{Ignore}/{Other}", func(ctx iris.Context) {
+		var p params
+		if err := ctx.ReadParams(&p); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%d:%s:%s:%s:%s", p.UserID, p.Slug, p.Name, p.Ignore, p.Other)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/users/42/posts/hello-world/kataras/ignored/untagged").Expect().
+		Status(httptest.StatusOK).Body().Equal("42:hello-world:kataras::")
+}
+
+func TestReadRequest(t *testing.T) {
+	type request struct {
+		ID        int    `param:"id" json:"-"`
+		Name      string `json:"name" form:"name"`
+		Page      int    `url:"page" json:"-"`
+		RequestID string `header:"X-Request-Id" json:"-"`
+	}
+
+	app := iris.New()
+	app.Post("/users/{id:int}", func(ctx iris.Context) {
+		var r request
+		if err := ctx.ReadRequest(&r); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%d:%s:%d:%s", r.ID, r.Name, r.Page, r.RequestID)
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/users/7").WithQuery("page", 2).WithHeader("X-Request-Id", "abc").
+		WithJSON(iris.Map{"name": "kataras"}).Expect().
+		Status(httptest.StatusOK).Body().Equal("7:kataras:2:abc")
+	e.POST("/users/7").WithQuery("page", 2).WithFormField("name", "makis").Expect().
+		Status(httptest.StatusOK).Body().Equal("7:makis:2:")
+	e.POST("/users/7").WithHeader("Content-Type", "application/json").WithBytes([]byte("{")).Expect().
+		Status(httptest.StatusBadRequest)
+}
+
+func TestReadRequestTaggedOnly(t *testing.T) {
+	type order struct {
+		ID    string `json:"id"`
+		Token string `json:"token"`
+	}
+
+	app := iris.New()
+	app.Post("/orders/{id}", func(ctx iris.Context) {
+		var o order
+		if err := ctx.ReadRequest(&o); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%s:%s", o.ID, o.Token)
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/orders/7").WithHeader("Token", "from-header").
+		WithJSON(iris.Map{"token": "from-body", "id": "body-id"}).Expect().
+		Status(httptest.StatusOK).Body().Equal("body-id:from-body")
+}
@@ -0,0 +1,303 @@
+package context
+
+import (
+	"bufio"
+	stdContext "context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Timeout returns a Handler which bounds the execution time of the next handlers to "timeout".
+//
+// The request's context (`Context.Request().Context()`) is canceled when the timeout expires,
+// handlers which run long operations should respect its `Done` channel.
+// The response of the next handlers is buffered, it is sent as it is when they complete in time,
+// otherwise a 503 Service Unavailable response is sent through the registered `OnErrorCode` handler
+// as soon as the timeout expires and anything written later is discarded, these writes return the `http.ErrHandlerTimeout`.
+// Flushing, hijacking and HTTP/2 pushing are not supported by the buffered response writer.
+//
+// Note that the connection is not released before the handlers return,
+// even if the client has already received the 503 response.
+//
+// See `Party.SetTimeout` and `Route.SetTimeout` too.
+func Timeout(timeout time.Duration) Handler {
+	return func(ctx Context) {
+		if timeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		r := ctx.Request()
+		original := ctx.ResponseWriter()
+		w := newTimeoutResponseWriter(original)
+
+		// the request's context is canceled after the response writer is marked as timed out,
+		// so the handlers can't write anything after they receive the cancellation.
+		stdCtx, cancel := stdContext.WithCancel(r.Context())
+		ctx.ResetRequest(r.WithContext(&timeoutContext{
+			Context:  stdCtx,
+			deadline: time.Now().Add(timeout),
+			w:        w,
+		}))
+		ctx.ResetResponseWriter(w)
+
+		app, routeName := ctx.Application(), ctx.RouteName()
+		timer := time.AfterFunc(timeout, func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+
+			if w.finished {
+				return
+			}
+
+			w.timedOut = true
+			cancel()
+			fireTimeout(app, routeName, original, r)
+		})
+
+		completed := false
+		defer func() {
+			timer.Stop()
+			cancel()
+
+			// waits for the 503 response, if it's being sent.
+			w.mu.Lock()
+			w.finished = true
+			timedOut := w.timedOut
+			w.mu.Unlock()
+
+			ctx.ResetRequest(r)
+			ctx.ResetResponseWriter(original)
+
+			// on panic the buffered response is discarded.
+			if completed && !timedOut {
+				w.WriteTo(original)
+			}
+		}()
+
+		ctx.Next()
+		completed = true
+	}
+}
+
+// timeoutContext is the request's context of the `Timeout` handler,
+// it reports the deadline and the context.DeadlineExceeded error when it's canceled by the timeout.
+type timeoutContext struct {
+	stdContext.Context
+	deadline time.Time
+	w        *timeoutResponseWriter
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	if parent, ok := c.Context.Deadline(); ok && parent.Before(c.deadline) {
+		return parent, true
+	}
+
+	return c.deadline, true
+}
+
+func (c *timeoutContext) Err() error {
+	err := c.Context.Err()
+	if err == stdContext.Canceled {
+		c.w.mu.Lock()
+		timedOut := c.w.timedOut
+		c.w.mu.Unlock()
+
+		if timedOut {
+			return stdContext.DeadlineExceeded
+		}
+	}
+
+	return err
+}
+
+// fireTimeout sends the 503 Service Unavailable response through the error code handlers
+// with a new Context, the request's one is still used by its handlers.
+func fireTimeout(app Application, routeName string, w ResponseWriter, r *http.Request) {
+	ctx := NewContext(app)
+	ctx.BeginRequest(w, r)
+	ctx.SetCurrentRouteName(routeName)
+	ctx.Record()
+	ctx.StatusCode(http.StatusServiceUnavailable)
+	app.FireErrorCode(ctx)
+
+	// the response is complete for the client only when the handlers return,
+	// unless it knows the body's length.
+	_, direct := w.(*responseWriter)
+	if direct {
+		ctx.Header(ContentLengthHeaderKey, strconv.Itoa(len(ctx.Recorder().Body())))
+	}
+
+	ctx.ResponseWriter().FlushResponse()
+	if direct {
+		w.Write(nil) // sends the status code even if the body is empty.
+		w.Flush()
+	}
+	ctx.ResponseWriter().EndResponse()
+}
+
+// timeoutResponseWriter is the buffered response writer of the `Timeout` handler.
+type timeoutResponseWriter struct {
+	ResponseWriter // the original, only its read-only methods are used.
+
+	header      http.Header
+	statusCode  int
+	body        []byte
+	written     int
+	beforeFlush func()
+
+	mu       sync.Mutex
+	timedOut bool
+	finished bool
+}
+
+var _ ResponseWriter = (*timeoutResponseWriter)(nil)
+
+func newTimeoutResponseWriter(original ResponseWriter) *timeoutResponseWriter {
+	return &timeoutResponseWriter{
+		ResponseWriter: original,
+		header:         original.Header().Clone(),
+		statusCode:     original.StatusCode(),
+		written:        NoWritten,
+		beforeFlush:    original.GetBeforeFlush(),
+	}
+}
+
+// Header returns the buffered response headers.
+func (w *timeoutResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader sets the status code of the buffered response.
+func (w *timeoutResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+// StatusCode returns the status code of the buffered response.
+func (w *timeoutResponseWriter) StatusCode() int {
+	return w.statusCode
+}
+
+// Write appends the "contents" to the buffered response,
+// it returns the `http.ErrHandlerTimeout` if the timeout has expired.
+func (w *timeoutResponseWriter) Write(contents []byte) (int, error) {
+	w.mu.Lock()
+	timedOut := w.timedOut
+	w.mu.Unlock()
+
+	if timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if w.written == NoWritten {
+		w.written = StatusCodeWritten
+	}
+
+	w.body = append(w.body, contents...)
+	w.written += len(contents)
+	return len(contents), nil
+}
+
+// Writef formats according to a format specifier and writes to the buffered response.
+func (w *timeoutResponseWriter) Writef(format string, a ...interface{}) (n int, err error) {
+	return fmt.Fprintf(w, format, a...)
+}
+
+// WriteString writes a simple string to the buffered response.
+func (w *timeoutResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written returns the length of the buffered body.
+func (w *timeoutResponseWriter) Written() int {
+	return w.written
+}
+
+// SetWritten sets manually a value for written, it can be
+// NoWritten(-1) or StatusCodeWritten(0).
+func (w *timeoutResponseWriter) SetWritten(n int) {
+	if n >= NoWritten && n <= StatusCodeWritten {
+		w.written = n
+	}
+}
+
+// SetBeforeFlush registers the unique callback which called exactly before the response is flushed to the client.
+func (w *timeoutResponseWriter) SetBeforeFlush(cb func()) {
+	w.beforeFlush = cb
+}
+
+// GetBeforeFlush returns (not execute) the before flush callback, or nil if not set by SetBeforeFlush.
+func (w *timeoutResponseWriter) GetBeforeFlush() func() {
+	return w.beforeFlush
+}
+
+// BeginResponse does nothing, the buffered response is initialized by the `Timeout` handler.
+func (w *timeoutResponseWriter) BeginResponse(http.ResponseWriter) {}
+
+// EndResponse does nothing, the original response writer is ended by the request's Context.
+func (w *timeoutResponseWriter) EndResponse() {}
+
+// FlushResponse does nothing, the buffered response is written to the original response writer
+// by the `Timeout` handler.
+func (w *timeoutResponseWriter) FlushResponse() {}
+
+// Flusher reports that flushing is not supported.
+func (w *timeoutResponseWriter) Flusher() (http.Flusher, bool) {
+	return nil, false
+}
+
+// Flush does nothing, the response is buffered.
+func (w *timeoutResponseWriter) Flush() {}
+
+var errTimeoutHijack = errors.New("hijack is not supported by the timeout ResponseWriter")
+
+// Hijack returns an error, the response is buffered.
+func (w *timeoutResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errTimeoutHijack
+}
+
+// IsHijacked reports false, hijacking is not supported.
+func (w *timeoutResponseWriter) IsHijacked() bool {
+	return false
+}
+
+// Push returns the `ErrPushNotSupported`.
+func (w *timeoutResponseWriter) Push(target string, opts *http.PushOptions) error {
+	return ErrPushNotSupported
+}
+
+// Clone returns a clone of the buffered response.
+func (w *timeoutResponseWriter) Clone() ResponseWriter {
+	return &timeoutResponseWriter{
+		ResponseWriter: w.ResponseWriter,
+		header:         w.header.Clone(),
+		statusCode:     w.statusCode,
+		body:           w.body[0:],
+		written:        w.written,
+		beforeFlush:    w.beforeFlush,
+	}
+}
+
+// WriteTo writes the buffered response (status code, headers and body) to another response writer.
+func (w *timeoutResponseWriter) WriteTo(to ResponseWriter) {
+	h := to.Header()
+	for k := range h {
+		if _, ok := w.header[k]; !ok {
+			delete(h, k)
+		}
+	}
+	for k, values := range w.header {
+		h[k] = values
+	}
+
+	to.SetBeforeFlush(w.beforeFlush)
+	to.WriteHeader(w.statusCode)
+	if w.written != NoWritten {
+		to.Write(w.body)
+	}
+}
@@ -0,0 +1,67 @@
+package context_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestTimeout(t *testing.T) {
+	app := iris.New()
+	app.OnErrorCode(iris.StatusServiceUnavailable, func(ctx iris.Context) {
+		ctx.WriteString("timeout")
+	})
+
+	app.Get("/fast", func(ctx iris.Context) {
+		ctx.Header("X-Fast", "true")
+		ctx.StatusCode(iris.StatusCreated)
+		ctx.WriteString("fast")
+	}).SetTimeout(time.Second)
+
+	lateWriteErr := make(chan error, 1)
+	app.Get("/slow", func(ctx iris.Context) {
+		select {
+		case <-ctx.Request().Context().Done():
+		case <-time.After(time.Second):
+		}
+
+		_, err := ctx.WriteString("late")
+		lateWriteErr <- err
+	}).SetTimeout(50 * time.Millisecond)
+
+	// the handler does not respect the cancellation.
+	app.Get("/sleep", iris.Timeout(50*time.Millisecond), func(ctx iris.Context) {
+		time.Sleep(150 * time.Millisecond)
+		ctx.Header("X-Late", "true")
+		ctx.WriteString("late")
+	})
+
+	api := app.Party("/api")
+	api.SetTimeout(50 * time.Millisecond)
+	api.Party("/users").Get("/", func(ctx iris.Context) {
+		<-ctx.Request().Context().Done()
+	})
+
+	app.Get("/notfound", func(ctx iris.Context) {
+		ctx.NotFound()
+	}).SetTimeout(time.Second)
+
+	e := httptest.New(t, app)
+	e.GET("/fast").Expect().Status(iris.StatusCreated).Header("X-Fast").Equal("true")
+	e.GET("/fast").Expect().Body().Equal("fast")
+
+	e.GET("/slow").Expect().Status(iris.StatusServiceUnavailable).Body().Equal("timeout")
+	if err := <-lateWriteErr; err != http.ErrHandlerTimeout {
+		t.Fatalf("expected the late write to fail with: %v but got: %v", http.ErrHandlerTimeout, err)
+	}
+
+	resp := e.GET("/sleep").Expect().Status(iris.StatusServiceUnavailable)
+	resp.Body().Equal("timeout")
+	resp.Headers().NotContainsKey("X-Late")
+
+	e.GET("/api/users").Expect().Status(iris.StatusServiceUnavailable).Body().Equal("timeout")
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("Not Found")
+}
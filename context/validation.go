@@ -0,0 +1,134 @@
+package context
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Validator is the validator for request values,
+// i.e the structs that are filled by `ReadJSON`, `ReadXML`, `ReadYAML`, `ReadForm` and `ReadQuery`.
+// Set it to the `Application.Validator` field.
+//
+// The go-playground/validator's `*Validate` value completes this interface.
+type Validator interface {
+	Struct(interface{}) error
+}
+
+// ValidationError describes a field-level validation failure.
+type ValidationError struct {
+	// Field is the name of the invalid field.
+	Field string `json:"field" xml:"field" yaml:"Field"`
+	// Tag is the validation rule which failed, i.e "required", optional.
+	Tag string `json:"tag,omitempty" xml:"tag,omitempty" yaml:"Tag,omitempty"`
+	// Value is the invalid value of the field, optional.
+	Value interface{} `json:"value,omitempty" xml:"-" yaml:"Value,omitempty"`
+	// Reason is the human-readable failure message.
+	Reason string `json:"reason" xml:"reason" yaml:"Reason"`
+}
+
+// Error returns the failure reason.
+func (e ValidationError) Error() string {
+	return e.Reason
+}
+
+// ValidationErrors is a list of field-level validation failures.
+// A `Validator` may return it, other field errors are converted through `AsValidationErrors`.
+type ValidationErrors []ValidationError
+
+// Error returns the failure reasons separated by a comma.
+func (errs ValidationErrors) Error() string {
+	reasons := make([]string, 0, len(errs))
+	for _, e := range errs {
+		reasons = append(reasons, e.Reason)
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
+// fieldError is the minimum interface that a third-party field error should complete,
+// i.e the go-playground/validator's `FieldError`.
+// The `Tag() string` and `Value() interface{}` methods are optional.
+type fieldError interface {
+	Field() string
+	Error() string
+}
+
+func toValidationError(fe fieldError) ValidationError {
+	e := ValidationError{Field: fe.Field(), Reason: fe.Error()}
+	if t, ok := fe.(interface{ Tag() string }); ok {
+		e.Tag = t.Tag()
+	}
+	if v, ok := fe.(interface{ Value() interface{} }); ok {
+		e.Value = v.Value()
+	}
+
+	return e
+}
+
+// AsValidationErrors reports whether the "err" describes field-level validation failures
+// and if so, it returns them as `ValidationErrors`.
+// It accepts a `ValidationErrors` or `ValidationError` value
+// and any error or slice of errors which provide a `Field() string` method,
+// i.e the go-playground/validator's `ValidationErrors`.
+func AsValidationErrors(err error) (ValidationErrors, bool) {
+	switch v := err.(type) {
+	case nil:
+		return nil, false
+	case ValidationErrors:
+		return v, len(v) > 0
+	case ValidationError:
+		return ValidationErrors{v}, true
+	case fieldError:
+		return ValidationErrors{toValidationError(v)}, true
+	}
+
+	val := reflect.ValueOf(err)
+	if val.Kind() != reflect.Slice || val.Len() == 0 {
+		return nil, false
+	}
+
+	errs := make(ValidationErrors, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		fe, ok := val.Index(i).Interface().(fieldError)
+		if !ok {
+			return nil, false
+		}
+
+		errs = append(errs, toValidationError(fe))
+	}
+
+	return errs, true
+}
+
+// NewValidationProblem returns a new 400 Bad Request `Problem`
+// with its "errors" field filled by the field-level validation failures.
+// See `AsValidationErrors` too.
+func NewValidationProblem(errs ValidationErrors) Problem {
+	return NewProblem().
+		Status(http.StatusBadRequest).
+		Title("Your request parameters didn't validate.").
+		Key("errors", errs)
+}
+
+// validate runs the application's validator against the "ptr"
+// if it's a struct value or a pointer to a struct value.
+func validate(app Application, ptr interface{}) error {
+	if app == nil {
+		return nil
+	}
+
+	if typ := indirectType(reflect.TypeOf(ptr)); typ == nil || typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return app.Validate(ptr)
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
@@ -0,0 +1,135 @@
+package context_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/httptest"
+
+	"github.com/gavv/httpexpect"
+)
+
+// testFieldError completes the same methods as the go-playground/validator's FieldError.
+type testFieldError struct {
+	field, tag string
+}
+
+func (e testFieldError) Field() string { return e.field }
+func (e testFieldError) Tag() string   { return e.tag }
+func (e testFieldError) Error() string {
+	return fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", e.field, e.tag)
+}
+
+type testFieldErrors []testFieldError
+
+func (errs testFieldErrors) Error() string { return fmt.Sprintf("%d field errors", len(errs)) }
+
+type testValidator struct{}
+
+func (testValidator) Struct(v interface{}) error {
+	u, ok := v.(*testUser)
+	if !ok {
+		return nil
+	}
+
+	var errs testFieldErrors
+	if u.Name == "" {
+		errs = append(errs, testFieldError{"Name", "required"})
+	}
+	if u.Age < 18 {
+		errs = append(errs, testFieldError{"Age", "gte"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+type testUser struct {
+	Name string `json:"name" form:"name" url:"name"`
+	Age  int    `json:"age" form:"age" url:"age"`
+}
+
+func TestValidator(t *testing.T) {
+	app := iris.New()
+	app.Validator = testValidator{}
+
+	handler := func(read func(iris.Context, interface{}) error) iris.Handler {
+		return func(ctx iris.Context) {
+			var u testUser
+			if err := read(ctx, &u); err != nil {
+				if errs, ok := iris.AsValidationErrors(err); ok {
+					ctx.Problem(iris.NewValidationProblem(errs))
+					return
+				}
+
+				ctx.StatusCode(iris.StatusInternalServerError)
+				return
+			}
+
+			ctx.Writef("%s:%d", u.Name, u.Age)
+		}
+	}
+
+	app.Post("/json", handler(iris.Context.ReadJSON))
+	app.Post("/form", handler(iris.Context.ReadForm))
+	app.Get("/query", handler(iris.Context.ReadQuery))
+	app.Post("/map", func(ctx iris.Context) {
+		var m map[string]interface{}
+		if err := ctx.ReadJSON(&m); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			return
+		}
+
+		ctx.Writef("%v", m["name"])
+	})
+
+	e := httptest.New(t, app)
+	problemJSON := httpexpect.ContentOpts{MediaType: context.ContentJSONProblemHeaderValue}
+
+	e.POST("/json").WithJSON(testUser{Name: "kataras", Age: 27}).Expect().
+		Status(httptest.StatusOK).Body().Equal("kataras:27")
+	e.POST("/form").WithFormField("name", "kataras").WithFormField("age", 27).Expect().
+		Status(httptest.StatusOK).Body().Equal("kataras:27")
+	e.GET("/query").WithQuery("name", "kataras").WithQuery("age", 27).Expect().
+		Status(httptest.StatusOK).Body().Equal("kataras:27")
+	// not a struct, the validator is skipped.
+	e.POST("/map").WithJSON(map[string]interface{}{"name": "kataras"}).Expect().
+		Status(httptest.StatusOK).Body().Equal("kataras")
+
+	expected := map[string]interface{}{
+		"status": iris.StatusBadRequest,
+		"title":  "Your request parameters didn't validate.",
+		"errors": []map[string]interface{}{
+			{"field": "Name", "tag": "required", "reason": "Field validation for 'Name' failed on the 'required' tag"},
+			{"field": "Age", "tag": "gte", "reason": "Field validation for 'Age' failed on the 'gte' tag"},
+		},
+	}
+
+	e.POST("/json").WithJSON(testUser{}).Expect().
+		Status(httptest.StatusBadRequest).
+		JSON(problemJSON).Equal(expected)
+	// empty form and query values are validated too.
+	e.POST("/form").Expect().Status(httptest.StatusBadRequest).JSON(problemJSON).Equal(expected)
+	e.GET("/query").Expect().Status(httptest.StatusBadRequest).JSON(problemJSON).Equal(expected)
+}
+
+func TestAsValidationErrors(t *testing.T) {
+	if _, ok := context.AsValidationErrors(fmt.Errorf("not a field error")); ok {
+		t.Fatalf("expected a non-field error to not be converted")
+	}
+
+	errs, ok := context.AsValidationErrors(testFieldError{"Name", "required"})
+	if !ok || len(errs) != 1 || errs[0].Field != "Name" || errs[0].Tag != "required" {
+		t.Fatalf("unexpected validation errors: %#v", errs)
+	}
+
+	errs, ok = context.AsValidationErrors(context.ValidationErrors{{Field: "Age", Reason: "too young"}})
+	if !ok || errs.Error() != "too young" {
+		t.Fatalf("unexpected validation errors: %#v", errs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kataras/iris/v12/context"
@@ -36,13 +37,18 @@ var AllMethods = []string{
 }
 
 // repository passed to all parties(subrouters), it's the object witch keeps
-// all the routes.
+// all the routes. It's safe for concurrent use, routes can be registered
+// and removed while the router is built and serving.
 type repository struct {
+	mu     sync.RWMutex
 	routes []*Route
 	pos    map[string]int
 }
 
 func (repo *repository) get(routeName string) *Route {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	for _, r := range repo.routes {
 		if r.Name == routeName {
 			return r
@@ -56,8 +62,12 @@ func (repo *repository) getRelative(r *Route) *Route {
 		return nil
 	}
 
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	for _, route := range repo.routes {
-		if r.Subdomain == route.Subdomain && r.Method == route.Method && r.FormattedPath == route.FormattedPath && !route.tmpl.IsTrailing() {
+		// routes with the same template, i.e with different matchers, are not linked here.
+		if r.Subdomain == route.Subdomain && r.Method == route.Method && r.FormattedPath == route.FormattedPath && r.tmpl.Src != route.tmpl.Src && !route.tmpl.IsTrailing() {
 			return route
 		}
 	}
@@ -66,6 +76,9 @@ func (repo *repository) getRelative(r *Route) *Route {
 }
 
 func (repo *repository) getByPath(tmplPath string) *Route {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	if repo.pos != nil {
 		if idx, ok := repo.pos[tmplPath]; ok {
 			if len(repo.routes) > idx {
@@ -77,11 +90,20 @@ func (repo *repository) getByPath(tmplPath string) *Route {
 	return nil
 }
 
+// getAll returns a copy of the registered routes,
+// so the caller can range and sort them while routes are registered or removed.
 func (repo *repository) getAll() []*Route {
-	return repo.routes
+	repo.mu.RLock()
+	routes := make([]*Route, len(repo.routes))
+	copy(routes, repo.routes)
+	repo.mu.RUnlock()
+	return routes
 }
 
 func (repo *repository) register(route *Route, rule RouteRegisterRule) (*Route, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
 	for i, r := range repo.routes {
 		// 14 August 2019 allow register same path pattern with different macro functions,
 		// see #1058
@@ -90,16 +112,18 @@ func (repo *repository) register(route *Route, rule RouteRegisterRule) (*Route,
 				return r, nil
 			} else if rule == RouteError {
 				return nil, fmt.Errorf("new route: %s conflicts with an already registered one: %s route", route.String(), r.String())
-			} else {
-				// replace existing with the latest one, the default behavior.
-				repo.routes = append(repo.routes[:i], repo.routes[i+1:]...)
 			}
 
-			continue
+			// replace existing with the latest one, the default behavior.
+			repo.routes = append(repo.routes[:i], repo.routes[i+1:]...)
+			route.replaced = r
+			repo.reindex()
+			break
 		}
 	}
 
 	repo.routes = append(repo.routes, route)
+	route.repo = repo
 	if repo.pos == nil {
 		repo.pos = make(map[string]int)
 	}
@@ -108,6 +132,81 @@ func (repo *repository) register(route *Route, rule RouteRegisterRule) (*Route,
 	return route, nil
 }
 
+// restore registers back the "replaced" route, before the "route" which replaced it,
+// so the registration order is kept, see `Route.addMatcher`.
+func (repo *repository) restore(replaced, route *Route) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	idx := -1
+	for i, r := range repo.routes {
+		if r.DeepEqual(replaced) {
+			return // registered again by the caller.
+		}
+
+		if r == route {
+			idx = i
+		}
+	}
+
+	if idx == -1 {
+		return
+	}
+
+	repo.routes = append(repo.routes[:idx], append([]*Route{replaced}, repo.routes[idx:]...)...)
+	repo.reindex()
+}
+
+// remove removes the routes which "match" reports true
+// and returns the number of the removed routes.
+func (repo *repository) remove(match func(r *Route) bool) int {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	routes := repo.routes[:0]
+	for _, r := range repo.routes {
+		if !match(r) {
+			routes = append(routes, r)
+		}
+	}
+
+	n := len(repo.routes) - len(routes)
+	if n == 0 {
+		return 0
+	}
+
+	// clear the references of the removed routes.
+	for i := len(routes); i < len(repo.routes); i++ {
+		repo.routes[i] = nil
+	}
+
+	// link the routes of a removed top route to the first remaining one, see `getRelative`.
+	for i, r := range routes {
+		if r.topLink == nil || !match(r.topLink) {
+			continue
+		}
+
+		r.topLink = nil
+		for _, top := range routes[:i] {
+			if top.topLink == nil && r.Subdomain == top.Subdomain && r.Method == top.Method && r.FormattedPath == top.FormattedPath && r.tmpl.Src != top.tmpl.Src && !top.tmpl.IsTrailing() {
+				r.topLink = top
+				break
+			}
+		}
+	}
+
+	repo.routes = routes
+	repo.reindex()
+	return n
+}
+
+func (repo *repository) reindex() {
+	repo.pos = make(map[string]int, len(repo.routes))
+	for i, r := range repo.routes {
+		repo.pos[r.tmpl.Src] = i
+	}
+}
+
 // APIBuilder the visible API for constructing the router
 // and child routers.
 type APIBuilder struct {
@@ -117,6 +216,8 @@ type APIBuilder struct {
 	errorCodeHandlers *ErrorCodeHandlers
 	// the api builder global routes repository
 	routes *repository
+	// the api builder global list of the mounted handlers, see `Mount`.
+	mounted *[]http.Handler
 
 	// the api builder global errors, can be filled by the Subdomain, WildcardSubdomain, Handle...
 	// the list of possible errors that can be
@@ -146,11 +247,24 @@ type APIBuilder struct {
 	// per any party's (and its children) routes registered
 	// if the method "x" wasn't registered already via  the `Handle` (and its extensions like `Get`, `Post`...).
 	allowMethods []string
+	// preflightHandlers are filled with the `Preflight` func.
+	// They are used to register an OPTIONS route
+	// per any party's (and its children) route registered
+	// if an OPTIONS route wasn't registered already for the same path.
+	preflightHandlers context.Handlers
+	// matchers are filled with the `Host`, `Header` and `Query` funcs.
+	// They are the request conditions of any party's (and its children) routes registered.
+	matchers []routeMatcher
+	// timeout is set by the `SetTimeout` func,
+	// it's the execution time limit of any party's (and its children) routes registered.
+	timeout time.Duration
 
 	// the per-party (and its children) execution rules for begin, main and done handlers.
 	handlerExecutionRules ExecutionRules
 	// the per-party (and its children) route registration rule, see `SetRegisterRule`.
 	routeRegisterRule RouteRegisterRule
+	// the per-party (and its children) request path matching policies, see `SetPathRules`.
+	pathRules PathRules
 }
 
 var _ Party = (*APIBuilder)(nil)
@@ -165,6 +279,7 @@ func NewAPIBuilder() *APIBuilder {
 		errors:            errgroup.New("API Builder"),
 		relativePath:      "/",
 		routes:            new(repository),
+		mounted:           new([]http.Handler),
 	}
 
 	return api
@@ -193,6 +308,121 @@ func (api *APIBuilder) AllowMethods(methods ...string) Party {
 	return api
 }
 
+// Preflight registers the "handlers" to answer the OPTIONS requests,
+// i.e the CORS preflight ones, of the future routes that will be registered
+// via `Handle`, `Get`, `Post`, ... on that Party and its children "Parties".
+// An OPTIONS route is registered per path, only if the caller didn't register one,
+// the Party's middleware are not executed for those requests.
+//
+// Call of `Preflight` will override any previous preflight handlers,
+// call it without arguments to disable it.
+//
+// See the "middleware/cors" package for a ready to use preflight handler.
+func (api *APIBuilder) Preflight(handlers ...context.Handler) Party {
+	api.preflightHandlers = handlers
+	return api
+}
+
+// Host makes the future routes of this Party and its children "Parties"
+// to handle only the requests that their host matches the "pattern".
+// The "pattern" is a domain, without the port, which its segments can be macro parameters,
+// i.e "{tenant}.example.com" or "{version:int min(1)}.api.example.com".
+// The parameters' values are stored to the `Context.Params`.
+//
+// Routes with the same method and path but different matchers can be registered,
+// a request is handled by the first one that its matchers pass
+// or the one without matchers, otherwise it fires a 404 Not Found.
+//
+// See `Route.Host` to set it per route.
+func (api *APIBuilder) Host(pattern string) Party {
+	m, err := newHostMatcher(pattern, *api.macros)
+	return api.addMatcher(m, err)
+}
+
+// Header makes the future routes of this Party and its children "Parties"
+// to handle only the requests that their "key" header exists and passes the "macroExpr", see `Host` too.
+// The "macroExpr" is a macro parameter type and its functions, i.e "int min(1)",
+// or a whole named parameter, i.e "{version:int min(1)}", which stores its value to the `Context.Params`.
+// An empty "macroExpr" accepts any value.
+//
+// See `Route.Header` to set it per route.
+func (api *APIBuilder) Header(key, macroExpr string) Party {
+	m, err := newHeaderMatcher(key, macroExpr, *api.macros)
+	return api.addMatcher(m, err)
+}
+
+// Query makes the future routes of this Party and its children "Parties"
+// to handle only the requests that their "key" URL query parameter exists and passes the "macroExpr",
+// see `Header` too.
+//
+// See `Route.Query` to set it per route.
+func (api *APIBuilder) Query(key, macroExpr string) Party {
+	m, err := newQueryMatcher(key, macroExpr, *api.macros)
+	return api.addMatcher(m, err)
+}
+
+// Consumes makes the future routes of this Party and its children "Parties"
+// to handle only the requests that their Content-Type is one of the "mimes",
+// i.e "application/json" or "application/*", requests without a body and a Content-Type pass too.
+//
+// Routes with the same method and path but different Content-Types can be registered,
+// when none of them consumes the request's Content-Type, a 415 Unsupported Media Type
+// is fired through the registered `OnErrorCode` handlers.
+//
+// See `Route.Consumes` to set it per route and `Produces` too.
+func (api *APIBuilder) Consumes(mimes ...string) Party {
+	m, err := newConsumesMatcher(mimes)
+	return api.addMatcher(m, err)
+}
+
+// Produces makes the future routes of this Party and its children "Parties"
+// to handle only the requests that accept one of the "mimes", based on their Accept header
+// and the `Context.Negotiation().Accept`, requests without an Accept header pass too.
+// The "mimes" are registered to the `Context.Negotiation`, so the handlers can call
+// `Context.Negotiate(v)` to render the one the client prefers.
+//
+// Routes with the same method and path but different mime types can be registered,
+// when the client accepts none of them, a 406 Not Acceptable
+// is fired through the registered `OnErrorCode` handlers.
+//
+// See `Route.Produces` to set it per route and `Consumes` too.
+func (api *APIBuilder) Produces(mimes ...string) Party {
+	m, err := newProducesMatcher(mimes)
+	return api.addMatcher(m, err)
+}
+
+func (api *APIBuilder) addMatcher(m routeMatcher, err error) Party {
+	if err != nil {
+		api.errors.Add(err)
+		return api
+	}
+
+	api.matchers = appendMatcher(api.matchers, m)
+	return api
+}
+
+// registerPreflight registers an OPTIONS route with the preflight handlers
+// for the same path of the "route", if not one exists already.
+func (api *APIBuilder) registerPreflight(route *Route) {
+	if len(api.preflightHandlers) == 0 || route.Method == http.MethodOptions || route.Method == MethodNone {
+		return
+	}
+
+	preflight, err := NewRoute(http.MethodOptions, route.Subdomain, route.tmpl.Src,
+		context.HandlerName(api.preflightHandlers[0]), joinHandlers(api.preflightHandlers, context.Handlers{}), *api.macros)
+	if err != nil {
+		api.errors.Add(err)
+		return
+	}
+
+	preflight.SourceFileName = route.SourceFileName
+	preflight.SourceLineNumber = route.SourceLineNumber
+	// don't override a route that may be registered by the caller.
+	if _, err = api.routes.register(preflight, RouteSkip); err != nil {
+		api.errors.Add(err)
+	}
+}
+
 // SetExecutionRules alters the execution flow of the route handlers outside of the handlers themselves.
 //
 // For example, if for some reason the desired result is the (done or all) handlers to be executed no matter what
@@ -245,6 +475,34 @@ func (api *APIBuilder) SetRegisterRule(rule RouteRegisterRule) Party {
 	return api
 }
 
+// SetPathRules sets the request path matching policies of the future routes
+// of this Party and its children "Parties", see `PathRules` for more.
+//
+// Usage:
+// api := app.Party("/api")
+// api.SetPathRules(router.PathRules{
+//   TrailingSlash:   router.TrailingSlashStrict,
+//   CaseInsensitive: true,
+//   CleanPath:       true,
+// })
+// api.Get("/users", listUsers)     // matches "/api/users" and redirects "/API/Users".
+// api.Get("/users/", usersIndex)   // matches "/api/users/".
+func (api *APIBuilder) SetPathRules(rules PathRules) Party {
+	api.pathRules = rules
+	return api
+}
+
+// SetTimeout sets the execution time limit of the future routes that will be registered
+// via `Handle`, `Get`, `Post`, ... on that Party and its children "Parties".
+// When the limit is exceeded the request's context is canceled and the registered
+// `OnErrorCode(503)` handler responds, see `context.Timeout` for more.
+//
+// Call of `SetTimeout` will override any previous timeout, zero disables it.
+func (api *APIBuilder) SetTimeout(timeout time.Duration) Party {
+	api.timeout = timeout
+	return api
+}
+
 // CreateRoutes returns a list of Party-based Routes.
 // It does NOT registers the route. Use `Handle, Get...` methods instead.
 // This method can be used for third-parties Iris helpers packages and tools
@@ -298,6 +556,10 @@ func (api *APIBuilder) CreateRoutes(methods []string, relativePath string, handl
 
 	// here we separate the subdomain and relative path
 	subdomain, path := splitSubdomainAndPath(fullpath)
+	if api.pathRules.TrailingSlash != TrailingSlashRemove && path != "/" && strings.HasSuffix(fullpath, "/") {
+		// keep the trailing slash on the template's source, see `Route.applyPathRules`.
+		path += "/"
+	}
 
 	// if allowMethods are empty, then simply register with the passed, main, method.
 	methods = append(api.allowMethods, methods...)
@@ -313,6 +575,11 @@ func (api *APIBuilder) CreateRoutes(methods []string, relativePath string, handl
 
 		route.SourceFileName = filename
 		route.SourceLineNumber = line
+		if len(api.matchers) > 0 {
+			route.matchers = append([]routeMatcher{}, api.matchers...)
+		}
+		route.Timeout = api.timeout
+		route.applyPathRules(api.pathRules)
 
 		// Add UseGlobal & DoneGlobal Handlers
 		route.Use(api.beginGlobalHandlers...)
@@ -370,6 +637,8 @@ func (api *APIBuilder) Handle(method string, relativePath string, handlers ...co
 			api.errors.Add(err)
 			break
 		}
+
+		api.registerPreflight(route)
 	}
 
 	return route
@@ -520,10 +789,15 @@ func (api *APIBuilder) Party(relativePath string, handlers ...context.Handler) P
 	allowMethods := make([]string, len(api.allowMethods))
 	copy(allowMethods, api.allowMethods)
 
+	// the matchers per party and its children.
+	matchers := make([]routeMatcher, len(api.matchers))
+	copy(matchers, api.matchers)
+
 	return &APIBuilder{
 		// global/api builder
 		macros:              api.macros,
 		routes:              api.routes,
+		mounted:             api.mounted,
 		errorCodeHandlers:   api.errorCodeHandlers,
 		beginGlobalHandlers: api.beginGlobalHandlers,
 		doneGlobalHandlers:  api.doneGlobalHandlers,
@@ -533,8 +807,12 @@ func (api *APIBuilder) Party(relativePath string, handlers ...context.Handler) P
 		doneHandlers:          api.doneHandlers[0:],
 		relativePath:          fullpath,
 		allowMethods:          allowMethods,
+		preflightHandlers:     api.preflightHandlers[0:],
+		matchers:              matchers,
+		timeout:               api.timeout,
 		handlerExecutionRules: api.handlerExecutionRules,
 		routeRegisterRule:     api.routeRegisterRule,
+		pathRules:             api.pathRules,
 	}
 }
 
@@ -603,6 +881,44 @@ func (api *APIBuilder) Macros() *macro.Macros {
 	return api.macros
 }
 
+// RemoveRoute removes a registered route based on its name
+// and reports whether the route was found.
+// A call of `RefreshRouter` is required in order to change to be really applied,
+// it's safe to be called while serving.
+func (api *APIBuilder) RemoveRoute(routeName string) bool {
+	return api.routes.remove(func(r *Route) bool {
+		return r.Name == routeName
+	}) > 0
+}
+
+// RemoveRoutes removes the registered routes of this Party
+// that their path is the "relativePath" or starts with it, i.e "/" removes all of the Party's routes
+// and "/users" removes the "/users", "/users/{id:uint64}" and so on.
+// It returns the number of the removed routes.
+// A call of `RefreshRouter` is required in order to change to be really applied,
+// it's safe to be called while serving.
+func (api *APIBuilder) RemoveRoutes(relativePath string) int {
+	if relativePath == "" {
+		relativePath = "/"
+	}
+
+	if api.relativePath[len(api.relativePath)-1] == '/' && relativePath[0] == '/' {
+		relativePath = relativePath[1:]
+	}
+
+	subdomain, prefix := splitSubdomainAndPath(api.relativePath + relativePath)
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return api.routes.remove(func(r *Route) bool {
+		if r.Subdomain != subdomain {
+			return false
+		}
+
+		src := r.tmpl.Src
+		return prefix == "" || src == prefix || strings.HasPrefix(src, prefix+"/")
+	})
+}
+
 // GetRoutes returns the routes information,
 // some of them can be changed at runtime some others not.
 //
@@ -687,7 +1003,7 @@ func (api *APIBuilder) Use(handlers ...context.Handler) {
 // Use of `ctx.Next()` of those handler(s) is necessary to call the main handler or the next middleware.
 // It's always a good practise to call it right before the `Application#Run` function.
 func (api *APIBuilder) UseGlobal(handlers ...context.Handler) {
-	for _, r := range api.routes.routes {
+	for _, r := range api.routes.getAll() {
 		r.Use(handlers...) // prepend the handlers to the existing routes
 	}
 	// set as begin handlers for the next routes as well.
@@ -713,7 +1029,7 @@ func (api *APIBuilder) Done(handlers ...context.Handler) {
 // Use of `ctx.Next()` at the previous handler is necessary.
 // It's always a good practise to call it right before the `Application#Run` function.
 func (api *APIBuilder) DoneGlobal(handlers ...context.Handler) {
-	for _, r := range api.routes.routes {
+	for _, r := range api.routes.getAll() {
 		r.Done(handlers...) // append the handlers to the existing routes
 	}
 	// set as done handlers for the next routes as well.
@@ -901,18 +1217,25 @@ func (api *APIBuilder) Favicon(favPath string, requestPath ...string) *Route {
 // the body if recorder was enabled
 // and/or disable the gzip if gzip response recorder
 // was active.
+//
+// The handlers fire for the requests under this Party's subdomain and path,
+// the handlers of the parent Parties are the fallbacks,
+// i.e a 404 of "/api/users/x" fires the "/api/users" Party's 404 handler, if registered,
+// otherwise the "/api" Party's one and so on.
 func (api *APIBuilder) OnErrorCode(statusCode int, handlers ...context.Handler) {
 	if len(api.beginGlobalHandlers) > 0 {
 		handlers = joinHandlers(api.beginGlobalHandlers, handlers)
 	}
 
-	api.errorCodeHandlers.Register(statusCode, handlers...)
+	subdomain, path := splitSubdomainAndPath(api.relativePath)
+	api.errorCodeHandlers.RegisterFor(subdomain, path, statusCode, handlers...)
 }
 
 // OnAnyErrorCode registers a handler which called when error status code written.
 // Same as `OnErrorCode` but registers all http error codes based on the `context.StatusCodeNotSuccessful`
 // which defaults to < 200 || >= 400 for an error code, any previous error code will be overridden,
 // so call it first if you want to use any custom handler for a specific error status code.
+// The handlers fire for the requests under this Party's subdomain and path, see `OnErrorCode`.
 //
 // Read more at: http://www.iana.org/assignments/http-status-codes/http-status-codes.xhtml
 func (api *APIBuilder) OnAnyErrorCode(handlers ...context.Handler) {
@@ -924,7 +1247,7 @@ func (api *APIBuilder) OnAnyErrorCode(handlers ...context.Handler) {
 }
 
 // FireErrorCode executes an error http status code handler
-// based on the context's status code.
+// based on the context's status code and the request's subdomain and path.
 //
 // If a handler is not already registered,
 // it creates and registers a new trivial handler on the-fly.
@@ -27,8 +27,12 @@ type DirOptions struct {
 	// that another handler, called index handler, is auto-registered by the framework
 	// if end developer does not managed to handle it by hand.
 	IndexName string
-	// When files should served under compression.
+	// When files should served under gzip compression.
 	Gzip bool
+	// When files should served under compression,
+	// the best encoding that the client accepts is picked, i.e brotli, zstd, gzip or deflate.
+	// See `context.DefaultCompressOptions` for the minimum size and content types.
+	Compress bool
 
 	// List the files inside the current requested directory if `IndexName` not found.
 	ShowList bool
@@ -276,7 +280,7 @@ func FileServer(directory string, opts ...DirOptions) context.Handler {
 	// }
 
 	plainStatusCode := func(ctx context.Context, statusCode int) {
-		if writer, ok := ctx.ResponseWriter().(*context.GzipResponseWriter); ok && writer != nil {
+		if writer, ok := ctx.ResponseWriter().(*context.CompressResponseWriter); ok && writer != nil {
 			writer.ResetBody()
 			writer.Disable()
 		}
@@ -334,10 +338,17 @@ func FileServer(directory string, opts ...DirOptions) context.Handler {
 		name := prefix(ctx.Request().URL.Path, "/")
 		ctx.Request().URL.Path = name
 
-		gzip := options.Gzip
-		if !gzip {
-			// if false then check if the dev did something like `ctx.Gzip(true)`.
-			_, gzip = ctx.ResponseWriter().(*context.GzipResponseWriter)
+		var encoding string
+		if options.Compress {
+			encoding = ctx.NegotiateEncoding()
+		} else if options.Gzip && ctx.ClientSupportsGzip() {
+			encoding = context.GzipHeaderValue
+		}
+
+		compress := encoding != ""
+		if !compress {
+			// if false then check if the dev did something like `ctx.Compress(true)`.
+			_, compress = ctx.ResponseWriter().(*context.CompressResponseWriter)
 		}
 
 		f, err := fs.Open(name)
@@ -425,7 +436,7 @@ func FileServer(directory string, opts ...DirOptions) context.Handler {
 		// and the binary data inside "f".
 		detectOrWriteContentType(ctx, info.Name(), f)
 
-		if gzip {
+		if compress {
 			// set the last modified as "serveContent" does.
 			ctx.SetLastModified(info.ModTime())
 
@@ -440,7 +451,7 @@ func FileServer(directory string, opts ...DirOptions) context.Handler {
 			// Use `WriteNow` instead of `Write`
 			// because we need to know the compressed written size before
 			// the `FlushResponse`.
-			_, err = ctx.GzipResponseWriter().Write(contents)
+			_, err = ctx.CompressResponseWriter(encoding).Write(contents)
 			if err != nil {
 				ctx.Application().Logger().Debugf("short write: %v", err)
 				plainStatusCode(ctx, http.StatusInternalServerError)
@@ -25,8 +25,17 @@ type RequestHandler interface {
 }
 
 type routerHandler struct {
-	trees []*trie
-	hosts bool // true if at least one route contains a Subdomain.
+	trees     []*trie
+	hosts     bool // true if at least one route contains a Subdomain.
+	pathRules bool // true if at least one route has path rules, see `Party.SetPathRules`.
+
+	// linkHandlers are the handlers which decide if a linked route should handle the request instead
+	// of the key route, they're prepended to its handlers, see `Route.topLink` and `matcherLinks`.
+	// They're kept here, and not on the routes, so a build does not modify the routes
+	// while the previous request handler still serves them, see `Router.RefreshRouter`.
+	linkHandlers map[*Route]context.Handlers
+	// matcherLinks are the routes which are executed by their value route if their matchers pass.
+	matcherLinks map[*Route]*Route
 }
 
 var _ RequestHandler = &routerHandler{}
@@ -49,9 +58,13 @@ func (h *routerHandler) AddRoute(r *Route) error {
 		method    = r.Method
 		subdomain = r.Subdomain
 		path      = r.Path
-		handlers  = r.Handlers
+		handlers  = r.withTimeout(r.Handlers)
 	)
 
+	if linkHandlers := h.linkHandlers[r]; len(linkHandlers) > 0 {
+		handlers = joinHandlers(linkHandlers, handlers)
+	}
+
 	t := h.getTree(method, subdomain)
 
 	if t == nil {
@@ -61,7 +74,7 @@ func (h *routerHandler) AddRoute(r *Route) error {
 		h.trees = append(h.trees, t)
 	}
 
-	t.insert(path, routeName, handlers)
+	t.insert(path, routeName, handlers, r.pathRules)
 	return nil
 }
 
@@ -81,13 +94,22 @@ type RoutesProvider interface { // api builder
 
 func (h *routerHandler) Build(provider RoutesProvider) error {
 	h.trees = h.trees[0:0] // reset, inneed when rebuilding.
+	h.hosts, h.pathRules = false, false
+	// reset the links of a previous build.
+	h.linkHandlers = make(map[*Route]context.Handlers)
+	h.matcherLinks = make(map[*Route]*Route)
 	rp := errgroup.New("Routes Builder")
 	registeredRoutes := provider.GetRoutes()
 
 	// before sort.
+	h.bindMatchersHandlers(registeredRoutes)
 	for _, r := range registeredRoutes {
+		if r.matcherErr != nil {
+			rp.Addf("%s: %w", r.String(), r.matcherErr)
+		}
+
 		if r.topLink != nil {
-			bindMultiParamTypesHandler(r.topLink, r)
+			h.bindMultiParamTypesHandler(r.topLink, r)
 		}
 	}
 
@@ -127,7 +149,11 @@ func (h *routerHandler) Build(provider RoutesProvider) error {
 			h.hosts = true
 		}
 
-		if r.topLink == nil {
+		if r.pathRules != (PathRules{}) {
+			h.pathRules = true
+		}
+
+		if _, linked := h.matcherLinks[r]; r.topLink == nil && !linked {
 			// build the r.Handlers based on begin and done handlers, if any.
 			r.BuildHandlers()
 
@@ -148,10 +174,10 @@ func (h *routerHandler) Build(provider RoutesProvider) error {
 	return errgroup.Check(rp)
 }
 
-func bindMultiParamTypesHandler(top *Route, r *Route) {
+func (h *routerHandler) bindMultiParamTypesHandler(top *Route, r *Route) {
 	r.BuildHandlers()
 
-	h := r.Handlers[1:] // remove the macro evaluator handler as we manually check below.
+	handlers := r.withTimeout(r.Handlers[1:]) // remove the macro evaluator handler as we manually check below.
 	f := macroHandler.MakeFilter(r.tmpl)
 	if f == nil {
 		return // should never happen, previous checks made to set the top link.
@@ -162,7 +188,7 @@ func bindMultiParamTypesHandler(top *Route, r *Route) {
 		if f(ctx) {
 			ctx.SetCurrentRouteName(r.Name)
 			ctx.HandlerIndex(0)
-			ctx.Do(h)
+			ctx.Do(handlers)
 			return
 		}
 
@@ -171,42 +197,194 @@ func bindMultiParamTypesHandler(top *Route, r *Route) {
 		ctx.Next()
 	}
 
-	r.topLink.beginHandlers = append(context.Handlers{decisionHandler}, r.topLink.beginHandlers...)
+	h.linkHandlers[top] = append(context.Handlers{decisionHandler}, h.linkHandlers[top]...)
+}
+
+// bindMatchersHandlers links the routes with the same method and path template
+// which differ by their matchers, only one of them is registered to the tree:
+// the last one without matchers, if any, otherwise the last one.
+// The rest are executed by that route if their matchers pass, in the order they were registered.
+func (h *routerHandler) bindMatchersHandlers(routes []*Route) {
+	var (
+		groups  [][]*Route
+		indexes = make(map[string]int)
+	)
+
+	for _, r := range routes {
+		if r.topLink != nil {
+			continue
+		}
+
+		key := r.Method + " " + r.Subdomain + r.tmpl.Src
+		if idx, ok := indexes[key]; ok {
+			groups[idx] = append(groups[idx], r)
+			continue
+		}
+
+		indexes[key] = len(groups)
+		groups = append(groups, []*Route{r})
+	}
+
+	for _, group := range groups {
+		top := group[len(group)-1]
+		for _, r := range group {
+			if len(r.matchers) == 0 {
+				top = r
+			}
+		}
+
+		var links []*Route
+		for _, r := range group {
+			if r != top {
+				h.matcherLinks[r] = top
+				links = append(links, r)
+			}
+		}
+
+		h.bindMatchersHandler(top, links)
+	}
+}
+
+// bindMatchersHandler adds a handler to the "top" route which executes the first of the "links"
+// that its matchers pass, otherwise the "top" itself if its matchers pass.
+// If none passes, it fires the status code of the most specific failed matcher,
+// i.e 415 Unsupported Media Type when a route matches the request but not its Content-Type.
+func (h *routerHandler) bindMatchersHandler(top *Route, links []*Route) {
+	if len(links) == 0 && len(top.matchers) == 0 {
+		return
+	}
+
+	handlers := make([]context.Handlers, len(links))
+	for i, r := range links {
+		r.BuildHandlers()
+		handlers[i] = r.withTimeout(r.Handlers)
+	}
+
+	decisionHandler := func(ctx context.Context) {
+		statusCode := 0
+		for i, r := range links {
+			failed := matchStatus(ctx, r.matchers)
+			if failed == 0 {
+				useProduces(ctx, r.matchers)
+				ctx.SetCurrentRouteName(r.Name)
+				ctx.HandlerIndex(0)
+				ctx.Do(handlers[i])
+				return
+			}
+
+			if statusCodeRank(failed) >= statusCodeRank(statusCode) {
+				statusCode = failed
+			}
+		}
+
+		if failed := matchStatus(ctx, top.matchers); failed != 0 {
+			if statusCodeRank(failed) >= statusCodeRank(statusCode) {
+				statusCode = failed
+			}
+
+			ctx.StatusCode(statusCode)
+			ctx.StopExecution()
+			return
+		}
+
+		useProduces(ctx, top.matchers)
+		ctx.Next()
+	}
+
+	h.linkHandlers[top] = append(context.Handlers{decisionHandler}, h.linkHandlers[top]...)
 }
 
 func (h *routerHandler) HandleRequest(ctx context.Context) {
 	method := ctx.Method()
 	path := ctx.Path()
-	if !ctx.Application().ConfigurationReadOnly().GetDisablePathCorrection() {
+	correct := !ctx.Application().ConfigurationReadOnly().GetDisablePathCorrection()
+
+	if h.pathRules {
+		// the per-party path rules, see `Party.SetPathRules`,
+		// a clean path which matches a route as it is, is served without them.
+		if isCleanPath(path) && h.serve(ctx, method, path) {
+			return
+		}
+
+		corrected, handled := h.correctPath(ctx, method, path)
+		if handled {
+			return
+		}
+
+		if corrected != path {
+			// continue with the corrected path, without the default correction.
+			path, correct = corrected, false
+		}
+	}
+
+	if correct {
 		if len(path) > 1 && strings.HasSuffix(path, "/") {
 			// Remove trailing slash and client-permanent rule for redirection,
 			// if confgiuration allows that and path has an extra slash.
 
 			// update the new path and redirect.
-			r := ctx.Request()
 			// use Trim to ensure there is no open redirect due to two leading slashes
-			path = "/" + strings.Trim(path, "/")
-
-			r.URL.Path = path
-			if !ctx.Application().ConfigurationReadOnly().GetDisablePathCorrectionRedirection() {
-				// do redirect, else continue with the modified path without the last "/".
-				url := r.URL.String()
-
-				// Fixes https://github.com/kataras/iris/issues/921
-				// This is caused for security reasons, imagine a payment shop,
-				// you can't just permantly redirect a POST request, so just 307 (RFC 7231, 6.4.7).
-				if method == http.MethodPost || method == http.MethodPut {
-					ctx.Redirect(url, http.StatusTemporaryRedirect)
-					return
-				}
+			var redirected bool
+			if path, redirected = h.redirectPath(ctx, method, "/"+strings.Trim(path, "/")); redirected {
+				return
+			}
+			// else continue with the modified path without the last "/".
+		}
+	}
 
-				ctx.Redirect(url, http.StatusMovedPermanently)
+	if h.serve(ctx, method, path) {
+		// found
+		return
+	}
+
+	var (
+		config         = ctx.Application().ConfigurationReadOnly()
+		handleOptions  = method == http.MethodOptions && config.GetEnableOptionsHandling()
+		fireNotAllowed = config.GetFireMethodNotAllowed()
+	)
+
+	// if `Configuration#FireMethodNotAllowed` and `EnableOptionsHandling` are kept as defaulted(false)
+	// then this will not run, therefore performance kept as before.
+	if handleOptions || fireNotAllowed {
+		if methods := h.allowedMethods(ctx, path); len(methods) > 0 {
+			if handleOptions {
+				methods = append(methods, http.MethodOptions)
+			}
+
+			// RCF rfc2616 https://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
+			// The response MUST include an Allow header containing a list of valid methods for the requested resource.
+			ctx.Header("Allow", strings.Join(methods, ", "))
+
+			if handleOptions {
+				ctx.StatusCode(http.StatusNoContent)
 				return
 			}
 
+			ctx.StatusCode(http.StatusMethodNotAllowed)
+			return
 		}
 	}
 
+	ctx.StatusCode(http.StatusNotFound)
+}
+
+// serve executes the handlers of the route that matches the "method" and "path",
+// it reports false if not found.
+func (h *routerHandler) serve(ctx context.Context, method, path string) bool {
+	n := h.find(ctx, method, path, ctx.Params(), false)
+	if n == nil {
+		return false
+	}
+
+	ctx.SetCurrentRouteName(n.RouteName)
+	ctx.Do(n.Handlers)
+	return true
+}
+
+// find returns the node of the route that matches the "method", the request's subdomain and the "path",
+// it stores the parameters' values to the "params".
+// If "fold" is true then the static path segments are matched case-insensitively.
+func (h *routerHandler) find(ctx context.Context, method, path string, params *context.RequestParams, fold bool) *trieNode {
 	for i := range h.trees {
 		t := h.trees[i]
 		if method != t.method {
@@ -244,36 +422,45 @@ func (h *routerHandler) HandleRequest(ctx context.Context) {
 				continue
 			}
 		}
-		n := t.search(path, ctx.Params())
-		if n != nil {
-			ctx.SetCurrentRouteName(n.RouteName)
-			ctx.Do(n.Handlers)
-			// found
-			return
-		}
+
 		// not found or method not allowed.
-		break
+		return t.find(path, params, fold)
 	}
 
-	if ctx.Application().ConfigurationReadOnly().GetFireMethodNotAllowed() {
-		for i := range h.trees {
-			t := h.trees[i]
-			// if `Configuration#FireMethodNotAllowed` is kept as defaulted(false) then this function will not
-			// run, therefore performance kept as before.
-			if h.subdomainAndPathAndMethodExists(ctx, t, "", path) {
-				// RCF rfc2616 https://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
-				// The response MUST include an Allow header containing a list of valid methods for the requested resource.
-				ctx.Header("Allow", t.method)
-				ctx.StatusCode(http.StatusMethodNotAllowed)
-				return
-			}
+	return nil
+}
+
+// allowedMethods returns the methods of the online routes registered for the "path",
+// under the request's subdomain.
+func (h *routerHandler) allowedMethods(ctx context.Context, path string) (methods []string) {
+	// the parameters of the searches are discarded, they don't belong to the request's route.
+	params := new(context.RequestParams)
+	for i := range h.trees {
+		t := h.trees[i]
+		if t.method == MethodNone || hasMethod(methods, t.method) {
+			continue
+		}
+
+		params.Reset()
+		if h.subdomainAndPathAndMethodExists(ctx, t, "", path, params) {
+			methods = append(methods, t.method)
 		}
 	}
 
-	ctx.StatusCode(http.StatusNotFound)
+	return
+}
+
+func hasMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (h *routerHandler) subdomainAndPathAndMethodExists(ctx context.Context, t *trie, method, path string) bool {
+func (h *routerHandler) subdomainAndPathAndMethodExists(ctx context.Context, t *trie, method, path string, params *context.RequestParams) bool {
 	if method != "" && method != t.method {
 		return false
 	}
@@ -310,7 +497,7 @@ func (h *routerHandler) subdomainAndPathAndMethodExists(ctx context.Context, t *
 		}
 	}
 
-	n := t.search(path, ctx.Params())
+	n := t.search(path, params)
 	return n != nil
 }
 
@@ -319,7 +506,7 @@ func (h *routerHandler) subdomainAndPathAndMethodExists(ctx context.Context, t *
 func (h *routerHandler) RouteExists(ctx context.Context, method, path string) bool {
 	for i := range h.trees {
 		t := h.trees[i]
-		if h.subdomainAndPathAndMethodExists(ctx, t, method, path) {
+		if h.subdomainAndPathAndMethodExists(ctx, t, method, path, ctx.Params()) {
 			return true
 		}
 	}
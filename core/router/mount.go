@@ -0,0 +1,162 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/macro/handler"
+)
+
+// mountableApp is implemented by the Applications which embed an `APIBuilder`, i.e the `iris.Application`.
+type mountableApp interface {
+	http.Handler
+	apiBuilder() *APIBuilder
+}
+
+func (api *APIBuilder) apiBuilder() *APIBuilder {
+	return api
+}
+
+// Mount registers the "h" under the "prefix" of this Party and returns the Party of the "prefix".
+//
+// If "h" is an `iris.Application` its routes, registered so far, are registered under the "prefix"
+// with their middleware, names and settings, its custom macros and its error handlers are merged,
+// the error handlers fire for the requests under the "prefix", and its views are merged on `Build`.
+// The mounted Application should not be served by itself.
+//
+// Otherwise "h" is a standard http.Handler which serves all the requests under the "prefix",
+// with the "prefix" stripped from their path, i.e "/files/a.txt" is served as "/a.txt" by a "/files" mount.
+//
+// Usage:
+// users := iris.New()
+// users.Get("/{id:uint64}", getUser)
+// app.Mount("/users", users)
+// app.Mount("/debug/vars", expvar.Handler())
+func (api *APIBuilder) Mount(prefix string, h http.Handler) Party {
+	p := api.Party(prefix).(*APIBuilder)
+	*api.mounted = append(*api.mounted, h)
+
+	if app, ok := h.(mountableApp); ok {
+		p.mountAPIBuilder(app.apiBuilder())
+		return p
+	}
+
+	_, prefixPath := splitSubdomainAndPath(p.relativePath)
+	stdHandler := func(ctx context.Context) {
+		r := ctx.Request()
+		path := strings.TrimPrefix(r.URL.Path, prefixPath)
+		if path == "" || path[0] != '/' {
+			path = "/" + path
+		}
+
+		// like the http.StripPrefix, the Context's request is not modified.
+		stripped := new(http.Request)
+		*stripped = *r
+		stripped.URL = new(url.URL)
+		*stripped.URL = *r.URL
+		stripped.URL.Path = path
+		stripped.URL.RawPath = ""
+
+		h.ServeHTTP(ctx.ResponseWriter(), stripped)
+	}
+
+	p.Any("/", stdHandler)
+	p.Any("/{path:path}", stdHandler)
+	return p
+}
+
+// GetMounted returns the handlers mounted so far, see `Mount`.
+func (api *APIBuilder) GetMounted() []http.Handler {
+	return *api.mounted
+}
+
+// mountAPIBuilder registers the routes, the custom macros and the error handlers of the "other"
+// under this Party.
+func (api *APIBuilder) mountAPIBuilder(other *APIBuilder) {
+	if other.macros != api.macros {
+		for _, m := range *other.macros {
+			if api.macros.Get(m.Indent()) == nil && (m.Alias() == "" || api.macros.Get(m.Alias()) == nil) && !m.Master() {
+				*api.macros = append(*api.macros, m)
+			}
+		}
+	}
+
+	for _, r := range other.routes.getAll() {
+		handlers := r.Handlers
+		if handler.CanMakeHandler(r.tmpl) && len(handlers) > 0 {
+			handlers = handlers[1:] // the macro evaluator handler is created again on the new route.
+		}
+		handlers = joinHandlers(r.beginHandlers, joinHandlers(handlers, r.doneHandlers))
+
+		for _, route := range api.CreateRoutes([]string{r.Method}, r.tmpl.Src, handlers...) {
+			if route == nil {
+				break
+			}
+
+			api.copyMountedRoute(route, r)
+			route.topLink = api.routes.getRelative(route)
+			if route, err := api.routes.register(route, api.routeRegisterRule); err != nil {
+				api.errors.Add(err)
+			} else {
+				api.registerPreflight(route)
+			}
+		}
+	}
+
+	subdomain, path := splitSubdomainAndPath(api.relativePath)
+	path = strings.TrimRight(path, "/")
+	for _, h := range other.errorCodeHandlers.list() {
+		if h.builtin {
+			continue
+		}
+
+		hSubdomain := subdomain
+		if h.Subdomain != "" {
+			hSubdomain = h.Subdomain
+		}
+
+		api.errorCodeHandlers.RegisterFor(hSubdomain, path+h.Path, h.StatusCode, h.getHandlers()...)
+	}
+}
+
+// copyMountedRoute copies the source, the name and the settings of the mounted "r" route to the new "route".
+func (api *APIBuilder) copyMountedRoute(route, r *Route) {
+	route.SourceFileName = r.SourceFileName
+	route.SourceLineNumber = r.SourceLineNumber
+	route.MainHandlerName = r.MainHandlerName
+
+	if r.Subdomain != "" && route.Subdomain == "" {
+		route.Subdomain = r.Subdomain
+		route.Name = route.Method + route.Subdomain + route.tmpl.Src
+	}
+
+	if r.Name != r.Method+r.Subdomain+r.tmpl.Src { // not the default one.
+		route.Name = r.Name
+	}
+
+	for _, m := range r.matchers {
+		route.matchers = appendMatcher(route.matchers, m)
+	}
+	if route.matcherErr == nil {
+		route.matcherErr = r.matcherErr
+	}
+
+	if r.Timeout > 0 {
+		route.Timeout = r.Timeout
+	}
+
+	if r.pathRules != (PathRules{}) {
+		route.applyPathRules(r.pathRules)
+	}
+
+	route.LastMod = r.LastMod
+	route.ChangeFreq = r.ChangeFreq
+	route.Priority = r.Priority
+	route.Summary = r.Summary
+	route.Description = r.Description
+	route.Tags = r.Tags
+	route.RequestBody = r.RequestBody
+	route.Responses = r.Responses
+}
@@ -0,0 +1,112 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/context"
+
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func writeErrorFrom(from string) context.Handler {
+	return func(ctx context.Context) {
+		ctx.Writef("%s %d", from, ctx.GetStatusCode())
+	}
+}
+
+func TestPartyOnErrorCode(t *testing.T) {
+	app := iris.New()
+	app.OnErrorCode(iris.StatusNotFound, writeErrorFrom("root"))
+
+	api := app.Party("/api")
+	api.OnErrorCode(iris.StatusNotFound, writeErrorFrom("api"))
+	api.Get("/", func(ctx context.Context) {})
+
+	users := api.Party("/users")
+	users.OnAnyErrorCode(writeErrorFrom("users"))
+	users.Get("/{id:uint64}", func(ctx context.Context) {
+		ctx.StatusCode(iris.StatusForbidden)
+	})
+
+	// no handlers, fallbacks to the parent's ones.
+	api.Party("/products/{id:uint64}").Get("/", func(ctx context.Context) {
+		ctx.StatusCode(iris.StatusBadRequest)
+	})
+
+	// should not match the "/api" Party.
+	app.Get("/apiv2", func(ctx context.Context) {
+		ctx.NotFound()
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("root 404")
+	e.GET("/apiv2").Expect().Status(iris.StatusNotFound).Body().Equal("root 404")
+	e.GET("/api/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("api 404")
+	e.GET("/api/users/42").Expect().Status(iris.StatusForbidden).Body().Equal("users 403")
+	e.GET("/api/users/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("users 404")
+	e.GET("/api/products/42/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("api 404")
+	e.GET("/api/products/42").Expect().Status(iris.StatusBadRequest).Body().Equal(http.StatusText(iris.StatusBadRequest))
+}
+
+func TestPartyOnErrorCodeSubdomain(t *testing.T) {
+	app := iris.New()
+	app.OnErrorCode(iris.StatusNotFound, writeErrorFrom("root"))
+
+	admin := app.Party("admin.")
+	admin.OnErrorCode(iris.StatusNotFound, writeErrorFrom("admin"))
+	admin.Get("/", func(ctx context.Context) {})
+
+	e := httptest.New(t, app)
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("root 404")
+	e.GET("/notfound").WithURL("http://admin.example.com").Expect().Status(iris.StatusNotFound).Body().Equal("admin 404")
+}
+
+func TestMountApplication(t *testing.T) {
+	users := iris.New()
+	users.Macros().Get("string").RegisterFunc("isUsername", func(s string) bool {
+		return len(s) > 2
+	})
+	users.OnErrorCode(iris.StatusNotFound, writeErrorFrom("users"))
+	users.Use(func(ctx context.Context) {
+		ctx.Header("X-Users", "true")
+		ctx.Next()
+	})
+	users.Get("/", func(ctx context.Context) {
+		ctx.WriteString("list")
+	})
+	users.Get("/{name:string isUsername()}", func(ctx context.Context) {
+		ctx.WriteString(ctx.Params().Get("name"))
+	}).Name = "user"
+
+	app := iris.New()
+	app.OnErrorCode(iris.StatusNotFound, writeErrorFrom("root"))
+	app.Mount("/users", users)
+
+	e := httptest.New(t, app)
+	e.GET("/users").Expect().Status(iris.StatusOK).Header("X-Users").Equal("true")
+	e.GET("/users").Expect().Body().Equal("list")
+	e.GET("/users/kataras").Expect().Status(iris.StatusOK).Body().Equal("kataras")
+	e.GET("/users/ab").Expect().Status(iris.StatusNotFound).Body().Equal("users 404")
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("root 404")
+
+	if r := app.GetRoute("user"); r == nil || r.Tmpl().Src != "/users/{name:string isUsername()}" {
+		t.Fatalf("expected the named route of the mounted application to be registered under the prefix but got: %#+v", r)
+	}
+}
+
+func TestMountHandler(t *testing.T) {
+	std := http.NewServeMux()
+	std.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("std " + r.URL.Path))
+	})
+
+	app := iris.New()
+	app.Mount("/std", std)
+
+	e := httptest.New(t, app)
+	e.GET("/std").Expect().Status(iris.StatusOK).Body().Equal("std /")
+	e.GET("/std/a/b.txt").Expect().Status(iris.StatusOK).Body().Equal("std /a/b.txt")
+	e.POST("/std/a").Expect().Status(iris.StatusOK).Body().Equal("std /a")
+}
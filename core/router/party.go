@@ -1,6 +1,9 @@
 package router
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/kataras/iris/v12/context"
 	"github.com/kataras/iris/v12/core/errgroup"
 	"github.com/kataras/iris/v12/macro"
@@ -73,6 +76,77 @@ type Party interface {
 	//
 	// Call of `AllowMethod` will override any previous allow methods.
 	AllowMethods(methods ...string) Party
+	// Preflight registers the "handlers" to answer the OPTIONS requests,
+	// i.e the CORS preflight ones, of the future routes that will be registered
+	// via `Handle`, `Get`, `Post`, ... on that Party and its children "Parties".
+	// An OPTIONS route is registered per path, only if the caller didn't register one,
+	// the Party's middleware are not executed for those requests.
+	//
+	// Call of `Preflight` will override any previous preflight handlers,
+	// call it without arguments to disable it.
+	//
+	// See the "middleware/cors" package for a ready to use preflight handler.
+	Preflight(handlers ...context.Handler) Party
+	// Host makes the future routes of this Party and its children "Parties"
+	// to handle only the requests that their host matches the "pattern".
+	// The "pattern" is a domain, without the port, which its segments can be macro parameters,
+	// i.e "{tenant}.example.com" or "{version:int min(1)}.api.example.com".
+	// The parameters' values are stored to the `Context.Params`.
+	//
+	// Routes with the same method and path but different matchers can be registered,
+	// a request is handled by the first one that its matchers pass
+	// or the one without matchers, otherwise it fires a 404 Not Found.
+	//
+	// See `Route.Host` to set it per route.
+	Host(pattern string) Party
+	// Header makes the future routes of this Party and its children "Parties"
+	// to handle only the requests that their "key" header exists and passes the "macroExpr", see `Host` too.
+	// The "macroExpr" is a macro parameter type and its functions, i.e "int min(1)",
+	// or a whole named parameter, i.e "{version:int min(1)}", which stores its value to the `Context.Params`.
+	// An empty "macroExpr" accepts any value.
+	//
+	// See `Route.Header` to set it per route.
+	Header(key, macroExpr string) Party
+	// Query makes the future routes of this Party and its children "Parties"
+	// to handle only the requests that their "key" URL query parameter exists and passes the "macroExpr",
+	// see `Header` too.
+	//
+	// See `Route.Query` to set it per route.
+	Query(key, macroExpr string) Party
+	// Consumes makes the future routes of this Party and its children "Parties"
+	// to handle only the requests that their Content-Type is one of the "mimes",
+	// i.e "application/json" or "application/*", requests without a body and a Content-Type pass too.
+	//
+	// Routes with the same method and path but different Content-Types can be registered,
+	// when none of them consumes the request's Content-Type, a 415 Unsupported Media Type
+	// is fired through the registered `OnErrorCode` handlers.
+	//
+	// See `Route.Consumes` to set it per route and `Produces` too.
+	Consumes(mimes ...string) Party
+	// Produces makes the future routes of this Party and its children "Parties"
+	// to handle only the requests that accept one of the "mimes", based on their Accept header
+	// and the `Context.Negotiation().Accept`, requests without an Accept header pass too.
+	// The "mimes" are registered to the `Context.Negotiation`, so the handlers can call
+	// `Context.Negotiate(v)` to render the one the client prefers.
+	//
+	// Routes with the same method and path but different mime types can be registered,
+	// when the client accepts none of them, a 406 Not Acceptable
+	// is fired through the registered `OnErrorCode` handlers.
+	//
+	// See `Route.Produces` to set it per route and `Consumes` too.
+	Produces(mimes ...string) Party
+	// RemoveRoute removes a registered route based on its name
+	// and reports whether the route was found.
+	// A call of `RefreshRouter` is required in order to change to be really applied,
+	// it's safe to be called while serving.
+	RemoveRoute(routeName string) bool
+	// RemoveRoutes removes the registered routes of this Party
+	// that their path is the "relativePath" or starts with it, i.e "/" removes all of the Party's routes
+	// and "/users" removes the "/users", "/users/{id:uint64}" and so on.
+	// It returns the number of the removed routes.
+	// A call of `RefreshRouter` is required in order to change to be really applied,
+	// it's safe to be called while serving.
+	RemoveRoutes(relativePath string) int
 
 	// SetExecutionRules alters the execution flow of the route handlers outside of the handlers themselves.
 	//
@@ -101,6 +175,16 @@ type Party interface {
 	// SetRegisterRule sets a `RouteRegisterRule` for this Party and its children.
 	// Available values are: RouteOverride (the default one), RouteSkip and RouteError.
 	SetRegisterRule(rule RouteRegisterRule) Party
+	// SetPathRules sets the request path matching policies of the future routes
+	// of this Party and its children "Parties", see `PathRules` for more.
+	SetPathRules(rules PathRules) Party
+	// SetTimeout sets the execution time limit of the future routes that will be registered
+	// via `Handle`, `Get`, `Post`, ... on that Party and its children "Parties".
+	// When the limit is exceeded the request's context is canceled and the registered
+	// `OnErrorCode(503)` handler responds, see `context.Timeout` for more.
+	//
+	// Call of `SetTimeout` will override any previous timeout, zero disables it.
+	SetTimeout(timeout time.Duration) Party
 	// Handle registers a route to the server's router.
 	// if empty method is passed then handler(s) are being registered to all methods, same as .Any.
 	//
@@ -224,4 +308,29 @@ type Party interface {
 	//
 	// Examples: https://github.com/kataras/iris/tree/master/_examples/view
 	Layout(tmplLayoutFile string) Party
+
+	// OnErrorCode registers an error http status code
+	// based on the "statusCode" < 200 || >= 400 (came from `context.StatusCodeNotSuccessful`).
+	//
+	// The handlers fire for the requests under this Party's subdomain and path,
+	// the handlers of the parent Parties are the fallbacks,
+	// i.e a 404 of "/api/users/x" fires the "/api/users" Party's 404 handler, if registered,
+	// otherwise the "/api" Party's one and so on.
+	OnErrorCode(statusCode int, handlers ...context.Handler)
+	// OnAnyErrorCode registers a handler which called when error status code written.
+	// Same as `OnErrorCode` but registers all http error codes based on the `context.StatusCodeNotSuccessful`
+	// which defaults to < 200 || >= 400 for an error code, any previous error code will be overridden,
+	// so call it first if you want to use any custom handler for a specific error status code.
+	OnAnyErrorCode(handlers ...context.Handler)
+
+	// Mount registers the "h" under the "prefix" of this Party and returns the Party of the "prefix".
+	//
+	// If "h" is an `iris.Application` its routes, registered so far, are registered under the "prefix"
+	// with their middleware, names and settings, its custom macros and its error handlers are merged,
+	// the error handlers fire for the requests under the "prefix", and its views are merged on `Build`.
+	// The mounted Application should not be served by itself.
+	//
+	// Otherwise "h" is a standard http.Handler which serves all the requests under the "prefix",
+	// with the "prefix" stripped from their path, i.e "/files/a.txt" is served as "/a.txt" by a "/files" mount.
+	Mount(prefix string, h http.Handler) Party
 }
@@ -1,11 +1,16 @@
 package router
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"path"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/kataras/iris/v12/core/memstore"
 	"github.com/kataras/iris/v12/core/netutil"
 	"github.com/kataras/iris/v12/macro"
 	"github.com/kataras/iris/v12/macro/interpreter/ast"
@@ -391,3 +396,179 @@ func (ps *RoutePathReverser) URL(routeName string, paramValues ...interface{}) (
 
 	return
 }
+
+// SubdomainParamName is the "params" key of the value of a wildcard subdomain,
+// see `RoutePathReverser.URLFor`.
+const SubdomainParamName = "subdomain"
+
+// URLFor returns the URL of a route based on its name, the values of its dynamic path parameters
+// and an optional url query.
+//
+// The "params" can be a map with string keys, i.e iris.Map, a url.Values or a struct (or a pointer to it)
+// whose fields are matched to the parameters by their "param" tag or their names (case-insensitively), nil for static routes.
+// Each value is checked against the macro type and functions of its parameter,
+// i.e "0" or "abc" are not valid values of a "{id:uint64 min(1)}" parameter.
+// The value of a wildcard subdomain is set through the `SubdomainParamName` key.
+//
+// It returns an absolute URL, i.e "https://admin.mydomain.com/users/42?tab=posts",
+// when the reverser has a host (see `WithHost` and `WithServer`), otherwise just the path and the query.
+// An error is returned when the route does not exist, a value is missing, invalid or unknown
+// or the host of a subdomain route is not known.
+func (ps *RoutePathReverser) URLFor(routeName string, params interface{}, query url.Values) (string, error) {
+	r := ps.provider.GetRoute(routeName)
+	if r == nil {
+		return "", fmt.Errorf("url for: route %q not found", routeName)
+	}
+
+	values, err := paramValues(params)
+	if err != nil {
+		return "", fmt.Errorf("url for %q: %w", routeName, err)
+	}
+
+	tmpl := r.Tmpl()
+	routePath := tmpl.Src
+	store := new(memstore.Store)
+	for i := range tmpl.Params {
+		p := &tmpl.Params[i]
+		name, value, ok := lookupParamValue(values, p.Name)
+		if !ok {
+			return "", fmt.Errorf("url for %q: missing value for parameter %q", routeName, p.Name)
+		}
+		delete(values, name)
+
+		if !evalMacroParam(p, value, store) {
+			return "", fmt.Errorf("url for %q: invalid value %q for parameter %q", routeName, value, p.Name)
+		}
+
+		routePath = strings.Replace(routePath, p.Src, escapePathParam(value, ast.IsTrailing(p.Type)), 1)
+	}
+
+	subdomain := r.Subdomain
+	if subdomain == SubdomainWildcardIndicator {
+		value := values[SubdomainParamName]
+		if value == "" {
+			return "", fmt.Errorf("url for %q: missing value for the wildcard subdomain", routeName)
+		}
+		delete(values, SubdomainParamName)
+
+		subdomain = value + "."
+	}
+
+	if len(values) > 0 {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return "", fmt.Errorf("url for %q: unknown parameter %q", routeName, names[0])
+	}
+
+	if routePath == "" {
+		routePath = "/"
+	}
+
+	if len(query) > 0 {
+		routePath += "?" + query.Encode()
+	}
+
+	if ps.vhost == "" {
+		if subdomain != "" {
+			return "", fmt.Errorf("url for %q: unknown host of the %q subdomain", routeName, subdomain)
+		}
+
+		return routePath, nil
+	}
+
+	return ps.vscheme + "://" + subdomain + ps.vhost + routePath, nil
+}
+
+// paramValues converts the "params" of the `URLFor` to string values by their names.
+func paramValues(params interface{}) (map[string]string, error) {
+	values := make(map[string]string)
+	if params == nil {
+		return values, nil
+	}
+
+	if query, ok := params.(url.Values); ok {
+		for name := range query {
+			values[name] = query.Get(name)
+		}
+		return values, nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(params))
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+
+		for iter := v.MapRange(); iter.Next(); {
+			values[iter.Key().String()] = paramValueString(iter.Value())
+		}
+		return values, nil
+	case reflect.Struct:
+		for i, n := 0, v.NumField(); i < n; i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" { // unexported.
+				continue
+			}
+
+			name := strings.Split(field.Tag.Get("param"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			values[name] = paramValueString(v.Field(i))
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("unsupported params type %T", params)
+}
+
+// lookupParamValue returns the value of the "paramName",
+// the names are matched case-insensitively when there is no exact match, i.e a "Slug" struct field.
+func lookupParamValue(values map[string]string, paramName string) (string, string, bool) {
+	if value, ok := values[paramName]; ok {
+		return paramName, value, true
+	}
+
+	for name, value := range values {
+		if strings.EqualFold(name, paramName) {
+			return name, value, true
+		}
+	}
+
+	return "", "", false
+}
+
+func paramValueString(v reflect.Value) string {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// escapePathParam escapes the "value" of a path parameter,
+// the slashes of a trailing (path type) parameter's value are kept.
+func escapePathParam(value string, trailing bool) string {
+	if !trailing {
+		return url.PathEscape(value)
+	}
+
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,190 @@
+package router
+
+import (
+	"net/http"
+	pathpkg "path"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+// TrailingSlashRule is a type of uint8.
+// Defines how the routes match the request paths that end with a slash.
+// Available values are: TrailingSlashRemove, TrailingSlashAdd and TrailingSlashStrict.
+//
+// See `PathRules` and `Party#SetPathRules`.
+type TrailingSlashRule uint8
+
+const (
+	// TrailingSlashRemove registers the routes without a trailing slash,
+	// a request to "/a/" is redirected to the "/a" route, the default rule.
+	// It follows the `Configuration.DisablePathCorrection` and `DisablePathCorrectionRedirection` fields.
+	TrailingSlashRemove TrailingSlashRule = iota
+	// TrailingSlashAdd registers the routes with a trailing slash,
+	// a request to "/a" is redirected to the "/a/" route.
+	TrailingSlashAdd
+	// TrailingSlashStrict registers the routes as they are given,
+	// "/a" and "/a/" are different routes and a request to the one which is not registered
+	// fires a 404 Not Found, there is no redirect.
+	TrailingSlashStrict
+)
+
+// PathRules are the request path matching policies of the routes of a Party and its children.
+// The zero value is the default behavior, see `TrailingSlashRemove`.
+//
+// The redirects are permanent (301), except for the POST and PUT requests (307),
+// when the `Configuration.DisablePathCorrectionRedirection` is true
+// the request is served by the matched route without a redirect.
+//
+// See `Party#SetPathRules`.
+type PathRules struct {
+	// TrailingSlash defines how the routes match the request paths that end with a slash.
+	TrailingSlash TrailingSlashRule
+	// CaseInsensitive matches a request path, which no route matches as it is,
+	// to a route case-insensitively and redirects it to the route's path,
+	// i.e "/USERS/42" is redirected to "/users/42". The parameters' values are kept as they are.
+	CaseInsensitive bool
+	// CleanPath matches a request path which contains empty, "." or ".." segments
+	// to a route by its clean form and redirects it there,
+	// i.e "/users//42" and "/users/x/../42" are redirected to "/users/42".
+	CleanPath bool
+}
+
+// applyPathRules sets the "rules" to the route and adds or removes the trailing slash of its path,
+// based on the source of its template, i.e "/users/".
+func (r *Route) applyPathRules(rules PathRules) {
+	r.pathRules = rules
+
+	path, formattedPath := r.Path, r.FormattedPath
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+		formattedPath = strings.TrimSuffix(formattedPath, "/")
+	}
+
+	if path != "/" && !r.tmpl.IsTrailing() {
+		switch rules.TrailingSlash {
+		case TrailingSlashAdd:
+			path += "/"
+			formattedPath += "/"
+		case TrailingSlashStrict:
+			if src := r.tmpl.Src; len(src) > 1 && src[len(src)-1] == '/' {
+				path += "/"
+				formattedPath += "/"
+			}
+		}
+	}
+
+	r.Path, r.FormattedPath = path, formattedPath
+}
+
+// correctPath returns the path that the request should be served by, or redirected to,
+// based on the `PathRules` of the matched route.
+// It reports true when the request is handled, i.e it's redirected or not found because of a rule.
+func (h *routerHandler) correctPath(ctx context.Context, method, path string) (string, bool) {
+	var (
+		params    = new(context.RequestParams)
+		hasSlash  = len(path) > 1 && path[len(path)-1] == '/'
+		corrected = ""
+	)
+
+	if hasSlash {
+		// a route which is registered with a trailing slash matches as it is,
+		// the trailing slash is not removed by the default path correction.
+		if n := h.find(ctx, method, path, params, false); n != nil && strings.HasSuffix(n.key, "/") {
+			h.serve(ctx, method, path)
+			return path, true
+		}
+
+		trimmed := "/" + strings.Trim(path, "/")
+		if n := h.find(ctx, method, trimmed, params, false); n != nil && n.rules.TrailingSlash == TrailingSlashStrict {
+			ctx.NotFound()
+			return path, true
+		}
+	} else if len(path) > 1 && h.find(ctx, method, path, params, false) == nil {
+		if n := h.find(ctx, method, path+"/", params, false); n != nil && n.rules.TrailingSlash == TrailingSlashAdd {
+			corrected = path + "/"
+		}
+	}
+
+	if corrected == "" && (strings.Contains(path, "//") || strings.Contains(path, "/.")) {
+		clean := pathpkg.Clean(path)
+		if hasSlash && clean != "/" {
+			clean += "/"
+		}
+
+		if n := h.find(ctx, method, clean, params, false); n != nil && n.rules.CleanPath {
+			corrected = clean
+		} else if n = h.find(ctx, method, clean, params, true); n != nil && n.rules.CleanPath && n.rules.CaseInsensitive {
+			corrected = canonicalPath(n.key, clean)
+		}
+	}
+
+	if corrected == "" && h.find(ctx, method, path, params, false) == nil {
+		if n := h.find(ctx, method, path, params, true); n != nil && n.rules.CaseInsensitive {
+			corrected = canonicalPath(n.key, path)
+		}
+	}
+
+	if corrected == "" || corrected == path {
+		return path, false
+	}
+
+	return h.redirectPath(ctx, method, corrected)
+}
+
+// isCleanPath reports whether the "path" has no trailing slash, empty, "." or ".." segments,
+// so the `PathRules` can't change the route it matches.
+func isCleanPath(path string) bool {
+	return (len(path) <= 1 || path[len(path)-1] != '/') && !strings.Contains(path, "//") && !strings.Contains(path, "/.")
+}
+
+// redirectPath redirects the request to the "path", keeping its query,
+// or, if the `Configuration.DisablePathCorrectionRedirection` is true,
+// returns the "path" to be served without a redirect.
+func (h *routerHandler) redirectPath(ctx context.Context, method, path string) (string, bool) {
+	r := ctx.Request()
+	r.URL.Path = path
+	r.URL.RawPath = ""
+
+	if ctx.Application().ConfigurationReadOnly().GetDisablePathCorrectionRedirection() {
+		return path, false
+	}
+
+	url := r.URL.String()
+
+	// Fixes https://github.com/kataras/iris/issues/921
+	// This is caused for security reasons, imagine a payment shop,
+	// you can't just permantly redirect a POST request, so just 307 (RFC 7231, 6.4.7).
+	if method == http.MethodPost || method == http.MethodPut {
+		ctx.Redirect(url, http.StatusTemporaryRedirect)
+		return path, true
+	}
+
+	ctx.Redirect(url, http.StatusMovedPermanently)
+	return path, true
+}
+
+// canonicalPath returns the request "path" with the static segments of the route's "key",
+// i.e "/USERS/42" and "/users/:id" result to "/users/42".
+func canonicalPath(key, path string) string {
+	keySegments := strings.Split(key, "/")
+	segments := strings.Split(path, "/")
+
+	for i, s := range keySegments {
+		if i >= len(segments) {
+			break
+		}
+
+		if s == "" || s[0] == ParamStart[0] {
+			continue
+		}
+
+		if s[0] == WildcardParamStart[0] {
+			break
+		}
+
+		segments[i] = s
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,112 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+
+	"github.com/gavv/httpexpect"
+)
+
+// withoutRedirects returns a client of the "app" which does not follow the redirects.
+func withoutRedirects(app *iris.Application) *http.Client {
+	return &http.Client{
+		Transport: httpexpect.NewBinder(app),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func writeRouteName(ctx iris.Context) {
+	ctx.WriteString(ctx.GetCurrentRoute().Path())
+}
+
+func TestPathRulesTrailingSlash(t *testing.T) {
+	app := iris.New()
+	app.Get("/default", writeRouteName)
+
+	strict := app.Party("/strict").SetPathRules(iris.PathRules{TrailingSlash: iris.TrailingSlashStrict})
+	strict.Get("/a", writeRouteName)
+	strict.Get("/a/", writeRouteName)
+	strict.Get("/b", writeRouteName)
+	strict.Get("/c/", writeRouteName)
+
+	add := app.Party("/add").SetPathRules(iris.PathRules{TrailingSlash: iris.TrailingSlashAdd})
+	add.Get("/", writeRouteName)
+	add.Get("/users/{id:uint64}", writeRouteName)
+	add.Post("/users", writeRouteName)
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	noRedirects := withoutRedirects(app)
+
+	e.GET("/default").Expect().Status(httptest.StatusOK).Body().Equal("/default")
+	e.GET("/default/").Expect().Status(httptest.StatusOK).Body().Equal("/default")
+
+	e.GET("/strict/a").Expect().Status(httptest.StatusOK).Body().Equal("/strict/a")
+	e.GET("/strict/a/").Expect().Status(httptest.StatusOK).Body().Equal("/strict/a/")
+	e.GET("/strict/b").Expect().Status(httptest.StatusOK).Body().Equal("/strict/b")
+	e.GET("/strict/b/").Expect().Status(httptest.StatusNotFound)
+	e.GET("/strict/c/").Expect().Status(httptest.StatusOK).Body().Equal("/strict/c/")
+	e.GET("/strict/c").Expect().Status(httptest.StatusNotFound)
+
+	e.GET("/add").Expect().Status(httptest.StatusOK).Body().Equal("/add/")
+	e.GET("/add/").Expect().Status(httptest.StatusOK).Body().Equal("/add/")
+	e.GET("/add/users/42").Expect().Status(httptest.StatusOK).Body().Equal("/add/users/{id:uint64}")
+	e.GET("/add/users/42").WithClient(noRedirects).
+		Expect().Status(httptest.StatusMovedPermanently).Header("Location").Equal("http://example.com/add/users/42/")
+	e.POST("/add/users").WithQuery("q", "1").WithClient(noRedirects).
+		Expect().Status(httptest.StatusTemporaryRedirect).Header("Location").Equal("http://example.com/add/users/?q=1")
+}
+
+func TestPathRulesCaseInsensitiveAndCleanPath(t *testing.T) {
+	app := iris.New()
+	app.Get("/Default", writeRouteName)
+
+	p := app.Party("/api").SetPathRules(iris.PathRules{CaseInsensitive: true, CleanPath: true})
+	p.Get("/users/{name}", func(ctx iris.Context) {
+		ctx.WriteString(ctx.Path())
+	})
+	p.Get("/Products", writeRouteName)
+	// differ only by case, the lowest one by byte order is matched.
+	p.Get("/items", writeRouteName)
+	p.Get("/Items", writeRouteName)
+	p.Get("/ITEMS", writeRouteName)
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	noRedirects := withoutRedirects(app)
+
+	e.GET("/default").Expect().Status(httptest.StatusNotFound)
+
+	e.GET("/API/Users/Kataras").WithClient(noRedirects).
+		Expect().Status(httptest.StatusMovedPermanently).Header("Location").Equal("http://example.com/api/users/Kataras")
+	e.GET("/API/Users/Kataras").Expect().Status(httptest.StatusOK).Body().Equal("/api/users/Kataras")
+	e.GET("/api/products").Expect().Status(httptest.StatusOK).Body().Equal("/api/Products")
+
+	e.GET("/api/Items").Expect().Status(httptest.StatusOK).Body().Equal("/api/Items")
+	for i := 0; i < 20; i++ {
+		e.GET("/api/iTems").Expect().Status(httptest.StatusOK).Body().Equal("/api/ITEMS")
+	}
+
+	e.GET("/api//Products").WithClient(noRedirects).
+		Expect().Status(httptest.StatusMovedPermanently).Header("Location").Equal("http://example.com/api/Products")
+	e.GET("/api/x/../Products").WithClient(noRedirects).
+		Expect().Status(httptest.StatusMovedPermanently).Header("Location").Equal("http://example.com/api/Products")
+}
+
+func TestPathRulesWithoutRedirection(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithoutPathCorrectionRedirection)
+
+	p := app.Party("/api").SetPathRules(iris.PathRules{TrailingSlash: iris.TrailingSlashAdd, CaseInsensitive: true})
+	p.Get("/users", func(ctx iris.Context) {
+		ctx.WriteString(ctx.Path())
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/api/users").Expect().Status(httptest.StatusOK).Body().Equal("/api/users/")
+	e.GET("/API/USERS/").Expect().Status(httptest.StatusOK).Body().Equal("/api/users/")
+	e.Request(http.MethodGet, "/api/users/").Expect().Status(httptest.StatusOK)
+}
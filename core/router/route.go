@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -47,10 +48,35 @@ type Route struct {
 	StaticSites []context.StaticSite `json:"staticSites"`
 	topLink     *Route
 
+	// matchers are the request conditions besides the method and the path,
+	// see `Host`, `Header` and `Query`.
+	matchers   []routeMatcher
+	matcherErr error
+	// replaced is the route with the same path that this route replaced on registration,
+	// it's registered back if this route receives matchers.
+	replaced *Route
+	repo     *repository
+	macros   macro.Macros
+
+	// Timeout is the execution time limit of the route's handlers, zero means no limit.
+	// See `SetTimeout`.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// pathRules are the request path matching policies of the route, see `Party.SetPathRules`.
+	pathRules PathRules
+
 	// Sitemap properties: https://www.sitemaps.org/protocol.html
 	LastMod    time.Time `json:"lastMod,omitempty"`
 	ChangeFreq string    `json:"changeFreq,omitempty"`
 	Priority   float32   `json:"priority,omitempty"`
+
+	// OpenAPI properties, see the "openapi" package.
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// RequestBody is an example of the request body, its type describes the body's schema.
+	RequestBody interface{} `json:"-"`
+	// Responses are examples of the responses per status code, their types describe the responses' schemas.
+	Responses map[int]interface{} `json:"-"`
 }
 
 // NewRoute returns a new route based on its method,
@@ -87,6 +113,7 @@ func NewRoute(method, subdomain, unparsedPath, mainHandlerName string,
 		Handlers:        handlers,
 		MainHandlerName: mainHandlerName,
 		FormattedPath:   formattedPath,
+		macros:          macros,
 	}
 
 	return route, nil
@@ -175,9 +202,107 @@ func (r *Route) Equal(other *Route) bool {
 
 // DeepEqual compares the method, subdomain, the
 // underline representation of the route's path,
-// and the template source.
+// the template source and the matchers.
 func (r *Route) DeepEqual(other *Route) bool {
-	return r.Equal(other) && r.tmpl.Src == other.tmpl.Src
+	return r.Equal(other) && r.tmpl.Src == other.tmpl.Src && matchersSource(r.matchers) == matchersSource(other.matchers)
+}
+
+// Host makes this route to handle only the requests that their host matches the "pattern".
+// The "pattern" is a domain, without the port, which its segments can be macro parameters,
+// i.e "{tenant}.example.com" or "{version:int min(1)}.api.example.com".
+// The parameters' values are stored to the `Context.Params`.
+//
+// Routes with the same method and path but different matchers can be registered,
+// a request is handled by the first one that its matchers pass
+// or the one without matchers, otherwise it fires a 404 Not Found.
+func (r *Route) Host(pattern string) *Route {
+	m, err := newHostMatcher(pattern, r.macros)
+	return r.addMatcher(m, err)
+}
+
+// Header makes this route to handle only the requests that
+// their "key" header exists and passes the "macroExpr", see `Host` too.
+// The "macroExpr" is a macro parameter type and its functions, i.e "int min(1)",
+// or a whole named parameter, i.e "{version:int min(1)}", which stores its value to the `Context.Params`.
+// An empty "macroExpr" accepts any value.
+func (r *Route) Header(key, macroExpr string) *Route {
+	m, err := newHeaderMatcher(key, macroExpr, r.macros)
+	return r.addMatcher(m, err)
+}
+
+// Query makes this route to handle only the requests that
+// their "key" URL query parameter exists and passes the "macroExpr", see `Header` too.
+func (r *Route) Query(key, macroExpr string) *Route {
+	m, err := newQueryMatcher(key, macroExpr, r.macros)
+	return r.addMatcher(m, err)
+}
+
+// Consumes makes this route to handle only the requests that their Content-Type is one of the "mimes",
+// i.e "application/json" or "application/*", requests without a body and a Content-Type pass too.
+//
+// Routes with the same method and path but different Content-Types can be registered,
+// when none of them consumes the request's Content-Type, a 415 Unsupported Media Type
+// is fired through the registered `OnErrorCode` handlers.
+//
+// It overrides the Party's `Consumes`.
+func (r *Route) Consumes(mimes ...string) *Route {
+	m, err := newConsumesMatcher(mimes)
+	r.matchers = removeMatchers(r.matchers, http.StatusUnsupportedMediaType)
+	return r.addMatcher(m, err)
+}
+
+// Produces makes this route to handle only the requests that accept one of the "mimes",
+// based on their Accept header and the `Context.Negotiation().Accept`,
+// requests without an Accept header pass too.
+// The "mimes" are registered to the `Context.Negotiation`, so the handlers can call
+// `Context.Negotiate(v)` to render the one the client prefers.
+//
+// Routes with the same method and path but different mime types can be registered,
+// when the client accepts none of them, a 406 Not Acceptable
+// is fired through the registered `OnErrorCode` handlers.
+//
+// It overrides the Party's `Produces`.
+func (r *Route) Produces(mimes ...string) *Route {
+	m, err := newProducesMatcher(mimes)
+	r.matchers = removeMatchers(r.matchers, http.StatusNotAcceptable)
+	return r.addMatcher(m, err)
+}
+
+func (r *Route) addMatcher(m routeMatcher, err error) *Route {
+	if err != nil {
+		// reported on build.
+		r.matcherErr = err
+		return r
+	}
+
+	r.matchers = appendMatcher(r.matchers, m)
+
+	// a route without matchers and with the same path was replaced by this route
+	// before its matchers were set, register that back.
+	if replaced := r.replaced; replaced != nil && r.repo != nil {
+		r.replaced = nil
+		r.repo.restore(replaced, r)
+	}
+
+	return r
+}
+
+// SetTimeout sets the execution time limit of the route's handlers,
+// when it's exceeded the request's context is canceled and the registered
+// `OnErrorCode(503)` handler responds, see `context.Timeout` for more.
+// Zero disables it. The caller should refresh the router if it's called at serve-time.
+func (r *Route) SetTimeout(timeout time.Duration) *Route {
+	r.Timeout = timeout
+	return r
+}
+
+// withTimeout returns the "handlers" prefixed by the `context.Timeout` handler if the route has a timeout.
+func (r *Route) withTimeout(handlers context.Handlers) context.Handlers {
+	if r.Timeout <= 0 {
+		return handlers
+	}
+
+	return joinHandlers(context.Handlers{context.Timeout(r.Timeout)}, handlers)
 }
 
 // SetLastMod sets the date of last modification of the file served by this static GET route.
@@ -206,6 +331,44 @@ func (r *Route) SetPriority(prio float32) *Route {
 	return r
 }
 
+// SetSummary sets a short summary of what this route does, used by API documents.
+func (r *Route) SetSummary(summary string) *Route {
+	r.Summary = summary
+	return r
+}
+
+// SetDescription sets a verbose explanation of this route's behavior, used by API documents.
+func (r *Route) SetDescription(description string) *Route {
+	r.Description = description
+	return r
+}
+
+// SetTags sets the tags which group this route in API documents.
+func (r *Route) SetTags(tags ...string) *Route {
+	r.Tags = tags
+	return r
+}
+
+// SetRequestBody sets an example of this route's request body, used by API documents.
+// Its type describes the body's schema, a zero value or a nil pointer of a type can be passed
+// to describe the schema without an example, i.e SetRequestBody(User{}) or SetRequestBody((*User)(nil)).
+func (r *Route) SetRequestBody(example interface{}) *Route {
+	r.RequestBody = example
+	return r
+}
+
+// SetResponse sets an example of this route's response for the "statusCode", used by API documents.
+// Its type describes the response's schema, see `SetRequestBody` too.
+// A nil "example" describes a response without a body.
+func (r *Route) SetResponse(statusCode int, example interface{}) *Route {
+	if r.Responses == nil {
+		r.Responses = make(map[int]interface{})
+	}
+
+	r.Responses[statusCode] = example
+	return r
+}
+
 // Tmpl returns the path template,
 // it contains the parsed template
 // for the route's path.
@@ -0,0 +1,411 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/memstore"
+	"github.com/kataras/iris/v12/macro"
+)
+
+// routeMatcher is a request condition of a route,
+// besides its method and path, see `Party.Host`, `Party.Header` and `Party.Query`.
+// Routes with the same method and path but different matchers can be registered,
+// the first route that its matchers pass handles the request.
+type routeMatcher struct {
+	// src is the matcher's description, i.e Host({tenant}.example.com),
+	// it's used to compare routes.
+	src   string
+	match func(ctx context.Context) bool
+	// statusCode is the error code fired when no route passes because of this matcher,
+	// i.e 415 for the `Consumes` ones, zero means 404.
+	statusCode int
+	// produces are the mime types of the `Produces` matcher,
+	// registered to the `Context.Negotiation` when the route is selected.
+	produces []string
+}
+
+// rank reports the evaluation order of the matcher, the content negotiation ones are evaluated last,
+// so the more specific status code is fired when no route passes:
+// 404 Not Found, then 415 Unsupported Media Type and then 406 Not Acceptable.
+func (m routeMatcher) rank() int {
+	return statusCodeRank(m.statusCode)
+}
+
+func statusCodeRank(statusCode int) int {
+	switch statusCode {
+	case http.StatusUnsupportedMediaType:
+		return 1
+	case http.StatusNotAcceptable:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// appendMatcher adds the "m" to the "matchers" by its rank.
+func appendMatcher(matchers []routeMatcher, m routeMatcher) []routeMatcher {
+	i := len(matchers)
+	for i > 0 && matchers[i-1].rank() > m.rank() {
+		i--
+	}
+
+	matchers = append(matchers, routeMatcher{})
+	copy(matchers[i+1:], matchers[i:])
+	matchers[i] = m
+	return matchers
+}
+
+func matchersSource(matchers []routeMatcher) string {
+	if len(matchers) == 0 {
+		return ""
+	}
+
+	srcs := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		srcs = append(srcs, m.src)
+	}
+
+	return strings.Join(srcs, " ")
+}
+
+// removeMatchers returns the "matchers" except the ones that fire the "statusCode",
+// i.e the `Consumes` ones for 415.
+func removeMatchers(matchers []routeMatcher, statusCode int) []routeMatcher {
+	filtered := make([]routeMatcher, 0, len(matchers))
+	for _, m := range matchers {
+		if m.statusCode != statusCode {
+			filtered = append(filtered, m)
+		}
+	}
+
+	return filtered
+}
+
+// matchStatus returns zero if all the "matchers" pass,
+// otherwise the status code of the first one that does not.
+func matchStatus(ctx context.Context, matchers []routeMatcher) int {
+	for _, m := range matchers {
+		if !m.match(ctx) {
+			if m.statusCode == 0 {
+				return http.StatusNotFound
+			}
+
+			return m.statusCode
+		}
+	}
+
+	return 0
+}
+
+// useProduces registers the mime types of the `Produces` "matchers" of the selected route
+// to the `Context.Negotiation`, so `Context.Negotiate` renders one of them.
+func useProduces(ctx context.Context, matchers []routeMatcher) {
+	for _, m := range matchers {
+		if len(m.produces) == 0 {
+			continue
+		}
+
+		// the response depends on the Accept header, let caches know about it.
+		context.AddVaryHeader(ctx.ResponseWriter().Header(), "Accept")
+
+		n := ctx.Negotiation()
+		for _, mime := range m.produces {
+			if !strings.Contains(mime, "*") {
+				n.MIME(mime, nil)
+			}
+		}
+	}
+}
+
+// parseMacroParam parses a single macro parameter, i.e {tenant:string regexp(^[a-z]+$)}.
+func parseMacroParam(src string, macros macro.Macros) (macro.TemplateParam, error) {
+	tmpl, err := macro.Parse(src, macros)
+	if err != nil {
+		return macro.TemplateParam{}, err
+	}
+
+	if len(tmpl.Params) != 1 {
+		return macro.TemplateParam{}, fmt.Errorf("invalid macro parameter: %s", src)
+	}
+
+	return tmpl.Params[0], nil
+}
+
+// evalMacroParam reports whether the "value" passes the "p" parameter's type and functions,
+// the evaluated value is stored to the "store" under the parameter's name.
+func evalMacroParam(p *macro.TemplateParam, value string, store *memstore.Store) bool {
+	if !p.CanEval() {
+		store.Set(p.Name, value)
+		return true
+	}
+
+	if !p.Eval(value, store) {
+		return false
+	}
+
+	if p.TypeEvaluator == nil {
+		// the type does not convert the value, i.e {name:string min(3)}.
+		store.Set(p.Name, value)
+	}
+
+	return true
+}
+
+type hostSegment struct {
+	static string
+	param  *macro.TemplateParam
+}
+
+// splitHost splits the "pattern" by its dots, the dots inside a parameter are ignored.
+func splitHost(pattern string) []string {
+	var (
+		segments []string
+		depth    int
+		start    int
+	)
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, pattern[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(segments, pattern[start:])
+}
+
+// newHostMatcher returns a matcher which reports whether the request's host, without the port,
+// matches the "pattern", i.e "{tenant}.example.com" or "{version:int min(1)}.api.example.com".
+// A segment is either static or a whole macro parameter, its value is stored to the `Context.Params`.
+func newHostMatcher(pattern string, macros macro.Macros) (routeMatcher, error) {
+	var segments []hostSegment
+	for _, s := range splitHost(strings.ToLower(pattern)) {
+		if s == "" {
+			return routeMatcher{}, fmt.Errorf("host pattern: %s: empty segment", pattern)
+		}
+
+		if s[0] != '{' || s[len(s)-1] != '}' {
+			if strings.ContainsAny(s, "{}") {
+				return routeMatcher{}, fmt.Errorf("host pattern: %s: a parameter should be the whole segment", pattern)
+			}
+
+			segments = append(segments, hostSegment{static: s})
+			continue
+		}
+
+		p, err := parseMacroParam(s, macros)
+		if err != nil {
+			return routeMatcher{}, fmt.Errorf("host pattern: %s: %w", pattern, err)
+		}
+
+		segments = append(segments, hostSegment{param: &p})
+	}
+
+	match := func(ctx context.Context) bool {
+		host := ctx.Host()
+		if idx := strings.LastIndexByte(host, ':'); idx != -1 && !strings.Contains(host[idx:], "]") {
+			host = host[:idx] // remove the port.
+		}
+
+		parts := strings.Split(host, ".")
+		if len(parts) != len(segments) {
+			return false
+		}
+
+		// store the values to the context's params only if the whole host matches.
+		var values memstore.Store
+		for i, s := range segments {
+			if s.param == nil {
+				if !strings.EqualFold(parts[i], s.static) {
+					return false
+				}
+				continue
+			}
+
+			if !evalMacroParam(s.param, parts[i], &values) {
+				return false
+			}
+		}
+
+		params := ctx.Params()
+		for _, entry := range values {
+			params.Store.Set(entry.Key, entry.ValueRaw)
+		}
+
+		return true
+	}
+
+	return routeMatcher{src: "Host(" + pattern + ")", match: match}, nil
+}
+
+// newValueMatcher returns a matcher which reports whether the value of a request's header or query
+// passes the "macroExpr", the value should exist.
+// The "macroExpr" is a parameter type and its functions, i.e "int min(1)",
+// or a whole named parameter, i.e "{version:int min(1)}", which stores the value to the `Context.Params` too.
+// An empty "macroExpr" matches any value.
+func newValueMatcher(kind, key, macroExpr string, macros macro.Macros, get func(ctx context.Context, key string) (string, bool)) (routeMatcher, error) {
+	src := macroExpr
+	capture := strings.HasPrefix(src, "{")
+	if !capture {
+		if src == "" {
+			src = "string"
+		}
+		src = "{" + strings.ToLower(kind) + ":" + src + "}"
+	}
+
+	p, err := parseMacroParam(src, macros)
+	if err != nil {
+		return routeMatcher{}, fmt.Errorf("%s matcher: %s: %w", strings.ToLower(kind), key, err)
+	}
+
+	match := func(ctx context.Context) bool {
+		value, ok := get(ctx, key)
+		if !ok {
+			return false
+		}
+
+		if !capture {
+			if !p.CanEval() {
+				return true
+			}
+
+			var discard memstore.Store
+			return p.Eval(value, &discard)
+		}
+
+		var values memstore.Store
+		if !evalMacroParam(&p, value, &values) {
+			return false
+		}
+
+		ctx.Params().Store.Set(p.Name, values.Get(p.Name))
+		return true
+	}
+
+	return routeMatcher{src: kind + "(" + key + ", " + macroExpr + ")", match: match}, nil
+}
+
+func newHeaderMatcher(key, macroExpr string, macros macro.Macros) (routeMatcher, error) {
+	return newValueMatcher("Header", key, macroExpr, macros, func(ctx context.Context, key string) (string, bool) {
+		value := ctx.GetHeader(key)
+		return value, value != ""
+	})
+}
+
+func newQueryMatcher(key, macroExpr string, macros macro.Macros) (routeMatcher, error) {
+	return newValueMatcher("Query", key, macroExpr, macros, func(ctx context.Context, key string) (string, bool) {
+		if !ctx.URLParamExists(key) {
+			return "", false
+		}
+
+		return ctx.URLParam(key), true
+	})
+}
+
+// parseMIMETypes returns the lowercase "mimes" without their parameters,
+// a value may contain more than one, separated by commas.
+func parseMIMETypes(kind string, mimes []string) ([]string, error) {
+	var parsed []string
+	for _, value := range mimes {
+		for _, mime := range strings.Split(value, ",") {
+			if idx := strings.IndexByte(mime, ';'); idx != -1 {
+				mime = mime[:idx]
+			}
+
+			mime = strings.ToLower(strings.TrimSpace(mime))
+			if mime == "" {
+				continue
+			}
+
+			if strings.Count(mime, "/") != 1 {
+				return nil, fmt.Errorf("%s matcher: invalid mime type: %s", strings.ToLower(kind), mime)
+			}
+
+			parsed = append(parsed, mime)
+		}
+	}
+
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("%s matcher: no mime types", strings.ToLower(kind))
+	}
+
+	return parsed, nil
+}
+
+// mimeTypeMatches reports whether the "mime" matches the "pattern",
+// which can be a wildcard, i.e "*/*" or "application/*".
+func mimeTypeMatches(pattern, mime string) bool {
+	if pattern == "*/*" || pattern == mime {
+		return true
+	}
+
+	return strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mime, pattern[:len(pattern)-1])
+}
+
+// newConsumesMatcher returns a matcher which reports whether the request's Content-Type is one of the "mimes",
+// it fires 415 Unsupported Media Type when no route passes.
+// Requests without body and Content-Type pass.
+func newConsumesMatcher(mimes []string) (routeMatcher, error) {
+	parsed, err := parseMIMETypes("Consumes", mimes)
+	if err != nil {
+		return routeMatcher{}, err
+	}
+
+	match := func(ctx context.Context) bool {
+		contentType := ctx.GetContentTypeRequested()
+		if contentType == "" {
+			r := ctx.Request()
+			return r.ContentLength == 0 || (r.ContentLength == -1 && r.Body == http.NoBody)
+		}
+
+		if idx := strings.IndexByte(contentType, ';'); idx != -1 {
+			contentType = contentType[:idx]
+		}
+		contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+		for _, mime := range parsed {
+			if mimeTypeMatches(mime, contentType) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return routeMatcher{
+		src:        "Consumes(" + strings.Join(parsed, ", ") + ")",
+		match:      match,
+		statusCode: http.StatusUnsupportedMediaType,
+	}, nil
+}
+
+// newProducesMatcher returns a matcher which reports whether the client accepts one of the "mimes",
+// based on the `Context.Negotiation().Accept`, it fires 406 Not Acceptable when no route passes.
+// Requests without an Accept header pass.
+func newProducesMatcher(mimes []string) (routeMatcher, error) {
+	parsed, err := parseMIMETypes("Produces", mimes)
+	if err != nil {
+		return routeMatcher{}, err
+	}
+
+	match := func(ctx context.Context) bool {
+		return ctx.Negotiation().Accept.Accepts(parsed...) != ""
+	}
+
+	return routeMatcher{
+		src:        "Produces(" + strings.Join(parsed, ", ") + ")",
+		match:      match,
+		statusCode: http.StatusNotAcceptable,
+		produces:   parsed,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestRouteMatchers(t *testing.T) {
+	app := iris.New()
+
+	tenants := app.Party("/")
+	tenants.Host("{tenant:string regexp(^[a-z]+$)}.example.com")
+	tenants.Get("/", func(ctx iris.Context) {
+		ctx.Writef("tenant: %s", ctx.Params().Get("tenant"))
+	})
+
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("index")
+	})
+
+	// registered after the fallback route, with route matchers.
+	app.Get("/", func(ctx iris.Context) {
+		version, _ := ctx.Params().GetInt("version")
+		ctx.Writef("version: %d", version)
+	}).Header("X-API-Version", "{version:int min(2)}")
+
+	app.Get("/users/{id:uint64}", func(ctx iris.Context) {
+		ctx.Writef("user: %d", ctx.Params().GetUint64Default("id", 0))
+	}).Host("{version:int}.api.example.com").Query("format", "string regexp(^json$)")
+
+	e := httptest.New(t, app)
+
+	e.GET("/").WithHeader("Host", "acme.example.com").Expect().Status(httptest.StatusOK).Body().Equal("tenant: acme")
+	e.GET("/").WithHeader("Host", "acme.example.com:8080").Expect().Status(httptest.StatusOK).Body().Equal("tenant: acme")
+	e.GET("/").WithHeader("Host", "acme1.example.com").Expect().Status(httptest.StatusOK).Body().Equal("index")
+	e.GET("/").WithHeader("Host", "www.acme.example.com").Expect().Status(httptest.StatusOK).Body().Equal("index")
+	e.GET("/").WithHeader("X-API-Version", "2").Expect().Status(httptest.StatusOK).Body().Equal("version: 2")
+	e.GET("/").WithHeader("X-API-Version", "1").Expect().Status(httptest.StatusOK).Body().Equal("index")
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("index")
+
+	e.GET("/users/42").WithHeader("Host", "1.api.example.com").WithQuery("format", "json").
+		Expect().Status(httptest.StatusOK).Body().Equal("user: 42")
+	e.GET("/users/42").WithHeader("Host", "1.api.example.com").WithQuery("format", "xml").
+		Expect().Status(httptest.StatusNotFound)
+	e.GET("/users/42").WithHeader("Host", "v1.api.example.com").WithQuery("format", "json").
+		Expect().Status(httptest.StatusNotFound)
+	e.GET("/users/42").WithQuery("format", "json").Expect().Status(httptest.StatusNotFound)
+}
+
+func TestRouteMatchersInvalid(t *testing.T) {
+	app := iris.New()
+	app.Host("api-{version:int}.example.com")
+	if expected, got := 1, len(app.GetReporter().Errors); expected != got {
+		t.Fatalf("expected api builder's errors length to be: %d but got: %d", expected, got)
+	}
+
+	app = iris.New()
+	app.Get("/", func(ctx iris.Context) {}).Query("q", "nosuchtype")
+	if err := app.Build(); err == nil {
+		t.Fatalf("expected an error on build because of the invalid query matcher")
+	}
+}
+
+func TestRouteConsumesProduces(t *testing.T) {
+	app := iris.New()
+	app.OnErrorCode(iris.StatusUnsupportedMediaType, func(ctx iris.Context) {
+		ctx.WriteString("unsupported: " + ctx.GetContentTypeRequested())
+	})
+
+	users := app.Party("/users")
+	users.Post("/", func(ctx iris.Context) {
+		ctx.WriteString("json")
+	}).Consumes("application/json")
+	users.Post("/", func(ctx iris.Context) {
+		ctx.WriteString("xml")
+	}).Consumes("application/xml", "text/xml")
+
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	articles := app.Party("/articles").Produces("application/json", "application/xml")
+	articles.Get("/", func(ctx iris.Context) {
+		ctx.Negotiate(user{Name: "kataras"})
+	})
+	articles.Get("/", func(ctx iris.Context) {
+		ctx.HTML("<h1>kataras</h1>")
+	}).Produces("text/html")
+
+	// no routes without matchers.
+	e := httptest.New(t, app)
+
+	e.POST("/users").WithHeader("Content-Type", "application/json; charset=utf-8").WithBytes([]byte("{}")).
+		Expect().Status(httptest.StatusOK).Body().Equal("json")
+	e.POST("/users").WithHeader("Content-Type", "text/xml").WithBytes([]byte("<a/>")).
+		Expect().Status(httptest.StatusOK).Body().Equal("xml")
+	e.POST("/users").WithHeader("Content-Type", "text/plain").WithBytes([]byte("a")).
+		Expect().Status(httptest.StatusUnsupportedMediaType).Body().Equal("unsupported: text/plain")
+
+	e.GET("/articles").WithHeader("Accept", "application/xml").
+		Expect().Status(httptest.StatusOK).ContentType("application/xml").Body().Equal("<user><name>kataras</name></user>")
+	e.GET("/articles").WithHeader("Accept", "application/json").
+		Expect().Status(httptest.StatusOK).ContentType("application/json").JSON().Equal(user{Name: "kataras"})
+	e.GET("/articles").WithHeader("Accept", "text/html").
+		Expect().Status(httptest.StatusOK).Body().Equal("<h1>kataras</h1>")
+	e.GET("/articles").WithHeader("Accept", "*/*").
+		Expect().Status(httptest.StatusOK).ContentType("application/json").Header("Vary").Equal("Accept")
+	e.GET("/articles").WithHeader("Accept", "text/plain").
+		Expect().Status(httptest.StatusNotAcceptable)
+	e.POST("/articles").WithHeader("Accept", "text/plain").
+		Expect().Status(httptest.StatusNotFound)
+}
@@ -0,0 +1,139 @@
+package router_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestURLFor(t *testing.T) {
+	app := iris.New()
+	noop := func(ctx iris.Context) {}
+	app.Get("/", noop).Name = "home"
+	app.Get("/users/{id:uint64 min(1)}", noop).Name = "user"
+	app.Get("/users/{id:uint64}/posts/{slug:string regexp(^[a-z-]+$)}", noop).Name = "post"
+	app.Get("/files/{file:path}", noop).Name = "file"
+	app.Party("admin.").Get("/", noop).Name = "admin"
+	app.WildcardSubdomain().Get("/profile", noop).Name = "profile"
+
+	type postParams struct {
+		UserID uint64 `param:"id"`
+		Slug   string
+	}
+	slug := "hello-world"
+
+	tests := []struct {
+		routeName string
+		params    interface{}
+		query     url.Values
+		expected  string
+	}{
+		{"home", nil, nil, "/"},
+		{"home", nil, url.Values{"page": []string{"2"}}, "/?page=2"},
+		{"user", iris.Map{"id": 42}, nil, "/users/42"},
+		{"user", map[string]string{"id": "42"}, url.Values{"tab": []string{"posts"}}, "/users/42?tab=posts"},
+		{"post", postParams{UserID: 42, Slug: slug}, nil, "/users/42/posts/hello-world"},
+		{"post", &postParams{UserID: 42, Slug: slug}, nil, "/users/42/posts/hello-world"},
+		{"post", iris.Map{"id": 42, "slug": &slug}, nil, "/users/42/posts/hello-world"},
+		{"file", iris.Map{"file": "css/main file.css"}, nil, "/files/css/main%20file.css"},
+	}
+
+	for i, tt := range tests {
+		got, err := app.URLFor(tt.routeName, tt.params, tt.query)
+		if err != nil {
+			t.Fatalf("[%d] %s: unexpected error: %v", i, tt.routeName, err)
+		}
+		if got != tt.expected {
+			t.Fatalf("[%d] %s: expected: %s but got: %s", i, tt.routeName, tt.expected, got)
+		}
+	}
+
+	invalid := []struct {
+		routeName string
+		params    interface{}
+	}{
+		{"notfound", nil},
+		{"user", nil},                                    // missing.
+		{"user", iris.Map{"id": 0}},                      // min(1).
+		{"user", iris.Map{"id": "abc"}},                  // uint64.
+		{"user", iris.Map{"id": 1, "other": 2}},          // unknown.
+		{"post", iris.Map{"id": 1, "slug": "Not Valid"}}, // regexp.
+		{"user", 42},                                     // unsupported type.
+		{"admin", nil},                                   // unknown host.
+		{"profile", iris.Map{"subdomain": "kataras"}},    // unknown host.
+	}
+
+	for i, tt := range invalid {
+		if got, err := app.URLFor(tt.routeName, tt.params, nil); err == nil {
+			t.Fatalf("[%d] %s: expected an error but got: %s", i, tt.routeName, got)
+		}
+	}
+
+	// set the virtual host.
+	app.NewHost(&http.Server{Addr: "mydomain.com:443"})
+
+	absolute := []struct {
+		routeName string
+		params    interface{}
+		expected  string
+	}{
+		{"user", iris.Map{"id": 42}, "https://mydomain.com/users/42"},
+		{"admin", nil, "https://admin.mydomain.com/"},
+		{"profile", iris.Map{"subdomain": "kataras"}, "https://kataras.mydomain.com/profile"},
+	}
+
+	for i, tt := range absolute {
+		got, err := app.URLFor(tt.routeName, tt.params, nil)
+		if err != nil {
+			t.Fatalf("[%d] %s: unexpected error: %v", i, tt.routeName, err)
+		}
+		if got != tt.expected {
+			t.Fatalf("[%d] %s: expected: %s but got: %s", i, tt.routeName, tt.expected, got)
+		}
+	}
+
+	if _, err := app.URLFor("profile", nil, nil); err == nil {
+		t.Fatalf("expected an error for the missing wildcard subdomain")
+	}
+}
+
+func TestURLForHandlerAndView(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urlfor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmpl := `{{ urlfor "user" "id" 42 "tab" "posts" }}|{{ urlfor "user" "id" 0 }}`
+	if err = ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(tmpl), os.FileMode(0644)); err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Logger().SetLevel("disable")
+	app.RegisterView(iris.HTML(dir, ".html"))
+	app.Get("/users/{id:uint64 min(1)}", func(ctx iris.Context) {}).Name = "user"
+	app.Get("/view", func(ctx iris.Context) {
+		ctx.View("index.html")
+	})
+	app.Get("/handler", func(ctx iris.Context) {
+		u, err := ctx.Application().URLFor("user", iris.Map{"id": 7}, nil)
+		if err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.WriteString(u)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/handler").Expect().Status(httptest.StatusOK).Body().Equal("/users/7")
+	e.GET("/view").Expect().Status(httptest.StatusOK).Body().Equal("/users/42?tab=posts|")
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"sync"
+	"sync/atomic"
 
 	"github.com/kataras/iris/v12/context"
 
@@ -15,17 +16,26 @@ import (
 // Router is responsible to build the received request handler and run it
 // to serve requests, based on the received context.Pool.
 //
-// User can refresh the router with `RefreshRouter` whenever a route's field is changed by him.
+// User can refresh the router with `RefreshRouter` whenever a route's field is changed by him
+// or routes are registered or removed at serve-time.
 type Router struct {
-	mu sync.Mutex // for Downgrade, WrapRouter & BuildRouter,
+	mu sync.Mutex // for Downgrade, WrapRouter, BuildRouter & RefreshRouter,
 	// not indeed but we don't to risk its usage by third-parties.
-	requestHandler RequestHandler   // build-accessible, can be changed to define a custom router or proxy, used on RefreshRouter too.
-	mainHandler    http.HandlerFunc // init-accessible
-	wrapperFunc    func(http.ResponseWriter, *http.Request, http.HandlerFunc)
+	wrapperFunc func(http.ResponseWriter, *http.Request, http.HandlerFunc)
 
 	cPool          *context.Pool // used on RefreshRouter
 	routesProvider RoutesProvider
 
+	// state holds the *routerState which serves the requests,
+	// it's replaced atomically on `BuildRouter/RefreshRouter` and `Downgrade`,
+	// so the in-flight requests are served by the previous one.
+	state atomic.Value
+}
+
+type routerState struct {
+	requestHandler RequestHandler   // build-accessible, can be changed to define a custom router or proxy, used on RefreshRouter too.
+	mainHandler    http.HandlerFunc // init-accessible
+
 	// key = subdomain
 	// value = closest of static routes, filled on `BuildRouter/RefreshRouter`.
 	closestPaths map[string]*closestmatch.ClosestMatch
@@ -36,10 +46,31 @@ func NewRouter() *Router {
 	return &Router{}
 }
 
+func (router *Router) load() *routerState {
+	if s, ok := router.state.Load().(*routerState); ok {
+		return s
+	}
+
+	return new(routerState)
+}
+
 // RefreshRouter re-builds the router. Should be called when a route's state
-// changed (i.e Method changed at serve-time).
+// changed (i.e Method changed at serve-time) or when routes are registered,
+// replaced (see `Party.SetRegisterRule`) or removed (see `Party.RemoveRoute`) at serve-time.
+//
+// It is safe to call it while serving: the default request handler is built from scratch
+// and replaces the current one atomically, the in-flight requests finish on the previous one.
+// Custom request handlers are re-built in place.
 func (router *Router) RefreshRouter() error {
-	return router.BuildRouter(router.cPool, router.requestHandler, router.routesProvider, true)
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	requestHandler := router.load().requestHandler
+	if _, ok := requestHandler.(*routerHandler); ok {
+		requestHandler = NewDefaultHandler()
+	}
+
+	return router.buildRouter(router.cPool, requestHandler, router.routesProvider, true)
 }
 
 // ErrNotRouteAdder throws on `AddRouteUnsafe` when a registered `RequestHandler`
@@ -49,9 +80,9 @@ var ErrNotRouteAdder = errors.New("request handler does not implement AddRoute m
 // AddRouteUnsafe adds a route directly to the router's request handler.
 // Works before or after Build state.
 // Mainly used for internal cases like `iris.WithSitemap`.
-// Do NOT use it on serve-time.
+// Do NOT use it on serve-time, register the route and call `RefreshRouter` instead.
 func (router *Router) AddRouteUnsafe(r *Route) error {
-	if h := router.requestHandler; h != nil {
+	if h := router.load().requestHandler; h != nil {
 		if v, ok := h.(interface {
 			AddRoute(*Route) error
 		}); ok {
@@ -66,11 +97,12 @@ func (router *Router) AddRouteUnsafe(r *Route) error {
 //
 // Order may change.
 func (router *Router) FindClosestPaths(subdomain, searchPath string, n int) []string {
-	if router.closestPaths == nil {
+	closestPaths := router.load().closestPaths
+	if closestPaths == nil {
 		return nil
 	}
 
-	cm, ok := router.closestPaths[subdomain]
+	cm, ok := closestPaths[subdomain]
 	if !ok {
 		return nil
 	}
@@ -92,6 +124,13 @@ func (router *Router) FindClosestPaths(subdomain, searchPath string, n int) []st
 //
 // Use of RefreshRouter to re-build the router if needed.
 func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHandler, routesProvider RoutesProvider, force bool) error {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	return router.buildRouter(cPool, requestHandler, routesProvider, force)
+}
+
+func (router *Router) buildRouter(cPool *context.Pool, requestHandler RequestHandler, routesProvider RoutesProvider, force bool) error {
 	if requestHandler == nil {
 		return errors.New("router: request handler is nil")
 	}
@@ -105,21 +144,20 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 		return err
 	}
 
-	router.mu.Lock()
-	defer router.mu.Unlock()
+	state := &routerState{requestHandler: router.load().requestHandler}
 
 	// store these for RefreshRouter's needs.
 	if force {
 		router.cPool = cPool
-		router.requestHandler = requestHandler
+		state.requestHandler = requestHandler
 		router.routesProvider = routesProvider
 	} else {
 		if router.cPool == nil {
 			router.cPool = cPool
 		}
 
-		if router.requestHandler == nil {
-			router.requestHandler = requestHandler
+		if state.requestHandler == nil {
+			state.requestHandler = requestHandler
 		}
 
 		if router.routesProvider == nil && routesProvider != nil {
@@ -128,16 +166,16 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 	}
 
 	// the important
-	router.mainHandler = func(w http.ResponseWriter, r *http.Request) {
+	state.mainHandler = func(w http.ResponseWriter, r *http.Request) {
 		ctx := cPool.Acquire(w, r)
 		// Note: we can't get all r.Context().Value key-value pairs
 		// and save them to ctx.values.
-		router.requestHandler.HandleRequest(ctx)
+		state.requestHandler.HandleRequest(ctx)
 		cPool.Release(ctx)
 	}
 
 	if router.wrapperFunc != nil { // if wrapper used then attach that as the router service
-		router.mainHandler = NewWrapper(router.wrapperFunc, router.mainHandler).ServeHTTP
+		state.mainHandler = NewWrapper(router.wrapperFunc, state.mainHandler).ServeHTTP
 	}
 
 	// build closest.
@@ -150,11 +188,12 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 		subdomainPaths[r.Subdomain] = append(subdomainPaths[r.Subdomain], r.Path)
 	}
 
-	router.closestPaths = make(map[string]*closestmatch.ClosestMatch)
+	state.closestPaths = make(map[string]*closestmatch.ClosestMatch)
 	for subdomain, paths := range subdomainPaths {
-		router.closestPaths[subdomain] = closestmatch.New(paths, []int{3, 4, 6})
+		state.closestPaths[subdomain] = closestmatch.New(paths, []int{3, 4, 6})
 	}
 
+	router.state.Store(state)
 	return nil
 }
 
@@ -169,13 +208,16 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 // Downgrade is thread-safe.
 func (router *Router) Downgrade(newMainHandler http.HandlerFunc) {
 	router.mu.Lock()
-	router.mainHandler = newMainHandler
+	state := *router.load()
+	state.mainHandler = newMainHandler
+	router.state.Store(&state)
 	router.mu.Unlock()
 }
 
 // Downgraded returns true if this router is downgraded.
 func (router *Router) Downgraded() bool {
-	return router.mainHandler != nil && router.requestHandler == nil
+	state := router.load()
+	return state.mainHandler != nil && state.requestHandler == nil
 }
 
 // WrapperFunc is used as an expected input parameter signature
@@ -186,7 +228,8 @@ type WrapperFunc func(w http.ResponseWriter, r *http.Request, firstNextIsTheRout
 
 // WrapRouter adds a wrapper on the top of the main router.
 // Usually it's useful for third-party middleware
-// when need to wrap the entire application with a middleware like CORS.
+// when need to wrap the entire application with a middleware like CORS,
+// see the built-in "middleware/cors" package, which doesn't need a wrapper, too.
 //
 // Developers can add more than one wrappers,
 // those wrappers' execution comes from last to first.
@@ -220,17 +263,17 @@ func (router *Router) WrapRouter(wrapperFunc WrapperFunc) {
 
 // ServeHTTPC serves the raw context, useful if we have already a context, it by-pass the wrapper.
 func (router *Router) ServeHTTPC(ctx context.Context) {
-	router.requestHandler.HandleRequest(ctx)
+	router.load().requestHandler.HandleRequest(ctx)
 }
 
 func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	router.mainHandler(w, r)
+	router.load().mainHandler(w, r)
 }
 
 // RouteExists reports whether a particular route exists
 // It will search from the current subdomain of context's host, if not inside the root domain.
 func (router *Router) RouteExists(ctx context.Context, method, path string) bool {
-	return router.requestHandler.RouteExists(ctx, method, path)
+	return router.load().requestHandler.RouteExists(ctx, method, path)
 }
 
 type wrapper struct {
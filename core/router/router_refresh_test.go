@@ -0,0 +1,175 @@
+package router_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestRefreshRouterAddRemove(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("index")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/plugins/a").Expect().Status(httptest.StatusNotFound)
+
+	plugin := app.Party("/plugins/a")
+	plugin.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("plugin a")
+	})
+	plugin.Get("/users/{id:uint64}", func(ctx iris.Context) {
+		ctx.Writef("plugin a user %d", ctx.Params().GetUint64Default("id", 0))
+	})
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+
+	e.GET("/plugins/a").Expect().Status(httptest.StatusOK).Body().Equal("plugin a")
+	e.GET("/plugins/a/users/42").Expect().Status(httptest.StatusOK).Body().Equal("plugin a user 42")
+
+	// replace.
+	plugin.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("plugin a v2")
+	})
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/plugins/a").Expect().Status(httptest.StatusOK).Body().Equal("plugin a v2")
+
+	// remove a single route.
+	if !app.RemoveRoute("GET/plugins/a/users/{id:uint64}") {
+		t.Fatalf("expected route to be removed")
+	}
+	if app.RemoveRoute("GET/plugins/a/users/{id:uint64}") {
+		t.Fatalf("expected route to be already removed")
+	}
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/plugins/a/users/42").Expect().Status(httptest.StatusNotFound)
+	e.GET("/plugins/a").Expect().Status(httptest.StatusOK).Body().Equal("plugin a v2")
+
+	// remove the whole party.
+	if expected, got := 1, plugin.RemoveRoutes("/"); expected != got {
+		t.Fatalf("expected %d removed routes but got %d", expected, got)
+	}
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/plugins/a").Expect().Status(httptest.StatusNotFound)
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("index")
+
+	if expected, got := 1, len(app.GetRoutes()); expected != got {
+		t.Fatalf("expected %d registered routes but got %d", expected, got)
+	}
+}
+
+func TestRefreshRouterRemoveTopLink(t *testing.T) {
+	app := iris.New()
+	app.Get("/{id:int}", func(ctx iris.Context) {
+		ctx.WriteString("int")
+	})
+	app.Get("/{name:string regexp(^[a-z]+$)}", func(ctx iris.Context) {
+		ctx.WriteString("string")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/42").Expect().Status(httptest.StatusOK).Body().Equal("int")
+	e.GET("/abc").Expect().Status(httptest.StatusOK).Body().Equal("string")
+
+	if !app.RemoveRoute("GET/{id:int}") {
+		t.Fatalf("expected route to be removed")
+	}
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+
+	e.GET("/42").Expect().Status(httptest.StatusNotFound)
+	e.GET("/abc").Expect().Status(httptest.StatusOK).Body().Equal("string")
+}
+
+func TestRefreshRouterWhileServing(t *testing.T) {
+	app := iris.New()
+	app.Get("/stable", func(ctx iris.Context) {
+		ctx.WriteString("stable")
+	})
+
+	e := httptest.New(t, app)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				e.GET("/stable").Expect().Status(httptest.StatusOK).Body().Equal("stable")
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("/dynamic/%d", i)
+		app.Get(path, func(ctx iris.Context) {
+			ctx.WriteString(path)
+		})
+		if i > 0 {
+			app.RemoveRoute(fmt.Sprintf("GET/dynamic/%d", i-1))
+		}
+
+		if err := app.RefreshRouter(); err != nil {
+			t.Fatal(err)
+		}
+
+		e.GET(path).Expect().Status(httptest.StatusOK).Body().Equal(path)
+	}
+
+	wg.Wait()
+
+	e.GET("/dynamic/18").Expect().Status(httptest.StatusNotFound)
+	e.GET("/dynamic/19").Expect().Status(httptest.StatusOK)
+}
+
+func TestRefreshRouterWhileServingLinkedRoutes(t *testing.T) {
+	app := iris.New()
+	app.Get("/items/{id:int}", func(ctx iris.Context) {
+		ctx.WriteString("int")
+	})
+	app.Get("/items/{name:string regexp(^[a-z]+$)}", func(ctx iris.Context) {
+		ctx.WriteString("string")
+	})
+	app.Get("/versioned", func(ctx iris.Context) {
+		ctx.WriteString("v2")
+	}).Header("X-Version", "int min(2)")
+	app.Get("/versioned", func(ctx iris.Context) {
+		ctx.WriteString("v1")
+	})
+
+	e := httptest.New(t, app)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				e.GET("/items/42").Expect().Status(httptest.StatusOK).Body().Equal("int")
+				e.GET("/items/abc").Expect().Status(httptest.StatusOK).Body().Equal("string")
+				e.GET("/versioned").WithHeader("X-Version", "2").Expect().Status(httptest.StatusOK).Body().Equal("v2")
+				e.GET("/versioned").Expect().Status(httptest.StatusOK).Body().Equal("v1")
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := app.RefreshRouter(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wg.Wait()
+}
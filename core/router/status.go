@@ -2,9 +2,11 @@ package router
 
 import (
 	"net/http" // just for status codes
+	"strings"
 	"sync"
 
 	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/netutil"
 )
 
 func statusCodeSuccessful(statusCode int) bool {
@@ -15,8 +17,14 @@ func statusCodeSuccessful(statusCode int) bool {
 // of the list of all http error code handlers.
 type ErrorCodeHandler struct {
 	StatusCode int
-	Handlers   context.Handlers
-	mu         sync.Mutex
+	// Subdomain and Path are the ones of the Party which registered this handler,
+	// both are empty for the root Party's handlers which fire for any request.
+	Subdomain string
+	Path      string
+	Handlers  context.Handlers
+	mu        sync.Mutex
+	// builtin reports whether the handler is registered by the framework, not by the caller.
+	builtin bool
 }
 
 // Fire executes the specific an error http error status.
@@ -31,8 +39,8 @@ func (ch *ErrorCodeHandler) Fire(ctx context.Context) {
 		// reset if previous content and it's recorder, keep the status code.
 		w.ClearHeaders()
 		w.ResetBody()
-	} else if w, ok := ctx.ResponseWriter().(*context.GzipResponseWriter); ok {
-		// reset and disable the gzip in order to be an expected form of http error result
+	} else if w, ok := ctx.ResponseWriter().(*context.CompressResponseWriter); ok {
+		// reset and disable the compression in order to be an expected form of http error result
 		w.ResetBody()
 		w.Disable()
 	} else {
@@ -62,20 +70,82 @@ func (ch *ErrorCodeHandler) Fire(ctx context.Context) {
 	// in order to:
 	// ignore the route's after-handlers, if any.
 	ctx.HandlerIndex(0)
-	ctx.Do(ch.Handlers)
+	ctx.Do(ch.getHandlers())
+}
+
+func (ch *ErrorCodeHandler) getHandlers() context.Handlers {
+	ch.mu.Lock()
+	handlers := ch.Handlers
+	ch.mu.Unlock()
+	return handlers
 }
 
 func (ch *ErrorCodeHandler) updateHandlers(handlers context.Handlers) {
 	ch.mu.Lock()
 	ch.Handlers = handlers
+	ch.builtin = false
 	ch.mu.Unlock()
 }
 
+// matchSubdomain reports the score of the request's host match against the handler's subdomain,
+// like the router does: 2 for exact, 1 for wildcard, 0 for the root domain's handlers which
+// match any host and -1 if it does not match.
+func (ch *ErrorCodeHandler) matchSubdomain(ctx context.Context) int {
+	if ch.Subdomain == "" {
+		return 0
+	}
+
+	host := ctx.Host()
+	if netutil.IsLoopbackSubdomain(host) {
+		return -1
+	}
+
+	if ch.Subdomain == SubdomainWildcardIndicator {
+		if host == ctx.Application().ConfigurationReadOnly().GetVHost() || strings.IndexByte(host, '.') <= 0 {
+			return -1
+		}
+		return 1
+	}
+
+	if strings.HasPrefix(host, ch.Subdomain) {
+		return 2
+	}
+
+	return -1
+}
+
+// matchPath reports whether the "path" is the handler's path or under it.
+// Dynamic segments of the handler's path, i.e "{id:int}", match any segment.
+func (ch *ErrorCodeHandler) matchPath(path string) bool {
+	if ch.Path == "" {
+		return true
+	}
+
+	if !strings.Contains(ch.Path, "{") {
+		return path == ch.Path || strings.HasPrefix(path, ch.Path+"/")
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range strings.Split(strings.Trim(ch.Path, "/"), "/") {
+		if i >= len(segments) {
+			return false
+		}
+
+		if segment != segments[i] && !strings.HasPrefix(segment, "{") {
+			return false
+		}
+	}
+
+	return true
+}
+
 // ErrorCodeHandlers contains the http error code handlers.
 // User of this struct can register, get
 // a status code handler based on a status code or
 // fire based on a receiver context.
+// It is safe for concurrent use, handlers can be fired while others are registered.
 type ErrorCodeHandlers struct {
+	mu       sync.RWMutex
 	handlers []*ErrorCodeHandler
 }
 
@@ -90,7 +160,7 @@ func defaultErrorCodeHandlers() *ErrorCodeHandlers {
 		http.StatusMethodNotAllowed,
 		http.StatusInternalServerError,
 	} {
-		chs.Register(statusCode, statusText(statusCode))
+		chs.Register(statusCode, statusText(statusCode)).builtin = true
 	}
 
 	return chs
@@ -102,11 +172,24 @@ func statusText(statusCode int) context.Handler {
 	}
 }
 
-// Get returns an http error handler based on the "statusCode".
+// Get returns the root Party's http error handler based on the "statusCode".
 // If not found it returns nil.
 func (s *ErrorCodeHandlers) Get(statusCode int) *ErrorCodeHandler {
+	return s.GetFor("", "", statusCode)
+}
+
+// GetFor returns the http error handler of a Party, based on its "subdomain" and "path",
+// and the "statusCode". If not found it returns nil.
+func (s *ErrorCodeHandlers) GetFor(subdomain, path string, statusCode int) *ErrorCodeHandler {
+	s.mu.RLock()
+	h := s.getFor(subdomain, strings.TrimRight(path, "/"), statusCode)
+	s.mu.RUnlock()
+	return h
+}
+
+func (s *ErrorCodeHandlers) getFor(subdomain, path string, statusCode int) *ErrorCodeHandler {
 	for i, n := 0, len(s.handlers); i < n; i++ {
-		if h := s.handlers[i]; h.StatusCode == statusCode {
+		if h := s.handlers[i]; h.StatusCode == statusCode && h.Subdomain == subdomain && h.Path == path {
 			return h
 		}
 	}
@@ -114,22 +197,38 @@ func (s *ErrorCodeHandlers) Get(statusCode int) *ErrorCodeHandler {
 }
 
 // Register registers an error http status code
-// based on the "statusCode" < 200 || >= 400 (`context.StatusCodeNotSuccessful`).
+// based on the "statusCode" < 200 || >= 400 (`context.StatusCodeNotSuccessful`)
+// for the root Party.
 // The handler is being wrapepd by a generic
 // handler which will try to reset
 // the body if recorder was enabled
 // and/or disable the gzip if gzip response recorder
 // was active.
 func (s *ErrorCodeHandlers) Register(statusCode int, handlers ...context.Handler) *ErrorCodeHandler {
+	return s.RegisterFor("", "", statusCode, handlers...)
+}
+
+// RegisterFor same as `Register` but it registers the error handler for a Party,
+// based on its "subdomain" and "path", i.e "admin." and "/users".
+// The handler fires for the requests under that subdomain and path,
+// if a more specific Party does not handle the "statusCode".
+func (s *ErrorCodeHandlers) RegisterFor(subdomain, path string, statusCode int, handlers ...context.Handler) *ErrorCodeHandler {
 	if statusCodeSuccessful(statusCode) {
 		return nil
 	}
 
-	h := s.Get(statusCode)
+	path = strings.TrimRight(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.getFor(subdomain, path, statusCode)
 	if h == nil {
 		// create new and add it
 		ch := &ErrorCodeHandler{
 			StatusCode: statusCode,
+			Subdomain:  subdomain,
+			Path:       path,
 			Handlers:   handlers,
 		}
 
@@ -145,17 +244,59 @@ func (s *ErrorCodeHandlers) Register(statusCode int, handlers ...context.Handler
 
 // Fire executes an error http status code handler
 // based on the context's status code.
+// The handler of the most specific Party, by the request's subdomain and path, is executed,
+// the parent Parties' handlers are the fallbacks.
 //
 // If a handler is not already registered,
-// then it creates & registers a new trivial handler on the-fly.
+// then it creates & registers a new trivial handler on-the-fly.
 func (s *ErrorCodeHandlers) Fire(ctx context.Context) {
 	statusCode := ctx.GetStatusCode()
 	if statusCodeSuccessful(statusCode) {
 		return
 	}
-	ch := s.Get(statusCode)
+
+	ch := s.match(ctx, statusCode)
 	if ch == nil {
 		ch = s.Register(statusCode, statusText(statusCode))
 	}
 	ch.Fire(ctx)
 }
+
+// list returns a copy of the registered handlers.
+func (s *ErrorCodeHandlers) list() []*ErrorCodeHandler {
+	s.mu.RLock()
+	handlers := make([]*ErrorCodeHandler, len(s.handlers))
+	copy(handlers, s.handlers)
+	s.mu.RUnlock()
+	return handlers
+}
+
+func (s *ErrorCodeHandlers) match(ctx context.Context, statusCode int) *ErrorCodeHandler {
+	path := ctx.Request().URL.Path
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		match          *ErrorCodeHandler
+		subdomainScore = -1
+	)
+
+	for i, n := 0, len(s.handlers); i < n; i++ {
+		h := s.handlers[i]
+		if h.StatusCode != statusCode {
+			continue
+		}
+
+		score := h.matchSubdomain(ctx)
+		if score < 0 || !h.matchPath(path) {
+			continue
+		}
+
+		if score > subdomainScore || (score == subdomainScore && len(h.Path) > len(match.Path)) {
+			match, subdomainScore = h, score
+		}
+	}
+
+	return match
+}
@@ -70,3 +70,55 @@ func checkAndClearBuf(t *testing.T, buff *bytes.Buffer, expected string) {
 
 	buff.Reset()
 }
+
+func TestMethodNotAllowedAllowHeader(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithFireMethodNotAllowed)
+
+	h := func(ctx context.Context) {
+		ctx.WriteString(ctx.Method())
+	}
+	app.Get("/resource/{id:int}", h)
+	app.Put("/resource/{id:int}", h)
+	app.Delete("/resource/{id:int}", h)
+	// offline routes are not allowed methods.
+	app.Get("/offline", h)
+	app.None("/offline", h)
+	// the searches of the allowed methods do not fill the request's parameters.
+	app.OnErrorCode(iris.StatusMethodNotAllowed, func(ctx context.Context) {
+		ctx.Writef("%d", ctx.Params().Len())
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/resource/42").Expect().Status(iris.StatusMethodNotAllowed).
+		Header("Allow").Equal("GET, PUT, DELETE")
+	e.PATCH("/resource/42").Expect().Status(iris.StatusMethodNotAllowed).
+		Body().Equal("0")
+	e.OPTIONS("/resource/42").Expect().Status(iris.StatusMethodNotAllowed).
+		Header("Allow").Equal("GET, PUT, DELETE")
+	e.POST("/notfound").Expect().Status(iris.StatusNotFound).
+		Header("Allow").Empty()
+	e.POST("/offline").Expect().Status(iris.StatusMethodNotAllowed).
+		Header("Allow").Equal("GET")
+}
+
+func TestOptionsHandling(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithOptionsHandling)
+
+	h := func(ctx context.Context) {
+		ctx.WriteString(ctx.Method())
+	}
+	app.Get("/resource", h)
+	app.Post("/resource", h)
+	app.Get("/custom", h)
+	app.Options("/custom", h)
+
+	e := httptest.New(t, app)
+	e.OPTIONS("/resource").Expect().Status(iris.StatusNoContent).
+		Header("Allow").Equal("GET, POST, OPTIONS")
+	e.OPTIONS("/custom").Expect().Status(iris.StatusOK).Body().Equal(iris.MethodOptions)
+	e.OPTIONS("/notfound").Expect().Status(iris.StatusNotFound)
+	// FireMethodNotAllowed is not enabled.
+	e.PUT("/resource").Expect().Status(iris.StatusNotFound)
+}
@@ -32,6 +32,7 @@ type trieNode struct {
 	// insert data.
 	Handlers  context.Handlers
 	RouteName string
+	rules     PathRules
 }
 
 func newTrieNode() *trieNode {
@@ -51,6 +52,33 @@ func (tn *trieNode) getChild(s string) *trieNode {
 	return tn.children[s]
 }
 
+// getChildFold returns the child of the "s" static segment,
+// if "fold" is true and not found then it's matched case-insensitively.
+// When more than one children match case-insensitively, i.e "/Users" and "/users",
+// the one with the lowest key, by byte order, is returned, so the match does not depend on the map's order.
+func (tn *trieNode) getChildFold(s string, fold bool) *trieNode {
+	if child := tn.getChild(s); child != nil || !fold {
+		return child
+	}
+
+	var (
+		match    *trieNode
+		matchKey string
+	)
+
+	for key, child := range tn.children {
+		if key == ParamStart || key == WildcardParamStart || !strings.EqualFold(key, s) {
+			continue
+		}
+
+		if match == nil || key < matchKey {
+			match, matchKey = child, key
+		}
+	}
+
+	return match
+}
+
 func (tn *trieNode) addChild(s string, n *trieNode) {
 	if tn.children == nil {
 		tn.children = make(map[string]*trieNode)
@@ -108,7 +136,7 @@ func slowPathSplit(path string) []string {
 	return strings.Split(path, pathSep)[1:]
 }
 
-func (tr *trie) insert(path, routeName string, handlers context.Handlers) {
+func (tr *trie) insert(path, routeName string, handlers context.Handlers, rules PathRules) {
 	input := slowPathSplit(path)
 
 	n := tr.root
@@ -119,6 +147,15 @@ func (tr *trie) insert(path, routeName string, handlers context.Handlers) {
 	var paramKeys []string
 
 	for _, s := range input {
+		if s == "" { // the trailing slash of a path, i.e "/users/".
+			if !n.hasChild(s) {
+				n.addChild(s, newTrieNode())
+			}
+
+			n = n.getChild(s)
+			continue
+		}
+
 		c := s[0]
 
 		if isParam, isWildcard := c == ParamStart[0], c == WildcardParamStart[0]; isParam || isWildcard {
@@ -150,6 +187,7 @@ func (tr *trie) insert(path, routeName string, handlers context.Handlers) {
 
 	n.RouteName = routeName
 	n.Handlers = handlers
+	n.rules = rules
 	n.paramKeys = paramKeys
 	n.key = path
 	n.end = true
@@ -166,6 +204,11 @@ func (tr *trie) insert(path, routeName string, handlers context.Handlers) {
 }
 
 func (tr *trie) search(q string, params *context.RequestParams) *trieNode {
+	return tr.find(q, params, false)
+}
+
+// find is like `search` but if "fold" is true then the static segments are matched case-insensitively.
+func (tr *trie) find(q string, params *context.RequestParams, fold bool) *trieNode {
 	end := len(q)
 
 	if end == 0 || (end == 1 && q[0] == pathSepB) {
@@ -187,7 +230,7 @@ func (tr *trie) search(q string, params *context.RequestParams) *trieNode {
 
 	for {
 		if i == end || q[i] == pathSepB {
-			if child := n.getChild(q[start:i]); child != nil {
+			if child := n.getChildFold(q[start:i], fold); child != nil {
 				n = child
 			} else if n.childNamedParameter {
 				n = n.getChild(ParamStart)
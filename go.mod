@@ -7,6 +7,7 @@ require (
 	github.com/CloudyKit/jet/v3 v3.0.0
 	github.com/Joker/jade v1.0.1-0.20190614124447-d475f43051e7
 	github.com/Shopify/goreferrer v0.0.0-20181106222321-ec9c9a553398
+	github.com/andybalholm/brotli v1.0.2
 	github.com/aymerick/raymond v2.0.3-0.20180322193309-b565731e1464+incompatible
 	github.com/dgraph-io/badger v1.6.0
 	github.com/eknkc/amber v0.0.0-20171010120322-cdade1c07385
@@ -24,6 +25,7 @@ require (
 	github.com/kataras/neffos v0.0.12
 	github.com/kataras/sitemap v0.0.5
 	github.com/klauspost/compress v1.9.7
+	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/mediocregopher/radix/v3 v3.4.2
 	github.com/microcosm-cc/bluemonday v1.0.2
 	github.com/ryanuber/columnize v2.1.0+incompatible
@@ -63,6 +63,22 @@ type (
 	//
 	// It is an alias of the `context#ProblemOptions` type.
 	ProblemOptions = context.ProblemOptions
+	// Validator is the validator for the request values
+	// filled by the `Context.ReadJSON`, `ReadXML`, `ReadYAML`, `ReadForm` and `ReadQuery`.
+	// See `Application.Validator` field.
+	//
+	// It is an alias of the `context#Validator` type.
+	Validator = context.Validator
+	// ValidationErrors is a list of field-level validation failures.
+	// See `AsValidationErrors` too.
+	//
+	// It is an alias of the `context#ValidationErrors` type.
+	ValidationErrors = context.ValidationErrors
+	// MultipartOptions holds the limits of a streaming multipart reader.
+	// See `Context.MultipartReader` method for more.
+	//
+	// It is an alias of the `context#MultipartOptions` type.
+	MultipartOptions = context.MultipartOptions
 	// JSON the optional settings for JSON renderer.
 	//
 	// It is an alias of the `context#JSON` type.
@@ -100,6 +116,16 @@ type (
 	//
 	// See `ExecutionRules` and `core/router/Party#SetExecutionRules` for more.
 	ExecutionOptions = router.ExecutionOptions
+	// PathRules are the request path matching policies of the routes of a Party and its children:
+	// trailing slash, case-insensitive and clean path matching.
+	// Usage:
+	// Party#SetPathRules(PathRules {
+	//   TrailingSlash: TrailingSlashStrict,
+	//   CaseInsensitive: true,
+	// })
+	//
+	// See `core/router/Party#SetPathRules` for more.
+	PathRules = router.PathRules
 
 	// CookieOption is the type of function that is accepted on
 	// context's methods like `SetCookieKV`, `RemoveCookie` and `SetCookie`
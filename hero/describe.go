@@ -0,0 +1,139 @@
+package hero
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/router"
+	"github.com/kataras/iris/v12/hero/di"
+)
+
+// BodyError is the error of a `Body` dependency which failed to read the request body.
+type BodyError struct {
+	Err error
+}
+
+// Error returns the error message of the request body read failure.
+func (e *BodyError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the request body read failure.
+func (e *BodyError) Unwrap() error {
+	return e.Err
+}
+
+var bodyErrTyp = reflect.TypeOf((*BodyError)(nil))
+
+// Body returns a dependency which binds the JSON request body to a new value
+// of the "v"'s type (i.e `User{}` or `new(User)`), a failure to read it is dispatched
+// as a `*BodyError` with the `DefaultErrStatusCode`. For the `Describe` of the same Hero,
+// or MVC Application, that type is a request body, the rest of the inputs are just dependencies.
+// See `Describe` too.
+func Body(v interface{}) interface{} {
+	typ := reflect.TypeOf(v)
+	fnTyp := reflect.FuncOf([]reflect.Type{contextTyp}, []reflect.Type{typ, bodyErrTyp}, false)
+
+	return reflect.MakeFunc(fnTyp, func(in []reflect.Value) []reflect.Value {
+		ctx := in[0].Interface().(context.Context)
+
+		ptr := reflect.New(typ)
+		if typ.Kind() == reflect.Ptr {
+			ptr.Elem().Set(reflect.New(typ.Elem()))
+			ptr = ptr.Elem()
+		}
+
+		errValue := reflect.Zero(bodyErrTyp)
+		if err := ctx.ReadJSON(ptr.Interface()); err != nil {
+			errValue = reflect.ValueOf(&BodyError{Err: err})
+		}
+
+		if typ.Kind() == reflect.Ptr {
+			return []reflect.Value{ptr, errValue}
+		}
+		return []reflect.Value{ptr.Elem(), errValue}
+	}).Interface()
+}
+
+// isBodyType reports whether the "typ" is the output of a `Body` dependency of the "values".
+func isBodyType(values di.Values, typ reflect.Type) bool {
+	for _, v := range values {
+		if fnTyp := v.Type(); fnTyp.Kind() == reflect.Func && fnTyp.NumOut() == 2 &&
+			fnTyp.Out(1) == bodyErrTyp && fnTyp.Out(0) == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+var resultTyp = reflect.TypeOf((*Result)(nil)).Elem()
+
+// Describe same as `Hero#Describe` but it uses the default hero's dependencies,
+// the ones registered through the package-level `Register`.
+func Describe(route *router.Route, fn interface{}) *router.Route {
+	return def.Describe(route, fn)
+}
+
+// Describe sets the request body and the 200 OK response of the "route", if not set already,
+// to the input and output types of the "fn" hero function, see the "openapi" package.
+// The first input of a type registered through a `Body` dependency of this Hero describes the request body
+// and the first struct, slice or map output, except the `Result` ones (i.e the `Response`), describes the response.
+//
+// Usage:
+// h := hero.New().Register(hero.Body(User{}), userService)
+// h.Describe(app.Post("/users", h.Handler(createUser)), createUser)
+func (h *Hero) Describe(route *router.Route, fn interface{}) *router.Route {
+	return DescribeWith(route, fn, h.values)
+}
+
+// DescribeWith same as `Describe` but it looks for the `Body` dependencies on the given "dependencies",
+// i.e the ones of an MVC Controller.
+func DescribeWith(route *router.Route, fn interface{}, dependencies di.Values) *router.Route {
+	if route == nil || fn == nil {
+		return route
+	}
+
+	typ := reflect.TypeOf(fn)
+	if typ.Kind() != reflect.Func {
+		return route
+	}
+
+	if route.RequestBody == nil {
+		for i := 0; i < typ.NumIn(); i++ {
+			if in := typ.In(i); isBodyType(dependencies, in) {
+				route.SetRequestBody(reflect.Zero(in).Interface())
+				break
+			}
+		}
+	}
+
+	if _, ok := route.Responses[http.StatusOK]; !ok {
+		for i := 0; i < typ.NumOut(); i++ {
+			out := typ.Out(i)
+			switch indirectKind(out) {
+			case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+				if !isResultType(out) {
+					route.SetResponse(http.StatusOK, reflect.Zero(out).Interface())
+					return route
+				}
+			}
+		}
+	}
+
+	return route
+}
+
+func indirectKind(typ reflect.Type) reflect.Kind {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ.Kind()
+}
+
+// isResultType reports whether the "typ" dispatches itself, i.e the `Response` and the `View`.
+func isResultType(typ reflect.Type) bool {
+	return typ.Implements(resultTyp) || (typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(resultTyp))
+}
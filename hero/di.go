@@ -3,6 +3,7 @@ package hero
 import (
 	"reflect"
 
+	"github.com/kataras/iris/v12/context"
 	"github.com/kataras/iris/v12/hero/di"
 )
 
@@ -40,4 +41,11 @@ func init() {
 		// or first argument is context.Context and second argument is a variadic, which is ignored (i.e new sessions#Start).
 		return (fn.NumIn() == 1 || (fn.NumIn() == 2 && fn.IsVariadic())) && IsContext(fn.In(0))
 	}
+
+	di.DefaultErrorHandler = func(ctxValue reflect.Value, err error) {
+		// the dependency's input argument is always a Context, see the type checker above.
+		if ctx, ok := ctxValue.Interface().(context.Context); ok {
+			DispatchErr(ctx, DefaultErrStatusCode, err)
+		}
+	}
 }
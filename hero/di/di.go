@@ -18,6 +18,10 @@ var (
 	DefaultHijacker Hijacker
 	// DefaultTypeChecker is the typechecker used on the package-level Struct & Func functions.
 	DefaultTypeChecker TypeChecker
+	// DefaultErrorHandler is called when a dynamic binder function, i.e `func(ctx) (T, error)`,
+	// returns a non-nil error. Its "ctx" is the binder's first input value.
+	// If nil then a 400 Bad Request status code and the error's text are written instead.
+	DefaultErrorHandler func(ctx reflect.Value, err error)
 )
 
 // Struct is being used to return a new injector based on
@@ -106,7 +106,9 @@ func MakeReturnValue(fn reflect.Value, goodFunc TypeChecker) (func([]reflect.Val
 			errVal := results[1]
 			if !errVal.IsNil() {
 				// error is not nil, do something with it.
-				if ctx, ok := ctxValue[0].Interface().(interface {
+				if DefaultErrorHandler != nil {
+					DefaultErrorHandler(ctxValue[0], errVal.Interface().(error))
+				} else if ctx, ok := ctxValue[0].Interface().(interface {
 					StatusCode(int)
 					WriteString(string) (int, error)
 					StopExecution()
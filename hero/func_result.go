@@ -80,7 +80,15 @@ type compatibleErr interface {
 var DefaultErrStatusCode = 400
 
 // DispatchErr writes the error to the response.
+// Field-level validation errors are written as a validation problem,
+// see `context#NewValidationProblem`.
 func DispatchErr(ctx context.Context, status int, err error) {
+	if errs, ok := context.AsValidationErrors(err); ok {
+		ctx.Problem(context.NewValidationProblem(errs))
+		ctx.StopExecution()
+		return
+	}
+
 	if status < 400 {
 		status = DefaultErrStatusCode
 	}
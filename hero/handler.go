@@ -86,10 +86,13 @@ func makeHandler(handler interface{}, values ...reflect.Value) (context.Handler,
 	}
 
 	h := func(ctx context.Context) {
-		// in := make([]reflect.Value, n, n)
-		// funcInjector.Inject(&in, reflect.ValueOf(ctx))
-		// DispatchFuncResult(ctx, fn.Call(in))
-		DispatchFuncResult(ctx, nil, funcInjector.Call(reflect.ValueOf(ctx)))
+		in := make([]reflect.Value, n)
+		funcInjector.Inject(&in, reflect.ValueOf(ctx))
+		if ctx.IsStopped() {
+			return // i.e a dependency failed to bind, see `di.DefaultErrorHandler`.
+		}
+
+		DispatchFuncResult(ctx, nil, fn.Call(in))
 	}
 
 	return h, nil
@@ -9,6 +9,8 @@ import (
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/httptest"
 
+	"github.com/gavv/httpexpect"
+
 	. "github.com/kataras/iris/v12/hero"
 )
 
@@ -126,3 +128,59 @@ func TestBindFunctionAsFunctionInputArgument(t *testing.T) {
 	e.POST("/").WithFormField("username", expectedUsername).
 		Expect().Status(iris.StatusOK).Body().Equal(expectedUsername)
 }
+
+func TestBody(t *testing.T) {
+	app := iris.New()
+	h := New().Register(Body(testUserStruct{}), Body(new(testUserStruct)))
+	app.Post("/", h.Handler(func(u testUserStruct) string {
+		return u.Username
+	}))
+	app.Post("/ptr", h.Handler(func(u *testUserStruct) string {
+		return u.Username
+	}))
+
+	e := httptest.New(t, app)
+	e.POST("/").WithJSON(testUserStruct{Username: "kataras"}).
+		Expect().Status(iris.StatusOK).Body().Equal("kataras")
+	e.POST("/ptr").WithJSON(testUserStruct{Username: "kataras"}).
+		Expect().Status(iris.StatusOK).Body().Equal("kataras")
+	e.POST("/").WithBytes([]byte("{")).
+		Expect().Status(DefaultErrStatusCode)
+}
+
+type testValidator struct{}
+
+func (testValidator) Struct(v interface{}) error {
+	if u, ok := v.(*testUserStruct); ok && u.Username == "" {
+		return iris.ValidationErrors{{Field: "Username", Tag: "required", Reason: "username is required"}}
+	}
+
+	return nil
+}
+
+func TestHandlerValidation(t *testing.T) {
+	app := iris.New()
+	app.Validator = testValidator{}
+
+	h := New()
+	h.Register(func(ctx iris.Context) (testUserStruct, error) {
+		var u testUserStruct
+		err := ctx.ReadJSON(&u)
+		return u, err
+	})
+
+	app.Post("/", h.Handler(func(u testUserStruct) string {
+		return u.Username
+	}))
+
+	e := httptest.New(t, app)
+	e.POST("/").WithJSON(testUserStruct{ID: 1, Username: "kataras"}).Expect().
+		Status(httptest.StatusOK).Body().Equal("kataras")
+	e.POST("/").WithJSON(testUserStruct{ID: 1}).Expect().
+		Status(httptest.StatusBadRequest).
+		JSON(httpexpect.ContentOpts{MediaType: "application/problem+json"}).Equal(iris.Map{
+		"status": iris.StatusBadRequest,
+		"title":  "Your request parameters didn't validate.",
+		"errors": []iris.Map{{"field": "Username", "tag": "required", "reason": "username is required"}},
+	})
+}
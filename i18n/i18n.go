@@ -336,6 +336,8 @@ func (i *I18n) GetLocale(ctx context.Context) context.Locale {
 	}
 
 	if !ok && i.Cookie != "" {
+		// the response now depends on the client's cookie, let caches know about it.
+		context.AddVaryHeader(ctx.ResponseWriter().Header(), "Cookie")
 		if v := ctx.GetCookie(i.Cookie); v != "" {
 			_, index, ok = i.TryMatchString(v) // url.QueryUnescape(cookie.Value)
 		}
@@ -348,6 +350,7 @@ func (i *I18n) GetLocale(ctx context.Context) context.Locale {
 	}
 
 	if !ok {
+		context.AddVaryHeader(ctx.ResponseWriter().Header(), acceptLanguageHeaderKey)
 		if v := ctx.GetHeader(acceptLanguageHeaderKey); v != "" {
 			desired, _, err := language.ParseAcceptLanguage(v)
 			if err == nil {
@@ -10,6 +10,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,6 +24,7 @@ import (
 	"github.com/kataras/iris/v12/core/host"
 	"github.com/kataras/iris/v12/core/netutil"
 	"github.com/kataras/iris/v12/core/router"
+	"github.com/kataras/iris/v12/macro"
 
 	// handlerconv conversions
 	"github.com/kataras/iris/v12/core/handlerconv"
@@ -153,6 +155,14 @@ type Application struct {
 	// See `Context#Tr` method for request-based translations.
 	I18n *i18n.I18n
 
+	// Validator validates the request values filled by the
+	// `Context.ReadJSON`, `ReadXML`, `ReadYAML`, `ReadForm` and `ReadQuery` methods
+	// and the MVC and hero dependencies that use them.
+	// The go-playground/validator's `*Validate` value can be used as it's.
+	//
+	// Defaults to nil, no validation.
+	Validator context.Validator
+
 	// view engine
 	view view.View
 	// used for build
@@ -307,6 +317,100 @@ func (app *Application) I18nReadOnly() context.I18nReadOnly {
 	return app.I18n
 }
 
+// Validate validates a value using the application's `Validator`.
+// It returns nil if passed or no validator is registered.
+func (app *Application) Validate(v interface{}) error {
+	if app.Validator == nil {
+		return nil
+	}
+
+	return app.Validator.Struct(v)
+}
+
+// URLFor returns the URL of a route based on its name, the values of its dynamic path parameters
+// and an optional url query. The scheme, the host and the route's subdomain are included
+// when the application's virtual host is known, i.e after `Run` or `NewHost`, otherwise it returns just the path and the query.
+//
+// The "params" can be an iris.Map, a map[string]string, a url.Values or a struct whose fields
+// are matched to the parameters by their "param" tag or their names, nil for static routes.
+// The values are checked against the parameters' macros, the value of a wildcard subdomain
+// is set through the "subdomain" key.
+//
+// Example Code:
+// app.Get("/users/{id:uint64 min(1)}", getUser).Name = "user"
+// app.URLFor("user", iris.Map{"id": 42}, url.Values{"tab": []string{"posts"}})
+// Returns: "/users/42?tab=posts", or "http://localhost:8080/users/42?tab=posts" when the app is running.
+//
+// Use the "urlfor" template function to resolve a URL from the view engines, i.e
+// {{ urlfor "user" "id" 42 "tab" "posts" }}, the keys which are not path parameters are added to the query.
+//
+// See `router.RoutePathReverser.URLFor` for more.
+func (app *Application) URLFor(routeName string, params interface{}, query url.Values) (string, error) {
+	var options []router.RoutePathReverserOption
+	if len(app.Hosts) > 0 {
+		// the vhost does not keep the 443 port.
+		if srv := app.Hosts[0].Server; netutil.IsTLS(srv) || netutil.ResolvePort(srv.Addr) == 443 {
+			options = append(options, router.WithScheme(netutil.SchemeHTTPS))
+		}
+	}
+
+	if vhost := app.config.GetVHost(); vhost != "" {
+		options = append(options, router.WithHost(vhost))
+	}
+
+	return router.NewRoutePathReverser(app.APIBuilder, options...).URLFor(routeName, params, query)
+}
+
+// urlFor is the "urlfor" template function,
+// it accepts the route name followed by key-value pairs of path parameters and url query values.
+// It logs the error and returns an empty string on failure.
+func (app *Application) urlFor(routeName string, pairs ...interface{}) string {
+	if len(pairs)%2 != 0 {
+		app.logger.Errorf("urlfor %q: odd number of key-value pairs", routeName)
+		return ""
+	}
+
+	var (
+		params = make(Map)
+		query  = make(url.Values)
+	)
+
+	var (
+		tmplParams        []macro.TemplateParam
+		wildcardSubdomain bool
+	)
+	if r := app.GetRoute(routeName); r != nil {
+		tmplParams = r.Tmpl().Params
+		wildcardSubdomain = r.Subdomain == router.SubdomainWildcardIndicator
+	}
+
+pairs:
+	for i := 0; i < len(pairs); i += 2 {
+		key := fmt.Sprint(pairs[i])
+		if wildcardSubdomain && key == router.SubdomainParamName {
+			params[key] = pairs[i+1]
+			continue
+		}
+
+		for _, p := range tmplParams {
+			if p.Name == key {
+				params[key] = pairs[i+1]
+				continue pairs
+			}
+		}
+
+		query.Add(key, fmt.Sprint(pairs[i+1]))
+	}
+
+	u, err := app.URLFor(routeName, params, query)
+	if err != nil {
+		app.logger.Error(err)
+		return ""
+	}
+
+	return u
+}
+
 var (
 	// HTML view engine.
 	// Shortcut of the kataras/iris/view.HTML.
@@ -385,6 +489,14 @@ var (
 	//
 	// A shortcut for the `context#NewConditionalHandler`.
 	NewConditionalHandler = context.NewConditionalHandler
+	// Timeout returns a Handler which bounds the execution time of the next handlers,
+	// when the timeout expires the request's context is canceled, the registered `OnErrorCode(503)`
+	// handler responds and any later write is discarded.
+	//
+	// See `Party.SetTimeout` and `Route.SetTimeout` too.
+	//
+	// A shortcut for the `context#Timeout`.
+	Timeout = context.Timeout
 	// FileServer returns a Handler which serves files from a specific system, phyisical, directory
 	// or an embedded one.
 	// The first parameter is the directory, relative to the executable program.
@@ -411,6 +523,17 @@ var (
 	//
 	// A shortcut for the `context#Gzip`.
 	Gzip = context.Gzip
+	// Compress is a middleware which enables writing
+	// using the best compression algorithm that the client supports,
+	// i.e brotli, zstd, gzip or deflate.
+	//
+	// A shortcut for the `context#Compress`.
+	Compress = context.Compress
+	// DecompressBody is a middleware which decompresses the request body
+	// based on its "Content-Encoding" header, i.e gzip, deflate, brotli or zstd.
+	//
+	// A shortcut for the `context#DecompressBody`.
+	DecompressBody = context.DecompressBody
 	// FromStd converts native http.Handler, http.HandlerFunc & func(w, r, next) to context.Handler.
 	//
 	// Supported form types:
@@ -520,6 +643,16 @@ var (
 	//
 	// A shortcut for the `context#NewProblem`.
 	NewProblem = context.NewProblem
+	// NewValidationProblem returns a new 400 Bad Request Problem
+	// with its "errors" field filled by the field-level validation failures.
+	//
+	// A shortcut for the `context#NewValidationProblem`.
+	NewValidationProblem = context.NewValidationProblem
+	// AsValidationErrors reports whether an error describes field-level validation failures,
+	// i.e the result of a `Context.ReadJSON` when the `Application.Validator` is set.
+	//
+	// A shortcut for the `context#AsValidationErrors`.
+	AsValidationErrors = context.AsValidationErrors
 	// XMLMap wraps a map[string]interface{} to compatible xml marshaler,
 	// in order to be able to render maps as XML on the `Context.XML` method.
 	//
@@ -541,6 +674,17 @@ const (
 	RouteError = router.RouteError
 )
 
+// Constants for the `router.PathRules.TrailingSlash` field.
+// See `Party#SetPathRules`.
+const (
+	// TrailingSlashRemove redirects "/a/" to the "/a" route, the default rule.
+	TrailingSlashRemove = router.TrailingSlashRemove
+	// TrailingSlashAdd registers the routes with a trailing slash and redirects "/a" to the "/a/" route.
+	TrailingSlashAdd = router.TrailingSlashAdd
+	// TrailingSlashStrict registers the routes as they are given, "/a" and "/a/" are different routes.
+	TrailingSlashStrict = router.TrailingSlashStrict
+)
+
 // Contains the enum values of the `Context.GetReferrer()` method,
 // shortcuts of the context subpackage.
 const (
@@ -680,6 +824,25 @@ func (app *Application) Shutdown(ctx stdContext.Context) error {
 	return nil
 }
 
+// mergeMountedViews registers the view engines of the Applications
+// mounted, recursively, to the "from" Application, see `Party.Mount`.
+func (app *Application) mergeMountedViews(from *Application) {
+	for _, h := range from.GetMounted() {
+		mounted, ok := h.(*Application)
+		if !ok {
+			continue
+		}
+
+		for _, e := range mounted.view.Engines() {
+			if !app.view.Has(e) {
+				app.view.Register(e)
+			}
+		}
+
+		app.mergeMountedViews(mounted)
+	}
+}
+
 // Build sets up, once, the framework.
 // It builds the default router with its default macros
 // and the template functions that are very-closed to iris.
@@ -698,6 +861,7 @@ func (app *Application) Build() error {
 	if !app.builded {
 		app.builded = true
 		rp.Err(app.APIBuilder.GetReporter())
+		app.mergeMountedViews(app)
 
 		if app.defaultMode { // the app.I18n and app.View will be not available until Build.
 			if !app.I18n.Loaded() {
@@ -757,7 +921,7 @@ func (app *Application) Build() error {
 			// Each engine has their defaults, i.e yield,render,render_r,partial, params...
 			rv := router.NewRoutePathReverser(app.APIBuilder)
 			app.view.AddFunc("urlpath", rv.Path)
-			// app.view.AddFunc("url", rv.URL)
+			app.view.AddFunc("urlfor", app.urlFor)
 			if err := app.view.Load(); err != nil {
 				rp.Group("View Builder").Err(err)
 			}
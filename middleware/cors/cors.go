@@ -0,0 +1,270 @@
+// Package cors provides a Cross-Origin Resource Sharing (CORS) middleware,
+// which also answers the preflight requests of the routes automatically.
+//
+// Read more at: https://developer.mozilla.org/en-US/docs/Web/HTTP/CORS
+package cors
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+const (
+	originHeaderKey           = "Origin"
+	allowOriginHeaderKey      = "Access-Control-Allow-Origin"
+	allowCredentialsHeaderKey = "Access-Control-Allow-Credentials"
+	allowMethodsHeaderKey     = "Access-Control-Allow-Methods"
+	allowHeadersHeaderKey     = "Access-Control-Allow-Headers"
+	exposeHeadersHeaderKey    = "Access-Control-Expose-Headers"
+	maxAgeHeaderKey           = "Access-Control-Max-Age"
+	requestMethodHeaderKey    = "Access-Control-Request-Method"
+	requestHeadersHeaderKey   = "Access-Control-Request-Headers"
+	anyOrigin                 = "*"
+	valuesSeparator           = ", "
+)
+
+// Config the configs for the CORS middleware.
+type Config struct {
+	// AllowedOrigins is a list of origins a cross-domain request can be executed from.
+	// The special "*" value allows all origins.
+	// An origin may contain one wildcard, i.e "https://*.example.com".
+	//
+	// Defaults to "*" when AllowedOrigins, AllowedOriginPatterns and AllowOriginFunc are all empty.
+	AllowedOrigins []string
+	// AllowedOriginPatterns is a list of regular expressions which an origin can match.
+	AllowedOriginPatterns []*regexp.Regexp
+	// AllowOriginFunc is a custom function to validate the origin.
+	// The origin is allowed if it's matched by any of the AllowedOrigins, AllowedOriginPatterns or AllowOriginFunc.
+	AllowOriginFunc func(ctx context.Context, origin string) bool
+	// AllowedMethods is a list of methods the client is allowed to use with cross-domain requests.
+	//
+	// Defaults to the methods of the routes registered for the requested path.
+	AllowedMethods []string
+	// AllowedHeaders is a list of non simple headers the client is allowed to use with cross-domain requests.
+	// The special "*" value allows all headers.
+	//
+	// Defaults to the headers requested by the client.
+	AllowedHeaders []string
+	// ExposedHeaders indicates which headers are safe to expose to the client.
+	ExposedHeaders []string
+	// AllowCredentials indicates whether the request can include user credentials like
+	// cookies, HTTP authentication or client side SSL certificates.
+	// When true, the origin itself is sent instead of the "*".
+	AllowCredentials bool
+	// MaxAge indicates how long the results of a preflight request can be cached.
+	// Zero means no max age header.
+	MaxAge time.Duration
+	// OptionsPassthrough instructs the preflight requests to continue to the next handlers
+	// instead of being answered with a 204 No Content status code.
+	OptionsPassthrough bool
+}
+
+// DefaultConfig returns the default configs for the CORS middleware,
+// it allows all origins.
+func DefaultConfig() Config {
+	return Config{AllowedOrigins: []string{anyOrigin}}
+}
+
+type cors struct {
+	config Config
+
+	allowAnyOrigin  bool
+	origins         []string
+	wildcardOrigins [][2]string // prefix and suffix.
+	allowedHeaders  string
+	exposedHeaders  string
+	maxAge          string
+}
+
+// New returns a new CORS handler based on the "c" configuration.
+// Register it with `Party.Use` or `Application.UseGlobal`,
+// see `Register` to answer the preflight requests too.
+func New(c Config) context.Handler {
+	cr := &cors{config: c}
+
+	if len(c.AllowedOrigins) == 0 && len(c.AllowedOriginPatterns) == 0 && c.AllowOriginFunc == nil {
+		cr.allowAnyOrigin = true
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		if origin == anyOrigin {
+			cr.allowAnyOrigin = true
+			continue
+		}
+
+		if idx := strings.IndexByte(origin, '*'); idx != -1 {
+			cr.wildcardOrigins = append(cr.wildcardOrigins, [2]string{origin[:idx], origin[idx+1:]})
+			continue
+		}
+
+		cr.origins = append(cr.origins, origin)
+	}
+
+	allowAnyHeader := false
+	for _, header := range c.AllowedHeaders {
+		if header == "*" {
+			allowAnyHeader = true
+			break
+		}
+	}
+
+	if !allowAnyHeader && len(c.AllowedHeaders) > 0 {
+		cr.allowedHeaders = strings.Join(c.AllowedHeaders, valuesSeparator)
+	}
+
+	cr.exposedHeaders = strings.Join(c.ExposedHeaders, valuesSeparator)
+
+	if c.MaxAge > 0 {
+		cr.maxAge = strconv.FormatInt(int64(c.MaxAge/time.Second), 10)
+	}
+
+	return cr.handler
+}
+
+// Register registers the CORS handler to the "p" Party (i.e the Application) and its future routes
+// and answers the preflight requests of those routes automatically, see `Party.Preflight`.
+// It should be called before the routes registration and any other middleware,
+// so the responses of the rest of the middleware contain the CORS headers too.
+//
+// Usage:
+// cors.Register(app, cors.Config{AllowedOrigins: []string{"https://*.example.com"}})
+// app.Get("/", handler)
+func Register(p router.Party, c Config) context.Handler {
+	h := New(c)
+	p.Use(h)
+	p.Preflight(h)
+	return h
+}
+
+func (cr *cors) isOriginAllowed(ctx context.Context, origin string) bool {
+	if cr.allowAnyOrigin {
+		return true
+	}
+
+	lowerOrigin := strings.ToLower(origin)
+	for _, o := range cr.origins {
+		if o == lowerOrigin {
+			return true
+		}
+	}
+
+	for _, w := range cr.wildcardOrigins {
+		if len(lowerOrigin) >= len(w[0])+len(w[1]) && strings.HasPrefix(lowerOrigin, w[0]) && strings.HasSuffix(lowerOrigin, w[1]) {
+			return true
+		}
+	}
+
+	for _, pattern := range cr.config.AllowedOriginPatterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	if cr.config.AllowOriginFunc != nil {
+		return cr.config.AllowOriginFunc(ctx, origin)
+	}
+
+	return false
+}
+
+func (cr *cors) handler(ctx context.Context) {
+	origin := ctx.GetHeader(originHeaderKey)
+	isPreflight := ctx.Method() == http.MethodOptions && ctx.GetHeader(requestMethodHeaderKey) != ""
+
+	h := ctx.ResponseWriter().Header()
+	if isPreflight {
+		context.AddVaryHeader(h, originHeaderKey, requestMethodHeaderKey, requestHeadersHeaderKey)
+	} else {
+		context.AddVaryHeader(h, originHeaderKey)
+	}
+
+	if origin == "" {
+		// not a cross-origin request.
+		ctx.Next()
+		return
+	}
+
+	if !cr.isOriginAllowed(ctx, origin) {
+		if isPreflight {
+			ctx.StatusCode(http.StatusForbidden)
+			ctx.StopExecution()
+			return
+		}
+
+		ctx.Next()
+		return
+	}
+
+	if cr.allowAnyOrigin && !cr.config.AllowCredentials {
+		h.Set(allowOriginHeaderKey, anyOrigin)
+	} else {
+		h.Set(allowOriginHeaderKey, origin)
+	}
+
+	if cr.config.AllowCredentials {
+		h.Set(allowCredentialsHeaderKey, "true")
+	}
+
+	if !isPreflight {
+		if cr.exposedHeaders != "" {
+			h.Set(exposeHeadersHeaderKey, cr.exposedHeaders)
+		}
+
+		ctx.Next()
+		return
+	}
+
+	h.Set(allowMethodsHeaderKey, strings.Join(cr.allowedMethods(ctx), valuesSeparator))
+
+	if cr.allowedHeaders != "" {
+		h.Set(allowHeadersHeaderKey, cr.allowedHeaders)
+	} else if requestHeaders := ctx.GetHeader(requestHeadersHeaderKey); requestHeaders != "" {
+		// allow any header or not configured: reflect the requested ones.
+		h.Set(allowHeadersHeaderKey, requestHeaders)
+	}
+
+	if cr.maxAge != "" {
+		h.Set(maxAgeHeaderKey, cr.maxAge)
+	}
+
+	if cr.config.OptionsPassthrough {
+		ctx.Next()
+		return
+	}
+
+	ctx.StatusCode(http.StatusNoContent)
+	ctx.StopExecution()
+}
+
+// allowedMethods returns the configured allowed methods
+// or the methods of the routes registered for the requested path.
+func (cr *cors) allowedMethods(ctx context.Context) []string {
+	if len(cr.config.AllowedMethods) > 0 {
+		return cr.config.AllowedMethods
+	}
+
+	var (
+		methods []string
+		app     = ctx.Application()
+		path    = ctx.Path()
+	)
+
+	for _, method := range router.AllMethods {
+		if method == http.MethodOptions {
+			continue
+		}
+
+		if app.RouteExists(ctx, method, path) {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
@@ -0,0 +1,94 @@
+package cors_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/middleware/cors"
+)
+
+func TestCORS(t *testing.T) {
+	app := iris.New()
+	cors.Register(app, cors.Config{
+		AllowedOrigins:        []string{"https://example.com", "https://*.example.org"},
+		AllowedOriginPatterns: []*regexp.Regexp{regexp.MustCompile(`^https://test-\d+\.local$`)},
+		AllowOriginFunc: func(ctx iris.Context, origin string) bool {
+			return origin == "https://custom.local"
+		},
+		ExposedHeaders:   []string{"X-Total-Count"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	app.Get("/users/{id:int}", func(ctx iris.Context) {
+		ctx.WriteString("get")
+	})
+	app.Put("/users/{id:int}", func(ctx iris.Context) {
+		ctx.WriteString("put")
+	})
+	app.Delete("/users/{id:int}", func(ctx iris.Context) {
+		ctx.WriteString("delete")
+	})
+	app.Options("/custom", func(ctx iris.Context) {
+		ctx.WriteString("custom options")
+	})
+	app.Post("/custom", func(ctx iris.Context) {
+		ctx.WriteString("post")
+	})
+
+	e := httptest.New(t, app)
+
+	for _, origin := range []string{"https://example.com", "https://api.example.org", "https://test-42.local", "https://custom.local"} {
+		e.OPTIONS("/users/42").WithHeader("Origin", origin).
+			WithHeader("Access-Control-Request-Method", "PUT").
+			WithHeader("Access-Control-Request-Headers", "Content-Type").
+			Expect().Status(httptest.StatusNoContent).
+			Header("Access-Control-Allow-Origin").Equal(origin)
+	}
+
+	r := e.OPTIONS("/users/42").WithHeader("Origin", "https://example.com").
+		WithHeader("Access-Control-Request-Method", "PUT").
+		WithHeader("Access-Control-Request-Headers", "Content-Type").Expect()
+	r.Header("Access-Control-Allow-Methods").Equal("GET, PUT, DELETE")
+	r.Header("Access-Control-Allow-Headers").Equal("Content-Type")
+	r.Header("Access-Control-Allow-Credentials").Equal("true")
+	r.Header("Access-Control-Max-Age").Equal("600")
+	r.Headers().Value("Vary").Equal([]string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"})
+
+	e.OPTIONS("/users/42").WithHeader("Origin", "https://evil.com").
+		WithHeader("Access-Control-Request-Method", "PUT").Expect().
+		Status(httptest.StatusForbidden).Header("Access-Control-Allow-Origin").Empty()
+
+	r = e.GET("/users/42").WithHeader("Origin", "https://example.com").Expect()
+	r.Status(httptest.StatusOK).Body().Equal("get")
+	r.Header("Access-Control-Allow-Origin").Equal("https://example.com")
+	r.Header("Access-Control-Expose-Headers").Equal("X-Total-Count")
+
+	r = e.GET("/users/42").WithHeader("Origin", "https://evil.com").Expect()
+	r.Status(httptest.StatusOK).Body().Equal("get")
+	r.Header("Access-Control-Allow-Origin").Empty()
+
+	// a registered OPTIONS route is not overridden.
+	e.OPTIONS("/custom").Expect().Status(httptest.StatusOK).Body().Equal("custom options")
+	e.OPTIONS("/custom").WithHeader("Origin", "https://example.com").
+		WithHeader("Access-Control-Request-Method", "POST").Expect().
+		Status(httptest.StatusNoContent).Header("Access-Control-Allow-Methods").Equal("POST")
+}
+
+func TestCORSAllowAnyOrigin(t *testing.T) {
+	app := iris.New()
+	cors.Register(app, cors.DefaultConfig())
+	app.Post("/", func(ctx iris.Context) {
+		ctx.WriteString("post")
+	})
+
+	e := httptest.New(t, app)
+	e.OPTIONS("/").WithHeader("Origin", "https://example.com").
+		WithHeader("Access-Control-Request-Method", "POST").Expect().
+		Status(httptest.StatusNoContent).Header("Access-Control-Allow-Origin").Equal("*")
+	e.POST("/").WithHeader("Origin", "https://example.com").Expect().
+		Status(httptest.StatusOK).Header("Access-Control-Allow-Origin").Equal("*")
+}
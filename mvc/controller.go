@@ -374,6 +374,8 @@ func (c *ControllerActivator) handleMany(method, path, funcName string, override
 		// change the main handler's name in order to respect the controller's and give
 		// a proper debug message.
 		r.MainHandlerName = fmt.Sprintf("%s.%s", c.fullName, funcName)
+		// describe the request and response bodies for the API documents.
+		hero.DescribeWith(r, c.Value.MethodByName(funcName).Interface(), funcDependencies)
 	}
 
 	// add this as a reserved method name in order to
@@ -0,0 +1,247 @@
+// Package openapi generates OpenAPI 3 documents from the registered routes.
+//
+// The path parameters are described by their macro types and functions,
+// i.e "/users/{id:uint64 min(1)}", and the request and response bodies by the
+// `Route.SetRequestBody` and `Route.SetResponse` examples,
+// which are filled automatically for MVC controllers' methods (their request bodies by the `hero.Body` dependencies),
+// see `hero.Describe` for hero functions.
+//
+// Read more at: https://swagger.io/specification/
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+// Version is the OpenAPI Specification version of the generated documents.
+const Version = "3.0.3"
+
+// Config the configs for the generated document.
+type Config struct {
+	// Title is the title of the API.
+	//
+	// Defaults to "API".
+	Title string
+	// Version is the version of the API, not the OpenAPI Specification's one.
+	//
+	// Defaults to "1.0.0".
+	Version string
+	// Description is a short description of the API.
+	Description string
+	// Servers are the URLs of the servers which serve the API, i.e "https://api.example.com/v1".
+	Servers []string
+	// Filter, if not nil, reports whether a route should be included to the document.
+	// The offline and the OPTIONS routes are never included.
+	Filter func(r *router.Route) bool
+}
+
+type (
+	// Document is the root object of an OpenAPI document.
+	Document struct {
+		OpenAPI    string                  `json:"openapi" yaml:"openapi"`
+		Info       Info                    `json:"info" yaml:"info"`
+		Servers    []Server                `json:"servers,omitempty" yaml:"servers,omitempty"`
+		Paths      map[string]PathItem     `json:"paths" yaml:"paths"`
+		Components *Components             `json:"components,omitempty" yaml:"components,omitempty"`
+		Tags       []Tag                   `json:"tags,omitempty" yaml:"tags,omitempty"`
+		schemas    map[reflect.Type]string // the registered component schemas' names.
+	}
+
+	// Info provides metadata about the API.
+	Info struct {
+		Title       string `json:"title" yaml:"title"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+		Version     string `json:"version" yaml:"version"`
+	}
+
+	// Server is a server which serves the API.
+	Server struct {
+		URL string `json:"url" yaml:"url"`
+	}
+
+	// Tag adds metadata to a tag used by the operations.
+	Tag struct {
+		Name string `json:"name" yaml:"name"`
+	}
+
+	// PathItem describes the operations available on a single path,
+	// the key is the lowercase HTTP method.
+	PathItem map[string]*Operation
+
+	// Operation describes a single API operation on a path, a route.
+	Operation struct {
+		Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+		Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+		OperationID string               `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+		Parameters  []*Parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+		RequestBody *RequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+		Responses   map[string]*Response `json:"responses" yaml:"responses"`
+		// Subdomain is the subdomain of the route, if any, as OpenAPI has no such field.
+		Subdomain string `json:"x-subdomain,omitempty" yaml:"x-subdomain,omitempty"`
+	}
+
+	// Parameter describes a single operation parameter.
+	Parameter struct {
+		Name     string  `json:"name" yaml:"name"`
+		In       string  `json:"in" yaml:"in"`
+		Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+		Schema   *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+		Content  map[string]*MediaType `json:"content" yaml:"content"`
+	}
+
+	// Response describes a single response of an operation.
+	Response struct {
+		Description string                `json:"description" yaml:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	}
+
+	// MediaType provides the schema and an example of a body.
+	MediaType struct {
+		Schema  *Schema     `json:"schema,omitempty" yaml:"schema,omitempty"`
+		Example interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+	}
+
+	// Components holds the reusable schemas of the document.
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	}
+)
+
+// Generate returns a new OpenAPI document of the "routes", i.e `Application.GetRoutes()`.
+func Generate(routes []*router.Route, c Config) *Document {
+	if c.Title == "" {
+		c.Title = "API"
+	}
+
+	if c.Version == "" {
+		c.Version = "1.0.0"
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       c.Title,
+			Description: c.Description,
+			Version:     c.Version,
+		},
+		Paths:   make(map[string]PathItem),
+		schemas: make(map[reflect.Type]string),
+	}
+
+	for _, server := range c.Servers {
+		doc.Servers = append(doc.Servers, Server{URL: server})
+	}
+
+	tags := make(map[string]struct{})
+	for _, r := range routes {
+		if !r.IsOnline() || r.Method == http.MethodOptions {
+			continue
+		}
+
+		if c.Filter != nil && !c.Filter(r) {
+			continue
+		}
+
+		path, params := pathOf(r)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+
+		item[strings.ToLower(r.Method)] = doc.operation(r, params)
+
+		for _, tag := range r.Tags {
+			if _, ok := tags[tag]; !ok {
+				tags[tag] = struct{}{}
+				doc.Tags = append(doc.Tags, Tag{Name: tag})
+			}
+		}
+	}
+
+	return doc
+}
+
+func (doc *Document) operation(r *router.Route, params []*Parameter) *Operation {
+	op := &Operation{
+		Tags:        r.Tags,
+		Summary:     r.Summary,
+		Description: r.Description,
+		Parameters:  params,
+		Responses:   make(map[string]*Response),
+		Subdomain:   r.Subdomain,
+	}
+
+	// the default name is not a friendly operation id.
+	if defaultName := r.Method + r.Subdomain + r.Tmpl().Src; r.Name != defaultName {
+		op.OperationID = r.Name
+	}
+
+	if r.RequestBody != nil && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  doc.content(r.RequestBody),
+		}
+	}
+
+	for statusCode, example := range r.Responses {
+		resp := &Response{Description: http.StatusText(statusCode)}
+		if example != nil {
+			resp.Content = doc.content(example)
+		}
+
+		op.Responses[strconv.Itoa(statusCode)] = resp
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses[strconv.Itoa(http.StatusOK)] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	return op
+}
+
+func (doc *Document) content(example interface{}) map[string]*MediaType {
+	mediaType := &MediaType{Schema: doc.schemaOf(reflect.TypeOf(example))}
+	if v := reflect.ValueOf(example); !v.IsZero() {
+		mediaType.Example = example
+	}
+
+	contentType := context.ContentJSONHeaderValue
+	if indirectType(reflect.TypeOf(example)).Kind() == reflect.String {
+		contentType = context.ContentTextHeaderValue
+	}
+
+	return map[string]*MediaType{contentType: mediaType}
+}
+
+// Handler returns a handler which serves the OpenAPI document of the "provider"'s routes,
+// i.e the `Application`. The document is generated on each request, so the routes
+// registered or removed at serve-time are described too.
+// It's served as YAML when the request path ends with ".yaml" or ".yml", otherwise as JSON.
+//
+// Usage:
+// app.Get("/openapi.json", openapi.Handler(app, openapi.Config{Title: "My API"}))
+func Handler(provider router.RoutesProvider, c Config) context.Handler {
+	return func(ctx context.Context) {
+		doc := Generate(provider.GetRoutes(), c)
+
+		if path := ctx.Path(); strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+			ctx.YAML(doc)
+			return
+		}
+
+		ctx.JSON(doc)
+	}
+}
@@ -0,0 +1,163 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/hero"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/mvc"
+	"github.com/kataras/iris/v12/openapi"
+)
+
+type user struct {
+	ID       uint64   `json:"id"`
+	Username string   `json:"username"`
+	Email    string   `json:"email,omitempty"`
+	Friends  []*user  `json:"friends,omitempty"`
+	Roles    []string `json:"-"`
+}
+
+type book struct {
+	Title string `json:"title"`
+}
+
+type bookService struct {
+	books []book
+}
+
+type bookController struct{}
+
+func (c *bookController) Get(s bookService) []book {
+	return s.books
+}
+
+func (c *bookController) Post(s bookService, b book) book {
+	return b
+}
+
+type userService struct{}
+
+func TestGenerate(t *testing.T) {
+	app := iris.New()
+
+	app.Get("/users/{id:uint64 min(1)}", func(ctx iris.Context) {}).
+		SetSummary("Get a user").
+		SetTags("users").
+		SetResponse(http.StatusOK, user{ID: 1, Username: "kataras"}).
+		SetResponse(http.StatusNotFound, nil)
+
+	createUser := func(s userService, u user) (user, error) { return u, nil }
+	h := hero.New().Register(userService{}, hero.Body(user{}))
+	h.Describe(app.Post("/users", h.Handler(createUser)), createUser).
+		SetTags("users").Name = "createUser"
+	// the user is a request body only for the Hero which registered it through a Body.
+	updateUser := func(u user) user { return u }
+	other := hero.New().Register(user{})
+	other.Describe(app.Put("/users/{id:uint64 min(1)}", other.Handler(updateUser)), updateUser)
+
+	app.Get("/files/{name:string regexp(^[a-z]+$) max(32)}", func(ctx iris.Context) {})
+	app.Get("/offline", func(ctx iris.Context) {}).SetStatusOffline()
+
+	m := mvc.New(app.Party("/books"))
+	m.Register(bookService{}, hero.Body(book{}))
+	m.Handle(new(bookController))
+
+	doc := openapi.Generate(app.GetRoutes(), openapi.Config{Title: "Test"})
+
+	if expected, got := openapi.Version, doc.OpenAPI; expected != got {
+		t.Fatalf("expected openapi version: %s but got: %s", expected, got)
+	}
+
+	if expected, got := 4, len(doc.Paths); expected != got {
+		t.Fatalf("expected %d paths but got %d: %v", expected, got, doc.Paths)
+	}
+
+	getUser := doc.Paths["/users/{id}"]["get"]
+	if getUser == nil {
+		t.Fatalf("expected the get user operation")
+	}
+	if expected, got := "Get a user", getUser.Summary; expected != got {
+		t.Fatalf("expected summary: %s but got: %s", expected, got)
+	}
+	if len(getUser.Parameters) != 1 {
+		t.Fatalf("expected one path parameter but got: %d", len(getUser.Parameters))
+	}
+	param := getUser.Parameters[0]
+	if param.Name != "id" || param.In != "path" || !param.Required || param.Schema.Type != "integer" || *param.Schema.Minimum != 1 {
+		t.Fatalf("unexpected path parameter: %#+v with schema: %#+v", param, param.Schema)
+	}
+	ok := getUser.Responses["200"]
+	if ok == nil || ok.Content["application/json"].Schema.Ref != "#/components/schemas/user" {
+		t.Fatalf("unexpected 200 response: %#+v", ok)
+	}
+	if example, isUser := ok.Content["application/json"].Example.(user); !isUser || example.Username != "kataras" {
+		t.Fatalf("unexpected 200 response example: %#+v", ok.Content["application/json"].Example)
+	}
+	if notFound := getUser.Responses["404"]; notFound == nil || notFound.Content != nil {
+		t.Fatalf("unexpected 404 response: %#+v", notFound)
+	}
+
+	userSchema := doc.Components.Schemas["user"]
+	if userSchema == nil {
+		t.Fatalf("expected the user schema component")
+	}
+	if expected, got := 4, len(userSchema.Properties); expected != got {
+		t.Fatalf("expected %d user properties but got: %d", expected, got)
+	}
+	if friends := userSchema.Properties["friends"]; friends.Type != "array" || friends.Items.Ref != "#/components/schemas/user" {
+		t.Fatalf("unexpected friends schema: %#+v", friends)
+	}
+	if expected, got := []string{"id", "username"}, userSchema.Required; len(got) != 2 || got[0] != expected[0] || got[1] != expected[1] {
+		t.Fatalf("expected required properties: %v but got: %v", expected, got)
+	}
+
+	postUser := doc.Paths["/users"]["post"]
+	if postUser == nil || postUser.OperationID != "createUser" || postUser.RequestBody == nil ||
+		postUser.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/user" ||
+		postUser.RequestBody.Content["application/json"].Example != nil {
+		t.Fatalf("unexpected post user operation: %#+v", postUser)
+	}
+
+	if putUser := doc.Paths["/users/{id}"]["put"]; putUser == nil || putUser.RequestBody != nil {
+		t.Fatalf("unexpected put user operation: %#+v", putUser)
+	}
+
+	fileParam := doc.Paths["/files/{name}"]["get"].Parameters[0].Schema
+	if fileParam.Type != "string" || fileParam.Pattern != "^[a-z]+$" || *fileParam.MaxLength != 32 {
+		t.Fatalf("unexpected file parameter schema: %#+v", fileParam)
+	}
+
+	postBook := doc.Paths["/books"]["post"]
+	if postBook == nil || postBook.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/book" ||
+		postBook.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/book" {
+		t.Fatalf("unexpected post book operation: %#+v", postBook)
+	}
+	// the dependencies are not request bodies.
+	getBooks := doc.Paths["/books"]["get"]
+	if getBooks == nil || getBooks.RequestBody != nil ||
+		getBooks.Responses["200"].Content["application/json"].Schema.Type != "array" {
+		t.Fatalf("unexpected get books operation: %#+v", getBooks)
+	}
+
+	if expected, got := 1, len(doc.Tags); expected != got {
+		t.Fatalf("expected %d tags but got: %d", expected, got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	app := iris.New()
+	app.Get("/users/{id:int}", func(ctx iris.Context) {})
+	app.Get("/openapi.json", openapi.Handler(app, openapi.Config{Title: "Test"}))
+	app.Get("/openapi.yaml", openapi.Handler(app, openapi.Config{Title: "Test"}))
+
+	e := httptest.New(t, app)
+	obj := e.GET("/openapi.json").Expect().Status(httptest.StatusOK).JSON().Object()
+	obj.Value("openapi").Equal(openapi.Version)
+	obj.Value("info").Object().Value("title").Equal("Test")
+	obj.Value("paths").Object().ContainsKey("/users/{id}")
+
+	e.GET("/openapi.yaml").Expect().Status(httptest.StatusOK).
+		ContentType("application/x-yaml").Body().Contains("openapi: 3.0.3")
+}
@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/core/router"
+	"github.com/kataras/iris/v12/macro"
+	"github.com/kataras/iris/v12/macro/interpreter/ast"
+	"github.com/kataras/iris/v12/macro/interpreter/parser"
+)
+
+// Schema describes a data type.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+// pathOf returns the OpenAPI form of the route's path, i.e "/users/{id}",
+// and its parameters described by their macros.
+func pathOf(r *router.Route) (string, []*Parameter) {
+	tmpl := r.Tmpl()
+	path := tmpl.Src
+	params := make([]*Parameter, 0, len(tmpl.Params))
+
+	for _, p := range tmpl.Params {
+		path = strings.Replace(path, p.Src, "{"+p.Name+"}", 1)
+		params = append(params, &Parameter{
+			Name:     p.Name,
+			In:       "path",
+			Required: true,
+			Schema:   macroSchema(p),
+		})
+	}
+
+	return path, params
+}
+
+// macroSchema returns the schema of a path parameter
+// based on its macro type and the "min", "max", "range" and "regexp" functions.
+func macroSchema(p macro.TemplateParam) *Schema {
+	s := new(Schema)
+	switch p.Type.Indent() {
+	case macro.Int.Indent(), macro.Int64.Indent():
+		s.Type, s.Format = "integer", "int64"
+	case macro.Int8.Indent():
+		s.Type, s.Minimum, s.Maximum = "integer", float(math.MinInt8), float(math.MaxInt8)
+	case macro.Int16.Indent():
+		s.Type, s.Minimum, s.Maximum = "integer", float(math.MinInt16), float(math.MaxInt16)
+	case macro.Int32.Indent():
+		s.Type, s.Format = "integer", "int32"
+	case macro.Uint.Indent(), macro.Uint64.Indent():
+		s.Type, s.Minimum = "integer", float(0)
+	case macro.Uint8.Indent():
+		s.Type, s.Minimum, s.Maximum = "integer", float(0), float(math.MaxUint8)
+	case macro.Uint16.Indent():
+		s.Type, s.Minimum, s.Maximum = "integer", float(0), float(math.MaxUint16)
+	case macro.Uint32.Indent():
+		s.Type, s.Minimum, s.Maximum = "integer", float(0), float(math.MaxUint32)
+	case macro.Bool.Indent():
+		s.Type = "boolean"
+	case macro.Alphabetical.Indent():
+		s.Type, s.Pattern = "string", "^[a-zA-Z ]+$"
+	case macro.File.Indent():
+		s.Type, s.Pattern = "string", "^[a-zA-Z0-9_.-]*$"
+	default:
+		s.Type = "string"
+	}
+
+	// the template keeps the functions but not their names and arguments,
+	// parse the parameter's source again.
+	stmt, err := parser.NewParamParser(p.Src).Parse([]ast.ParamType{p.Type})
+	if err != nil {
+		return s
+	}
+
+	for _, fn := range stmt.Funcs {
+		switch fn.Name {
+		case "min", "max", "range":
+			values, ok := parseFloats(fn.Args)
+			if !ok {
+				continue
+			}
+
+			var min, max *float64
+			switch {
+			case fn.Name == "min" && len(values) == 1:
+				min = &values[0]
+			case fn.Name == "max" && len(values) == 1:
+				max = &values[0]
+			case fn.Name == "range" && len(values) == 2:
+				min, max = &values[0], &values[1]
+			default:
+				continue
+			}
+
+			s.setBounds(min, max)
+		case "regexp":
+			if len(fn.Args) == 1 {
+				s.Pattern = fn.Args[0]
+			}
+		}
+	}
+
+	return s
+}
+
+func parseFloats(args []string) ([]float64, bool) {
+	values := make([]float64, 0, len(args))
+	for _, arg := range args {
+		v, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+
+	return values, true
+}
+
+// setBounds sets the minimum and maximum value of a numeric schema
+// or the minimum and maximum length of a string one.
+func (s *Schema) setBounds(min, max *float64) {
+	if s.Type != "string" {
+		if min != nil {
+			s.Minimum = min
+		}
+		if max != nil {
+			s.Maximum = max
+		}
+		return
+	}
+
+	if min != nil {
+		n := int(*min)
+		s.MinLength = &n
+	}
+	if max != nil {
+		n := int(*max)
+		s.MaxLength = &n
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+// schemaOf returns the schema of a Go type,
+// named struct types are registered as component schemas and referenced.
+func (doc *Document) schemaOf(typ reflect.Type) *Schema {
+	nullable := typ != nil && typ.Kind() == reflect.Ptr
+	typ = indirectType(typ)
+	if typ == nil {
+		return &Schema{}
+	}
+
+	var s *Schema
+	switch typ.Kind() {
+	case reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		s = &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		s = &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32:
+		s = &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		s = &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		s = &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			s = &Schema{Type: "string", Format: "byte"}
+		} else {
+			s = &Schema{Type: "array", Items: doc.schemaOf(typ.Elem())}
+		}
+	case reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: doc.schemaOf(typ.Elem())}
+	case reflect.Struct:
+		if typ == timeType {
+			s = &Schema{Type: "string", Format: "date-time"}
+			break
+		}
+
+		if typ.Name() == "" {
+			s = doc.structSchema(typ)
+			break
+		}
+
+		s = &Schema{Ref: "#/components/schemas/" + doc.registerSchema(typ)}
+	default: // interfaces, functions and channels.
+		s = &Schema{}
+	}
+
+	if nullable && s.Ref == "" {
+		s.Nullable = true
+	}
+
+	return s
+}
+
+// registerSchema registers the "typ" named struct type as a component schema and returns its name.
+func (doc *Document) registerSchema(typ reflect.Type) string {
+	if name, ok := doc.schemas[typ]; ok {
+		return name
+	}
+
+	if doc.Components == nil {
+		doc.Components = &Components{Schemas: make(map[string]*Schema)}
+	}
+
+	name := typ.Name()
+	if _, exists := doc.Components.Schemas[name]; exists {
+		// same name but different package.
+		pkgPath := typ.PkgPath()
+		name = pkgPath[strings.LastIndexByte(pkgPath, '/')+1:] + "." + name
+	}
+
+	// register before its fields, a field may refer to its struct type.
+	doc.schemas[typ] = name
+	doc.Components.Schemas[name] = &Schema{}
+	*doc.Components.Schemas[name] = *doc.structSchema(typ)
+	return name
+}
+
+func (doc *Document) structSchema(typ reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	doc.fillStructSchema(s, typ)
+	return s
+}
+
+func (doc *Document) fillStructSchema(s *Schema, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous { // unexported.
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if idx := strings.IndexByte(tag, ','); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		if field.Anonymous && name == "" {
+			if embedded := indirectType(field.Type); embedded.Kind() == reflect.Struct {
+				// the json encoder flattens the embedded structs' fields.
+				doc.fillStructSchema(s, embedded)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = doc.schemaOf(field.Type)
+		if field.Type.Kind() != reflect.Ptr && !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
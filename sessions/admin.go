@@ -0,0 +1,238 @@
+package sessions
+
+import (
+	stdContext "context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+)
+
+// SessionInfo contains the metadata of a session, see `Sessions.VisitSessions`.
+type SessionInfo struct {
+	ID string `json:"id"`
+	// Expires is zero when the session does not expire.
+	Expires time.Time `json:"expires"`
+	// LastAccess is the time that the session was last started by a request of this process,
+	// see `Session.LastAccess`. It's kept in memory only, it is not stored to the session database,
+	// so it's zero when the session is not started by this process,
+	// i.e. a database one after a restart or one of another instance of a load-balanced application.
+	LastAccess time.Time `json:"last_access"`
+}
+
+// VisitSessions calls the "cb" with the information of each non-expired session,
+// the "cb" returns false to stop the iteration.
+//
+// The sessions are listed by the registered database if it implements the `Lister` (or `ListerV2`),
+// e.g. the redis, badger, boltdb and sqldb ones, otherwise only the sessions started by this process are listed.
+// It returns `ErrNotImplemented` when the `Config.CookieStore` is used.
+func (s *Sessions) VisitSessions(ctx stdContext.Context, cb func(SessionInfo) bool) error {
+	if s.config.CookieStore != nil {
+		return ErrNotImplemented
+	}
+
+	return s.provider.VisitSessions(ctx, cb)
+}
+
+// Index sets the "key" entry of the request's session, see `Get` and `Start`, to the "value"
+// and adds the session to the sessions of that key and value, i.e. the sessions of a user ID,
+// so `Find` and `DestroyBy` resolve them through the index instead of a scan of all sessions.
+// The values are indexed by their string form (`fmt.Sprint`).
+//
+// The index is kept by the registered database if it implements the `Indexer`,
+// e.g. the memory (default), redis, badger, boltdb and sqldb ones,
+// it returns `ErrNotImplemented` otherwise and when the `Config.CookieStore` is used.
+//
+// Example:
+//
+//	app.Post("/login", func(ctx iris.Context) {
+//		[...authenticate]
+//		sess.Regenerate(ctx)
+//		sess.Index(ctx, "user_id", user.ID)
+//	})
+//	[...]
+//	sess.DestroyBy(ctx.Request().Context(), "user_id", user.ID)
+func (s *Sessions) Index(ctx context.Context, key string, value interface{}) error {
+	if s.config.CookieStore != nil {
+		return ErrNotImplemented
+	}
+
+	sess := Get(ctx)
+	if sess == nil || sess.provider != s.provider {
+		sess = s.Start(ctx)
+	}
+	stdCtx := ctx.Request().Context()
+
+	// the session is indexed first, an index entry of a value which fails to be set is removed by `Find`.
+	if err := s.provider.Index(stdCtx, sess.sid, key, fmt.Sprint(value)); err != nil {
+		return err
+	}
+
+	return sess.SetContext(stdCtx, key, value)
+}
+
+// Find returns the information of the sessions which contain the "key"
+// with a value equal to the "value", i.e. the sessions of a user ID.
+// The values are compared by their string form (`fmt.Sprint`), so "42" matches the 42 too.
+//
+// The sessions are resolved through the index of the database, so only the sessions
+// indexed by `Index` are found. The sessions of a database which does not implement
+// the `Indexer` are scanned through the `VisitSessions` and the database's `Get` of each session instead.
+// It returns `ErrNotImplemented` when the `Config.CookieStore` is used.
+func (s *Sessions) Find(ctx stdContext.Context, key string, value interface{}) ([]SessionInfo, error) {
+	if s.config.CookieStore != nil {
+		return nil, ErrNotImplemented
+	}
+
+	infos, err := s.provider.Find(ctx, key, fmt.Sprint(value))
+	if err != errNotIndexer {
+		return infos, err
+	}
+
+	return s.scan(ctx, key, value)
+}
+
+// scan returns the sessions of the "key" with the "value" through all sessions, see `Find`.
+func (s *Sessions) scan(ctx stdContext.Context, key string, value interface{}) ([]SessionInfo, error) {
+	var infos []SessionInfo
+	if err := s.VisitSessions(ctx, func(info SessionInfo) bool {
+		infos = append(infos, info)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	expected := fmt.Sprint(value)
+	found := infos[:0]
+	for _, info := range infos {
+		v, err := s.provider.db.Get(ctx, info.ID, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if v != nil && fmt.Sprint(v) == expected {
+			found = append(found, info)
+		}
+	}
+
+	return found, nil
+}
+
+// DestroyBy destroys the sessions which contain the "key" with a value equal to the "value",
+// i.e. "log out everywhere" a user by its ID. It returns the number of the destroyed sessions.
+// The sessions are resolved by `Find`, so they should be indexed by `Index`.
+// The destroy listeners are fired for the sessions started by this process, see `Find` too.
+//
+// Client's session cookie will still exist but it will be reseted on the next request.
+func (s *Sessions) DestroyBy(ctx stdContext.Context, key string, value interface{}) (int, error) {
+	infos, err := s.Find(ctx, key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, info := range infos {
+		if err = s.provider.Destroy(ctx, info.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(infos), nil
+}
+
+// AdminHandler returns a Handler which lists and revokes sessions through JSON responses.
+//
+// GET lists the sessions (`SessionInfo`), the "key" and "value" URL query parameters
+// limit the list to the sessions found by `Find`.
+// DELETE destroys the session of the "id" path or URL query parameter (204 No Content)
+// or the sessions found by the "key" and "value" URL query parameters, which responds with
+// the number of the destroyed sessions, e.g. {"destroyed": 2}.
+//
+// It responds with 501 Not Implemented when the `Config.CookieStore` is used.
+// The handler does not perform any authorization, register it behind an authentication middleware, e.g.
+//
+//	admin := app.Party("/admin/sessions", basicauth.New(...))
+//	admin.Get("/", sess.AdminHandler())
+//	admin.Delete("/", sess.AdminHandler())
+//	admin.Delete("/{id}", sess.AdminHandler())
+func (s *Sessions) AdminHandler() context.Handler {
+	return func(ctx context.Context) {
+		if s.config.CookieStore != nil {
+			s.stopAdmin(ctx, ErrNotImplemented)
+			return
+		}
+
+		stdCtx := ctx.Request().Context()
+		key, value := ctx.URLParam("key"), ctx.URLParam("value")
+
+		switch ctx.Method() {
+		case http.MethodGet:
+			var (
+				infos = []SessionInfo{}
+				err   error
+			)
+
+			if key != "" {
+				var found []SessionInfo
+				if found, err = s.Find(stdCtx, key, value); err == nil {
+					infos = append(infos, found...)
+				}
+			} else {
+				err = s.VisitSessions(stdCtx, func(info SessionInfo) bool {
+					infos = append(infos, info)
+					return true
+				})
+			}
+
+			if err != nil {
+				s.stopAdmin(ctx, err)
+				return
+			}
+
+			ctx.JSON(infos)
+		case http.MethodDelete:
+			id := ctx.Params().Get("id")
+			if id == "" {
+				id = ctx.URLParam("id")
+			}
+
+			if id != "" {
+				if err := s.provider.Destroy(stdCtx, id); err != nil {
+					s.stopAdmin(ctx, err)
+					return
+				}
+
+				ctx.StatusCode(http.StatusNoContent)
+				return
+			}
+
+			if key == "" {
+				ctx.StopExecution()
+				ctx.StatusCode(http.StatusBadRequest)
+				return
+			}
+
+			n, err := s.DestroyBy(stdCtx, key, value)
+			if err != nil {
+				s.stopAdmin(ctx, err)
+				return
+			}
+
+			ctx.JSON(context.Map{"destroyed": n})
+		default:
+			ctx.StopExecution()
+			ctx.StatusCode(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func (s *Sessions) stopAdmin(ctx context.Context, err error) {
+	ctx.StopExecution()
+	if err == ErrNotImplemented {
+		ctx.StatusCode(http.StatusNotImplemented)
+		return
+	}
+
+	s.handleError(ctx, err)
+	ctx.StatusCode(http.StatusInternalServerError)
+}
@@ -115,6 +115,20 @@ type (
 		//
 		// Defaults to false.
 		DisableSubdomainPersistence bool
+
+		// OnError if not nil, is called with the session database errors of a request,
+		// read the `Sessions.Handler` and `Sessions.Destroy` for more.
+		// It can stop the execution of the `Sessions.Handler`'s next handlers, i.e. `ctx.StopExecution()`.
+		//
+		// Defaults to nil, the errors are logged through the application's logger.
+		OnError func(ctx context.Context, err error)
+
+		// CookieStore if not nil, stores the whole session encrypted in the client's cookies
+		// instead of the server's memory or a registered database.
+		// Read the `CookieStore` type for more.
+		//
+		// Defaults to nil.
+		CookieStore *CookieStore
 	}
 )
 
@@ -0,0 +1,561 @@
+package sessions
+
+import (
+	"bytes"
+	stdContext "context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/core/memstore"
+)
+
+const (
+	// DefaultCookieStoreChunkSize is the default maximum length of a single session cookie's value,
+	// browsers limit a cookie (name, value and attributes) to 4096 bytes.
+	DefaultCookieStoreChunkSize = 3800
+	// DefaultCookieStoreMaxSize is the default maximum length of the encrypted session,
+	// split across all of its cookies.
+	DefaultCookieStoreMaxSize = 4 * DefaultCookieStoreChunkSize
+)
+
+var (
+	// ErrCookieStoreTooLarge is reported when a session of the `CookieStore` exceeds its `MaxSize`,
+	// see `Session.Err`. The session's cookies are not updated in that case.
+	ErrCookieStoreTooLarge = errors.New("cookie store: session exceeds the maximum size")
+	// ErrCookieStoreInvalid is returned when a session cookie is not signed or encrypted by any of the `CookieStore` keys.
+	ErrCookieStoreInvalid = errors.New("cookie store: invalid session cookie")
+)
+
+// CookieStore keeps the whole session on the client, there is no server-side state.
+// The session's ID, expiration, values and flash messages are serialized, encrypted with AES-GCM, signed with HMAC-SHA256
+// and split into cookies named after the `Config.Cookie`: "name", "name.1", "name.2" and so on.
+//
+// Set it to the `Config.CookieStore` field, the `Start`, `Handler` and `Get` work as before.
+//
+// Unlike the server-side sessions:
+// - the `DestroyByID` and `DestroyAll` do nothing, a session can be destroyed only through its request
+// - any change to the session should be done before the response is written, as the cookies are sent as headers
+// - the `Config.Encode`, `Config.Decode` and `Config.AllowReclaim` are ignored.
+//
+// Usage:
+//
+//	sess := sessions.New(sessions.Config{
+//	    Cookie: "mysession",
+//	    CookieStore: &sessions.CookieStore{
+//	        Keys: [][]byte{[]byte("the-current-secret"), []byte("a-previous-secret")},
+//	    },
+//	})
+type CookieStore struct {
+	// Keys are the secrets of the session cookies.
+	// The first key encrypts and signs the cookies, all of them are tried to read a cookie,
+	// so a new key can be prepended without invalidating the sessions of the older ones (key rotation).
+	// A key can be of any length, the encryption and signing keys are derived from it.
+	//
+	// At least one key is required.
+	Keys [][]byte
+	// MaxSize is the maximum length of the encrypted session, split across all of its cookies.
+	// If a session exceeds it then an `ErrCookieStoreTooLarge` is reported and its cookies are not updated.
+	//
+	// Defaults to `DefaultCookieStoreMaxSize`.
+	MaxSize int
+	// ChunkSize is the maximum length of a single cookie's value.
+	//
+	// Defaults to `DefaultCookieStoreChunkSize`.
+	ChunkSize int
+	// Transcoder serializes the session before its encryption.
+	// Note that the session values are stored as `memstore.Entry` values.
+	//
+	// Defaults to the gob encoding of the memstore.
+	Transcoder Transcoder
+
+	keys []cookieStoreKey
+}
+
+type cookieStoreKey struct {
+	aead    cipher.AEAD
+	signing []byte
+}
+
+// cookieSession is the session which is stored in the cookies.
+type cookieSession struct {
+	ID      string
+	Expires time.Time
+	Values  memstore.Store
+	Flashes map[string]cookieFlash
+}
+
+// cookieFlash is a flash message of the `cookieSession`.
+type cookieFlash struct {
+	Value interface{}
+	// Read is true when the message was fetched, it's removed on the next request.
+	Read bool
+}
+
+func (c *CookieStore) validate() (*CookieStore, error) {
+	if len(c.Keys) == 0 {
+		return nil, errors.New("cookie store: at least one key is required")
+	}
+
+	store := *c // do not modify the caller's value.
+	if store.ChunkSize <= 0 {
+		store.ChunkSize = DefaultCookieStoreChunkSize
+	}
+
+	if store.MaxSize <= 0 {
+		store.MaxSize = DefaultCookieStoreMaxSize
+	}
+
+	store.keys = make([]cookieStoreKey, 0, len(c.Keys))
+	for _, key := range c.Keys {
+		block, err := aes.NewCipher(deriveCookieStoreKey(key, "encryption"))
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		store.keys = append(store.keys, cookieStoreKey{
+			aead:    aead,
+			signing: deriveCookieStoreKey(key, "signing"),
+		})
+	}
+
+	return &store, nil
+}
+
+func deriveCookieStoreKey(key []byte, label string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte("iris-sessions-cookie-store-" + label))
+	return h.Sum(nil)
+}
+
+func (c *CookieStore) sign(key cookieStoreKey, name string, data []byte) []byte {
+	h := hmac.New(sha256.New, key.signing)
+	h.Write([]byte(name))
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// encode serializes, encrypts and signs the session with the first key.
+func (c *CookieStore) encode(name string, sess cookieSession) (string, error) {
+	var (
+		payload []byte
+		err     error
+	)
+
+	if c.Transcoder != nil {
+		payload, err = c.Transcoder.Marshal(sess)
+	} else {
+		buf := new(bytes.Buffer)
+		err = gob.NewEncoder(buf).Encode(sess)
+		payload = buf.Bytes()
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	key := c.keys[0]
+	nonce := make([]byte, key.aead.NonceSize(), key.aead.NonceSize()+len(payload)+key.aead.Overhead()+sha256.Size)
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	data := key.aead.Seal(nonce, nonce, payload, []byte(name))
+	data = append(data, c.sign(key, name, data)...)
+
+	value := base64.RawURLEncoding.EncodeToString(data)
+	if len(value) > c.MaxSize {
+		return "", ErrCookieStoreTooLarge
+	}
+
+	return value, nil
+}
+
+// decode verifies, decrypts and deserializes the session, all keys are tried.
+func (c *CookieStore) decode(name, value string) (sess cookieSession, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return
+	}
+
+	if len(data) < sha256.Size {
+		err = ErrCookieStoreInvalid
+		return
+	}
+
+	data, signature := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	for _, key := range c.keys {
+		if !hmac.Equal(signature, c.sign(key, name, data)) {
+			continue
+		}
+
+		nonceSize := key.aead.NonceSize()
+		if len(data) < nonceSize {
+			break
+		}
+
+		payload, openErr := key.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(name))
+		if openErr != nil {
+			break
+		}
+
+		if c.Transcoder != nil {
+			err = c.Transcoder.Unmarshal(payload, &sess)
+		} else {
+			err = gob.NewDecoder(bytes.NewReader(payload)).Decode(&sess)
+		}
+
+		return
+	}
+
+	err = ErrCookieStoreInvalid
+	return
+}
+
+// chunkName returns the name of the "index" cookie of a session.
+func chunkName(name string, index int) string {
+	if index == 0 {
+		return name
+	}
+
+	return name + "." + strconv.Itoa(index)
+}
+
+// isChunkName reports whether the "cookieName" is a cookie of the "name" session.
+func isChunkName(name, cookieName string) bool {
+	if cookieName == name {
+		return true
+	}
+
+	if !strings.HasPrefix(cookieName, name+".") {
+		return false
+	}
+
+	_, err := strconv.Atoi(cookieName[len(name)+1:])
+	return err == nil
+}
+
+// cookieDB is the `Database` of a single, per-request, session of the `CookieStore`,
+// each modification writes the session's cookies again.
+type cookieDB struct {
+	store    *CookieStore
+	sessions *Sessions
+	ctx      context.Context
+	options  []context.CookieOption
+
+	sid      string
+	values   memstore.Store
+	flashes  map[string]cookieFlash
+	expires  time.Duration
+	deadline time.Time
+	// the number of the cookies of the request's session,
+	// the ones that are not used by the written session are deleted.
+	chunks   int
+	released bool
+}
+
+var (
+	_ DatabaseV2    = (*cookieDB)(nil)
+	_ RegeneratorV2 = (*cookieDB)(nil)
+)
+
+func (db *cookieDB) Acquire(ctx stdContext.Context, sid string, expires time.Duration) (LifeTime, error) {
+	return LifeTime{Time: db.deadline}, nil
+}
+
+func (db *cookieDB) OnUpdateExpiration(ctx stdContext.Context, sid string, newExpires time.Duration) error {
+	db.expires = newExpires
+	db.deadline = time.Time{}
+	if newExpires > 0 {
+		db.deadline = time.Now().Add(newExpires)
+	}
+
+	return db.write()
+}
+
+func (db *cookieDB) Set(ctx stdContext.Context, sid string, lifetime LifeTime, key string, value interface{}, immutable bool) error {
+	db.values.Save(key, value, immutable)
+	return db.write()
+}
+
+func (db *cookieDB) Get(ctx stdContext.Context, sid string, key string) (interface{}, error) {
+	return db.values.Get(key), nil
+}
+
+func (db *cookieDB) Visit(ctx stdContext.Context, sid string, cb func(key string, value interface{})) error {
+	db.values.Visit(cb)
+	return nil
+}
+
+func (db *cookieDB) Len(ctx stdContext.Context, sid string) (int, error) {
+	return db.values.Len(), nil
+}
+
+func (db *cookieDB) Delete(ctx stdContext.Context, sid string, key string) (bool, error) {
+	if !db.values.Remove(key) {
+		return false, nil
+	}
+
+	return true, db.write()
+}
+
+func (db *cookieDB) Clear(ctx stdContext.Context, sid string) error {
+	db.values.Reset()
+	return db.write()
+}
+
+func (db *cookieDB) Release(ctx stdContext.Context, sid string) error {
+	db.values.Reset()
+	db.flashes = nil
+	db.released = true
+	db.setCookies(nil)
+	return nil
+}
+
+func (db *cookieDB) Regenerate(ctx stdContext.Context, oldSID, newSID string) error {
+	db.sid = newSID
+	if err := db.write(); err != nil {
+		db.sid = oldSID
+		return err
+	}
+
+	return nil
+}
+
+// write encrypts the session and replaces its cookies of the response.
+// On error the previous cookies are kept.
+func (db *cookieDB) write() error {
+	value, err := db.store.encode(db.sessions.config.Cookie, cookieSession{
+		ID:      db.sid,
+		Expires: db.deadline,
+		Values:  db.values,
+		Flashes: db.flashes,
+	})
+	if err != nil {
+		return err
+	}
+
+	var values []string
+	for len(value) > db.store.ChunkSize {
+		values = append(values, value[:db.store.ChunkSize])
+		value = value[db.store.ChunkSize:]
+	}
+
+	db.setCookies(append(values, value))
+	return nil
+}
+
+// setFlashes replaces the flash messages of the session with the "sess" ones and writes the cookies.
+func (db *cookieDB) setFlashes(sess *Session) error {
+	sess.mu.RLock()
+	flashes := make(map[string]cookieFlash, len(sess.flashes))
+	for key, v := range sess.flashes {
+		flashes[key] = cookieFlash{Value: v.value, Read: v.shouldRemove}
+	}
+	sess.mu.RUnlock()
+
+	db.flashes = flashes
+	return db.write()
+}
+
+// setCookies removes the previous session cookies of the response and adds the "values" ones,
+// the rest cookies of the request's session are deleted.
+func (db *cookieDB) setCookies(values []string) {
+	name := db.sessions.config.Cookie
+
+	header := db.ctx.ResponseWriter().Header()
+	setCookies := header[setCookieHeaderKey][:0]
+	for _, setCookie := range header[setCookieHeaderKey] {
+		if idx := strings.IndexByte(setCookie, '='); idx > 0 && isChunkName(name, setCookie[0:idx]) {
+			continue
+		}
+
+		setCookies = append(setCookies, setCookie)
+	}
+	header[setCookieHeaderKey] = setCookies
+
+	for i, value := range values {
+		cookie := db.sessions.newCookie(db.ctx, value, db.expires)
+		cookie.Name = chunkName(name, i)
+		for _, opt := range db.options {
+			opt(cookie)
+		}
+
+		db.ctx.SetCookie(cookie)
+	}
+
+	for i := len(values); i < db.chunks; i++ {
+		cookie := db.sessions.newCookie(db.ctx, "", -1)
+		cookie.Name = chunkName(name, i)
+		cookie.Expires = CookieExpireDelete
+		cookie.MaxAge = -1
+		for _, opt := range db.options {
+			opt(cookie)
+		}
+
+		db.ctx.SetCookie(cookie)
+	}
+}
+
+const (
+	setCookieHeaderKey    = "Set-Cookie"
+	contextCookieStoreKey = "_iris_session_cookie_store_"
+)
+
+// startCookieStore creates or retrieves the client-side session of the request.
+func (s *Sessions) startCookieStore(ctx context.Context, cookieOptions ...context.CookieOption) *Session {
+	key := contextCookieStoreKey + s.config.Cookie
+	if v := ctx.Values().Get(key); v != nil {
+		if sess, ok := v.(*Session); ok {
+			db := sess.provider.db.(*cookieDB)
+			if !db.released {
+				return sess
+			}
+
+			// a destroyed session is replaced by a new one,
+			// which is not sent to the client until it's modified.
+			sess = s.newCookieStoreSession(ctx, s.config.SessionIDGenerator(ctx), cookieSession{}, db.chunks, cookieOptions)
+			sess.isNew = true
+			ctx.Values().Set(key, sess)
+			return sess
+		}
+	}
+
+	var (
+		value  strings.Builder
+		chunks int
+	)
+	for ; ; chunks++ {
+		cookie, err := ctx.Request().Cookie(chunkName(s.config.Cookie, chunks))
+		if err != nil {
+			break
+		}
+
+		value.WriteString(cookie.Value)
+	}
+
+	var sess *Session
+	stored, err := s.config.CookieStore.decode(s.config.Cookie, value.String())
+	if err != nil || stored.ID == "" || (!stored.Expires.IsZero() && stored.Expires.Before(time.Now())) {
+		var expires time.Time
+		if s.config.Expires > 0 {
+			expires = time.Now().Add(s.config.Expires)
+		}
+
+		sess = s.newCookieStoreSession(ctx, s.config.SessionIDGenerator(ctx), cookieSession{Expires: expires}, chunks, cookieOptions)
+		sess.isNew = true
+		sess.handleErr(sess.provider.db.(*cookieDB).write())
+	} else {
+		sess = s.newCookieStoreSession(ctx, stored.ID, stored, chunks, cookieOptions)
+	}
+
+	ctx.Values().Set(key, sess)
+	return sess
+}
+
+func (s *Sessions) newCookieStoreSession(ctx context.Context, sid string, stored cookieSession, chunks int, cookieOptions []context.CookieOption) *Session {
+	db := &cookieDB{
+		store:    s.config.CookieStore,
+		sessions: s,
+		ctx:      ctx,
+		options:  cookieOptions,
+		sid:      sid,
+		values:   stored.Values,
+		flashes:  stored.Flashes,
+		expires:  s.config.Expires,
+		deadline: stored.Expires,
+		chunks:   chunks,
+	}
+
+	if !stored.Expires.IsZero() {
+		db.expires = time.Until(stored.Expires)
+	}
+
+	p := newProvider()
+	p.db = db
+	p.destroyListeners = s.provider.destroyListeners
+	p.regenerateListeners = s.provider.regenerateListeners
+
+	sess := &Session{sessionEntry: &sessionEntry{
+		sid:      sid,
+		provider: p,
+		flashes:  make(map[string]*flashMessage),
+		Lifetime: LifeTime{Time: db.deadline},
+	}}
+	p.sessions[sid] = sess
+	sess = sess.forRequest(ctx, s.errKey())
+
+	if len(stored.Flashes) > 0 {
+		for key, v := range stored.Flashes {
+			sess.flashes[key] = &flashMessage{value: v.Value, shouldRemove: v.Read}
+		}
+
+		// remove the messages which were fetched by the previous request.
+		sess.runFlashGC()
+		if len(sess.flashes) != len(stored.Flashes) {
+			sess.flashesChanged()
+		}
+	}
+
+	return sess
+}
+
+// updateCookieStoreExpiration re-sends the client-side session of the request with a new expiration.
+func (s *Sessions) updateCookieStoreExpiration(ctx context.Context, expires time.Duration, cookieOptions ...context.CookieOption) error {
+	if GetCookie(ctx, s.config.Cookie) == "" && ctx.Values().Get(contextCookieStoreKey+s.config.Cookie) == nil {
+		return ErrNotFound
+	}
+
+	sess := s.startCookieStore(ctx, cookieOptions...)
+	db := sess.provider.db.(*cookieDB)
+	if len(cookieOptions) > 0 {
+		db.options = cookieOptions
+	}
+
+	if err := db.OnUpdateExpiration(ctx.Request().Context(), sess.sid, expires); err != nil {
+		return err
+	}
+
+	sess.Lifetime.Time = db.deadline
+	return nil
+}
+
+// destroyCookieStore removes the client-side session of the request.
+func (s *Sessions) destroyCookieStore(ctx context.Context) {
+	if v := ctx.Values().Get(contextCookieStoreKey + s.config.Cookie); v != nil {
+		if sess, ok := v.(*Session); ok && sess.provider.db.(*cookieDB).released {
+			return // already destroyed.
+		}
+	} else if GetCookie(ctx, s.config.Cookie) == "" {
+		return // nothing to destroy.
+	}
+
+	if err := s.startCookieStore(ctx).DestroyContext(ctx.Request().Context()); err != nil {
+		s.handleError(ctx, err)
+	}
+}
+
+func (s *Sessions) validateCookieStore() {
+	store, err := s.config.CookieStore.validate()
+	if err != nil {
+		panic(fmt.Sprintf("sessions: %v", err))
+	}
+
+	s.config.CookieStore = store
+}
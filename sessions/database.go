@@ -1,7 +1,9 @@
 package sessions
 
 import (
+	stdContext "context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,10 +15,9 @@ import (
 var ErrNotImplemented = errors.New("not implemented yet")
 
 // Database is the interface which all session databases should implement
-// By design it doesn't support any type of cookie session like other frameworks.
-// I want to protect you, believe me.
 // The scope of the database is to store somewhere the sessions in order to
 // keep them after restarting the server, nothing more.
+// Use the `Config.CookieStore` to keep the sessions on the client-side instead.
 //
 // Synchronization are made automatically, you can register one using `UseDatabase`.
 //
@@ -52,14 +53,314 @@ type Database interface {
 	Release(sid string)
 }
 
+// DatabaseV2 is the context-aware version of the `Database` interface,
+// its methods return the errors of the underline storage, i.e. a network failure,
+// so they can be reported to the callers instead of being lost.
+//
+// The errors of the `Session` methods are available through the `Session.Err`
+// and its "Context"-suffixed methods, i.e. `Session.SetContext`.
+// The `Sessions.Handler` reports them to the `Config.OnError` hook.
+//
+// Register one using `UseDatabaseV2`, the `Database` implementations are adapted through `AdaptDatabase`.
+type DatabaseV2 interface {
+	// Acquire receives a session's lifetime from the database,
+	// if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
+	Acquire(ctx stdContext.Context, sid string, expires time.Duration) (LifeTime, error)
+	// OnUpdateExpiration should re-set the expiration (ttl) of the session entry inside the database,
+	// it is fired on `ShiftExpiration` and `UpdateExpiration`.
+	// If a database does not support this feature then an `ErrNotImplemented` will be returned instead.
+	OnUpdateExpiration(ctx stdContext.Context, sid string, newExpires time.Duration) error
+	// Set sets a key value of a specific session.
+	// The "immutable" input argument depends on the store, it may not implement it at all.
+	Set(ctx stdContext.Context, sid string, lifetime LifeTime, key string, value interface{}, immutable bool) error
+	// Get retrieves a session value based on the key.
+	// A missing key should return a nil value and a nil error.
+	Get(ctx stdContext.Context, sid string, key string) (interface{}, error)
+	// Visit loops through all session keys and values.
+	Visit(ctx stdContext.Context, sid string, cb func(key string, value interface{})) error
+	// Len returns the length of the session's entries (keys).
+	Len(ctx stdContext.Context, sid string) (int, error)
+	// Delete removes a session key value based on its key.
+	Delete(ctx stdContext.Context, sid string, key string) (deleted bool, err error)
+	// Clear removes all session key values but it keeps the session entry.
+	Clear(ctx stdContext.Context, sid string) error
+	// Release destroys the session, it clears and removes the session entry,
+	// session manager will create a new session ID on the next request after this call.
+	Release(ctx stdContext.Context, sid string) error
+}
+
+// Regenerator can be implemented by a `Database` in order to move the entries of a session
+// to a new session id natively, see `Sessions.Regenerate`.
+// The entries are moved through the `Database` methods otherwise,
+// which do not preserve the immutable flag of an entry.
+type Regenerator interface {
+	Regenerate(oldSID, newSID string) error
+}
+
+// RegeneratorV2 same as `Regenerator` but for a `DatabaseV2`.
+type RegeneratorV2 interface {
+	Regenerate(ctx stdContext.Context, oldSID, newSID string) error
+}
+
+// Lister can be implemented by a `Database` in order to list its sessions, see `Sessions.VisitSessions`.
+// The sessions of the current process are listed otherwise.
+// The "cb" returns false to stop the iteration.
+type Lister interface {
+	VisitSessions(cb func(sid string, lifetime LifeTime) bool) error
+}
+
+// ListerV2 same as `Lister` but for a `DatabaseV2`.
+type ListerV2 interface {
+	VisitSessions(ctx stdContext.Context, cb func(sid string, lifetime LifeTime) bool) error
+}
+
+// Indexer can be implemented by a `DatabaseV2` in order to keep a set of sessions
+// for each indexed value of an entry, i.e. the sessions of a user ID, see `Sessions.Index`.
+// The index entries of a session should be moved on `Regenerate` and removed on `Release`.
+// The "value" is the string form of the entry's value.
+type Indexer interface {
+	// Index adds the "sid" session to the sessions of the "key" with the "value".
+	Index(ctx stdContext.Context, sid, key, value string) error
+	// Unindex removes the "sid" session from the sessions of the "key" with the "value".
+	Unindex(ctx stdContext.Context, sid, key, value string) error
+	// VisitIndex loops through the sessions of the "key" with the "value" and their lifetime,
+	// the "cb" returns false to stop the iteration.
+	VisitIndex(ctx stdContext.Context, key, value string, cb func(sid string, lifetime LifeTime) bool) error
+}
+
+// regenerate moves the entries of the "oldSID" session to the "newSID" one
+// through the "db" methods, the "lifetime" is the lifetime of the "oldSID" session.
+func regenerate(ctx stdContext.Context, db DatabaseV2, oldSID, newSID string, lifetime LifeTime) error {
+	if r, ok := db.(RegeneratorV2); ok {
+		if err := r.Regenerate(ctx, oldSID, newSID); err != errNotRegenerator {
+			return err
+		}
+	}
+
+	var expires time.Duration
+	if !lifetime.IsZero() {
+		if expires = lifetime.DurationUntilExpiration(); expires <= 0 {
+			return ErrNotFound // expired.
+		}
+	}
+
+	if _, err := db.Acquire(ctx, newSID, expires); err != nil {
+		return err
+	}
+
+	var (
+		keys   []string
+		values []interface{}
+	)
+	err := db.Visit(ctx, oldSID, func(key string, value interface{}) {
+		keys = append(keys, key)
+		values = append(values, value)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		if err = db.Set(ctx, newSID, lifetime, key, values[i], false); err != nil {
+			return err
+		}
+	}
+
+	return db.Release(ctx, oldSID)
+}
+
+// AdaptDatabase returns a `DatabaseV2` of a `Database`.
+// The `Database` methods do not report any errors,
+// the adapter returns only the errors of the canceled or expired contexts.
+func AdaptDatabase(db Database) DatabaseV2 {
+	return &databaseAdapter{db}
+}
+
+type databaseAdapter struct {
+	db Database
+}
+
+var (
+	_ DatabaseV2    = (*databaseAdapter)(nil)
+	_ RegeneratorV2 = (*databaseAdapter)(nil)
+	_ ListerV2      = (*databaseAdapter)(nil)
+	_ Indexer       = (*databaseAdapter)(nil)
+)
+
+func (a *databaseAdapter) Acquire(ctx stdContext.Context, sid string, expires time.Duration) (LifeTime, error) {
+	if err := ctx.Err(); err != nil {
+		return LifeTime{}, err
+	}
+
+	return a.db.Acquire(sid, expires), nil
+}
+
+func (a *databaseAdapter) OnUpdateExpiration(ctx stdContext.Context, sid string, newExpires time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return a.db.OnUpdateExpiration(sid, newExpires)
+}
+
+func (a *databaseAdapter) Set(ctx stdContext.Context, sid string, lifetime LifeTime, key string, value interface{}, immutable bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	a.db.Set(sid, lifetime, key, value, immutable)
+	return nil
+}
+
+func (a *databaseAdapter) Get(ctx stdContext.Context, sid string, key string) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.db.Get(sid, key), nil
+}
+
+func (a *databaseAdapter) Visit(ctx stdContext.Context, sid string, cb func(key string, value interface{})) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	a.db.Visit(sid, cb)
+	return nil
+}
+
+func (a *databaseAdapter) Len(ctx stdContext.Context, sid string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return a.db.Len(sid), nil
+}
+
+func (a *databaseAdapter) Delete(ctx stdContext.Context, sid string, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	return a.db.Delete(sid, key), nil
+}
+
+func (a *databaseAdapter) Clear(ctx stdContext.Context, sid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	a.db.Clear(sid)
+	return nil
+}
+
+func (a *databaseAdapter) Release(ctx stdContext.Context, sid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	a.db.Release(sid)
+	return nil
+}
+
+func (a *databaseAdapter) Regenerate(ctx stdContext.Context, oldSID, newSID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r, ok := a.db.(Regenerator)
+	if !ok {
+		return errNotRegenerator
+	}
+
+	return r.Regenerate(oldSID, newSID)
+}
+
+var errNotRegenerator = errors.New("database does not implement the Regenerator")
+
+func (a *databaseAdapter) VisitSessions(ctx stdContext.Context, cb func(sid string, lifetime LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l, ok := a.db.(Lister)
+	if !ok {
+		return errNotLister
+	}
+
+	return l.VisitSessions(cb)
+}
+
+var errNotLister = errors.New("database does not implement the Lister")
+
+// Index calls the `Indexer.Index` of the `Database` if it implements the `Indexer`.
+func (a *databaseAdapter) Index(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ix, ok := a.db.(Indexer)
+	if !ok {
+		return errNotIndexer
+	}
+
+	return ix.Index(ctx, sid, key, value)
+}
+
+func (a *databaseAdapter) Unindex(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ix, ok := a.db.(Indexer)
+	if !ok {
+		return errNotIndexer
+	}
+
+	return ix.Unindex(ctx, sid, key, value)
+}
+
+func (a *databaseAdapter) VisitIndex(ctx stdContext.Context, key, value string, cb func(sid string, lifetime LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ix, ok := a.db.(Indexer)
+	if !ok {
+		return errNotIndexer
+	}
+
+	return ix.VisitIndex(ctx, key, value, cb)
+}
+
+var errNotIndexer = errors.New("database does not implement the Indexer")
+
 type mem struct {
 	values map[string]*memstore.Store
-	mu     sync.RWMutex
+	// the session ids of each index name, see `indexName`,
+	// and the index names of each session id.
+	index   map[string]map[string]struct{}
+	indexed map[string]map[string]struct{}
+	mu      sync.RWMutex
 }
 
-var _ Database = (*mem)(nil)
+var (
+	_ Database    = (*mem)(nil)
+	_ Regenerator = (*mem)(nil)
+	_ Indexer     = (*mem)(nil)
+)
+
+func newMemDB() DatabaseV2 {
+	return AdaptDatabase(&mem{
+		values:  make(map[string]*memstore.Store),
+		index:   make(map[string]map[string]struct{}),
+		indexed: make(map[string]map[string]struct{}),
+	})
+}
 
-func newMemDB() Database { return &mem{values: make(map[string]*memstore.Store)} }
+func indexName(key, value string) string {
+	return key + "\x00" + value
+}
 
 func (s *mem) Acquire(sid string, expires time.Duration) LifeTime {
 	s.mu.Lock()
@@ -114,5 +415,99 @@ func (s *mem) Clear(sid string) {
 func (s *mem) Release(sid string) {
 	s.mu.Lock()
 	delete(s.values, sid)
+	for name := range s.indexed[sid] {
+		s.removeIndex(name, sid)
+	}
+	delete(s.indexed, sid)
+	s.mu.Unlock()
+}
+
+func (s *mem) Regenerate(oldSID, newSID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, ok := s.values[oldSID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	s.values[newSID] = store
+	delete(s.values, oldSID)
+
+	if names, ok := s.indexed[oldSID]; ok {
+		for name := range names {
+			sids := s.index[name]
+			delete(sids, oldSID)
+			sids[newSID] = struct{}{}
+		}
+
+		s.indexed[newSID] = names
+		delete(s.indexed, oldSID)
+	}
+
+	return nil
+}
+
+func (s *mem) Index(ctx stdContext.Context, sid, key, value string) error {
+	name := indexName(key, value)
+
+	s.mu.Lock()
+	if s.index[name] == nil {
+		s.index[name] = make(map[string]struct{})
+	}
+	s.index[name][sid] = struct{}{}
+
+	if s.indexed[sid] == nil {
+		s.indexed[sid] = make(map[string]struct{})
+	}
+	s.indexed[sid][name] = struct{}{}
 	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *mem) Unindex(ctx stdContext.Context, sid, key, value string) error {
+	name := indexName(key, value)
+
+	s.mu.Lock()
+	s.removeIndex(name, sid)
+	if names, ok := s.indexed[sid]; ok {
+		delete(names, name)
+		if len(names) == 0 {
+			delete(s.indexed, sid)
+		}
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// removeIndex removes the "sid" from the sessions of the "name" index, it should be called under lock.
+func (s *mem) removeIndex(name, sid string) {
+	if sids, ok := s.index[name]; ok {
+		delete(sids, sid)
+		if len(sids) == 0 {
+			delete(s.index, name)
+		}
+	}
+}
+
+// VisitIndex loops through the sessions of the index, their lifetime
+// is managed by the callers on memory-based storage, so it's empty.
+func (s *mem) VisitIndex(ctx stdContext.Context, key, value string, cb func(sid string, lifetime LifeTime) bool) error {
+	s.mu.RLock()
+	sids := make([]string, 0, len(s.index[indexName(key, value)]))
+	for sid := range s.index[indexName(key, value)] {
+		sids = append(sids, sid)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(sids)
+	for _, sid := range sids {
+		if !cb(sid, LifeTime{}) {
+			break
+		}
+	}
+
+	return nil
 }
@@ -1,7 +1,10 @@
 package sessions
 
 import (
+	stdContext "context"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -13,10 +16,11 @@ type (
 		// we don't use RWMutex because all actions have read and write at the same action function.
 		// (or write to a *Session's value which is race if we don't lock)
 		// narrow locks are fasters but are useless here.
-		mu               sync.Mutex
-		sessions         map[string]*Session
-		db               Database
-		destroyListeners []DestroyListener
+		mu                  sync.Mutex
+		sessions            map[string]*Session
+		db                  DatabaseV2
+		destroyListeners    []DestroyListener
+		regenerateListeners []RegenerateListener
 	}
 )
 
@@ -29,19 +33,26 @@ func newProvider() *provider {
 }
 
 // RegisterDatabase sets a session database.
-func (p *provider) RegisterDatabase(db Database) {
+func (p *provider) RegisterDatabase(db DatabaseV2) {
 	p.mu.Lock() // for any case
 	p.db = db
 	p.mu.Unlock()
 }
 
 // newSession returns a new session from sessionid
-func (p *provider) newSession(sid string, expires time.Duration) *Session {
+// and the error of the session database, if any.
+func (p *provider) newSession(ctx stdContext.Context, sid string, expires time.Duration) (*Session, error) {
+	var sess *Session
 	onExpire := func() {
-		p.Destroy(sid)
+		// the session id may be changed by `Regenerate`.
+		p.mu.Lock()
+		if current, ok := p.sessions[sess.sid]; ok && current.sessionEntry == sess.sessionEntry {
+			p.deleteSession(stdContext.Background(), sess)
+		}
+		p.mu.Unlock()
 	}
 
-	lifetime := p.db.Acquire(sid, expires)
+	lifetime, err := p.db.Acquire(ctx, sid, expires)
 
 	// simple and straight:
 	if !lifetime.IsZero() {
@@ -58,31 +69,33 @@ func (p *provider) newSession(sid string, expires time.Duration) *Session {
 		lifetime.Begin(expires, onExpire)
 	}
 
-	sess := &Session{
-		sid:      sid,
-		provider: p,
-		flashes:  make(map[string]*flashMessage),
-		Lifetime: lifetime,
-	}
+	sess = &Session{sessionEntry: &sessionEntry{
+		sid:        sid,
+		provider:   p,
+		flashes:    make(map[string]*flashMessage),
+		Lifetime:   lifetime,
+		lastAccess: time.Now(),
+	}}
 
-	return sess
+	return sess, err
 }
 
-// Init creates the session  and returns it
-func (p *provider) Init(sid string, expires time.Duration) *Session {
-	newSession := p.newSession(sid, expires)
+// Init creates the session and returns it with the error of the session database, if any.
+func (p *provider) Init(ctx stdContext.Context, sid string, expires time.Duration) (*Session, error) {
+	newSession, err := p.newSession(ctx, sid, expires)
 	p.mu.Lock()
 	p.sessions[sid] = newSession
 	p.mu.Unlock()
-	return newSession
+	return newSession, err
 }
 
 // ErrNotFound may be returned from `UpdateExpiration` of a non-existing or
 // invalid session entry from memory storage or databases.
 // Usage:
-// if err != nil && err.Is(err, sessions.ErrNotFound) {
-//     [handle error...]
-// }
+//
+//	if err != nil && err.Is(err, sessions.ErrNotFound) {
+//	    [handle error...]
+//	}
 var ErrNotFound = errors.New("session not found")
 
 // UpdateExpiration resets the expiration of a session.
@@ -93,7 +106,7 @@ var ErrNotFound = errors.New("session not found")
 // because the call of the provider's `UpdateExpiration` is always called when the client has a valid session cookie.
 //
 // If a backend database is used then it may return an `ErrNotImplemented` error if the underline database does not support this operation.
-func (p *provider) UpdateExpiration(sid string, expires time.Duration) error {
+func (p *provider) UpdateExpiration(ctx stdContext.Context, sid string, expires time.Duration) error {
 	if expires <= 0 {
 		return nil
 	}
@@ -106,21 +119,59 @@ func (p *provider) UpdateExpiration(sid string, expires time.Duration) error {
 	}
 
 	sess.Lifetime.Shift(expires)
-	return p.db.OnUpdateExpiration(sid, expires)
+	return p.db.OnUpdateExpiration(ctx, sid, expires)
 }
 
 // Read returns the store which sid parameter belongs
-func (p *provider) Read(sid string, expires time.Duration) *Session {
+// and the error of the session database, if any.
+func (p *provider) Read(ctx stdContext.Context, sid string, expires time.Duration) (*Session, error) {
 	p.mu.Lock()
 	if sess, found := p.sessions[sid]; found {
 		sess.runFlashGC() // run the flash messages GC, new request here of existing session
+		sess.access()
 		p.mu.Unlock()
 
-		return sess
+		return sess, nil
+	}
+	p.mu.Unlock()
+
+	return p.Init(ctx, sid, expires) // if not found create new
+}
+
+// Regenerate moves the "sess" entries to the "newSID" session id,
+// the "sess" is modified in place so its flash messages are kept too.
+// The database is called without holding the provider's lock,
+// so a slow database does not block the rest of the sessions.
+func (p *provider) Regenerate(ctx stdContext.Context, sess *Session, newSID string) error {
+	p.mu.Lock()
+	db, oldSID := p.db, sess.sid
+	p.mu.Unlock()
+
+	if err := regenerate(ctx, db, oldSID, newSID, sess.Lifetime); err != nil {
+		return err
 	}
+
+	p.mu.Lock()
+	delete(p.sessions, oldSID)
+	sess.sid = newSID
+	p.sessions[newSID] = &Session{sessionEntry: sess.sessionEntry}
 	p.mu.Unlock()
 
-	return p.Init(sid, expires) // if not found create new
+	p.fireRegenerate(oldSID, newSID)
+	return nil
+}
+
+func (p *provider) registerRegenerateListener(ln RegenerateListener) {
+	if ln == nil {
+		return
+	}
+	p.regenerateListeners = append(p.regenerateListeners, ln)
+}
+
+func (p *provider) fireRegenerate(oldSID, newSID string) {
+	for _, ln := range p.regenerateListeners {
+		ln(oldSID, newSID)
+	}
 }
 
 func (p *provider) registerDestroyListener(ln DestroyListener) {
@@ -139,12 +190,18 @@ func (p *provider) fireDestroy(sid string) {
 // Destroy destroys the session, removes all sessions and flash values,
 // the session itself and updates the registered session databases,
 // this called from sessionManager which removes the client's cookie also.
-func (p *provider) Destroy(sid string) {
+//
+// A session which is not started by this process, i.e. a database one after a restart,
+// is removed from the database without firing the destroy listeners.
+func (p *provider) Destroy(ctx stdContext.Context, sid string) (err error) {
 	p.mu.Lock()
 	if sess, found := p.sessions[sid]; found {
-		p.deleteSession(sess)
+		err = p.deleteSession(ctx, sess)
+	} else {
+		err = p.db.Release(ctx, sid)
 	}
 	p.mu.Unlock()
+	return
 }
 
 // DestroyAll removes all sessions
@@ -153,15 +210,154 @@ func (p *provider) Destroy(sid string) {
 func (p *provider) DestroyAll() {
 	p.mu.Lock()
 	for _, sess := range p.sessions {
-		p.deleteSession(sess)
+		p.deleteSession(stdContext.Background(), sess)
 	}
 	p.mu.Unlock()
 }
 
-func (p *provider) deleteSession(sess *Session) {
+// VisitSessions calls the "cb" with the information of the non-expired sessions,
+// the sessions are listed by the database if it's a `ListerV2`,
+// otherwise the sessions started by this process are listed.
+func (p *provider) VisitSessions(ctx stdContext.Context, cb func(SessionInfo) bool) error {
+	db, started := p.startedSessions()
+
+	var (
+		infos  []SessionInfo
+		listed bool
+	)
+	if l, ok := db.(ListerV2); ok {
+		err := l.VisitSessions(ctx, func(sid string, lifetime LifeTime) bool {
+			info, found := started[sid]
+			if !found {
+				info = SessionInfo{ID: sid, Expires: lifetime.Time}
+			}
+			infos = append(infos, info)
+			return true
+		})
+
+		switch {
+		case err == nil:
+			listed = true
+		case err != errNotLister:
+			return err
+		}
+	}
+
+	if !listed {
+		infos = infos[:0]
+		for _, info := range started {
+			infos = append(infos, info)
+		}
+		sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	}
+
+	return visitSessionInfos(infos, cb)
+}
+
+// startedSessions returns the database and the information of the sessions started by this process.
+func (p *provider) startedSessions() (DatabaseV2, map[string]SessionInfo) {
+	p.mu.Lock()
+	db := p.db
+	started := make(map[string]SessionInfo, len(p.sessions))
+	for sid, sess := range p.sessions {
+		started[sid] = SessionInfo{ID: sid, Expires: sess.Lifetime.Time, LastAccess: sess.LastAccess()}
+	}
+	p.mu.Unlock()
+
+	return db, started
+}
+
+// Index adds the "sid" session to the sessions of the "key" with the "value"
+// of the database, it returns ErrNotImplemented if the database is not an `Indexer`.
+func (p *provider) Index(ctx stdContext.Context, sid, key, value string) error {
+	p.mu.Lock()
+	db := p.db
+	p.mu.Unlock()
+
+	ix, ok := db.(Indexer)
+	if !ok {
+		return ErrNotImplemented
+	}
+
+	if err := ix.Index(ctx, sid, key, value); err != errNotIndexer {
+		return err
+	}
+
+	return ErrNotImplemented
+}
+
+// Find returns the information of the non-expired sessions of the "key" with the "value"
+// through the index of the database, it returns errNotIndexer if the database is not an `Indexer`.
+// The sessions whose entry does not hold the "value" anymore are removed from the index.
+func (p *provider) Find(ctx stdContext.Context, key, value string) ([]SessionInfo, error) {
+	db, started := p.startedSessions()
+
+	ix, ok := db.(Indexer)
+	if !ok {
+		return nil, errNotIndexer
+	}
+
+	var infos []SessionInfo
+	err := ix.VisitIndex(ctx, key, value, func(sid string, lifetime LifeTime) bool {
+		info, found := started[sid]
+		if !found {
+			info = SessionInfo{ID: sid, Expires: lifetime.Time}
+		}
+		infos = append(infos, info)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	found := infos[:0]
+	for _, info := range infos {
+		if !info.Expires.IsZero() && !info.Expires.After(now) {
+			continue
+		}
+
+		v, err := db.Get(ctx, info.ID, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if v == nil || fmt.Sprint(v) != value {
+			// the entry was changed or removed after it was indexed.
+			if err = ix.Unindex(ctx, info.ID, key, value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		found = append(found, info)
+	}
+
+	return found, nil
+}
+
+// visitSessionInfos calls the "cb" with the non-expired "infos",
+// they are collected before so the "cb" can use the provider.
+func visitSessionInfos(infos []SessionInfo, cb func(SessionInfo) bool) error {
+	now := time.Now()
+	for _, info := range infos {
+		if !info.Expires.IsZero() && !info.Expires.After(now) {
+			continue
+		}
+
+		if !cb(info) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (p *provider) deleteSession(ctx stdContext.Context, sess *Session) error {
 	sid := sess.sid
 
 	delete(p.sessions, sid)
-	p.db.Release(sid)
+	err := p.db.Release(ctx, sid)
 	p.fireDestroy(sid)
+	return err
 }
@@ -1,10 +1,13 @@
 package sessions
 
 import (
+	stdContext "context"
 	"reflect"
 	"strconv"
 	"sync"
+	"time"
 
+	"github.com/kataras/iris/v12/context"
 	"github.com/kataras/iris/v12/core/memstore"
 )
 
@@ -15,12 +18,24 @@ type (
 	//
 	// This is what will be returned when sess := sessions.Start().
 	Session struct {
+		*sessionEntry
+
+		// the request which started the session,
+		// its database errors are kept in the request's values under the errKey.
+		ctx    context.Context
+		errKey string
+	}
+
+	// sessionEntry is the state of a session, shared by the requests of the same session id.
+	sessionEntry struct {
 		sid      string
 		isNew    bool
 		flashes  map[string]*flashMessage
 		mu       sync.RWMutex // for flashes.
 		Lifetime LifeTime
 		provider *provider
+
+		lastAccess time.Time
 	}
 
 	flashMessage struct {
@@ -39,7 +54,41 @@ type (
 //
 // Use the session's manager `Destroy(ctx)` in order to remove the cookie as well.
 func (s *Session) Destroy() {
-	s.provider.deleteSession(s)
+	s.handleErr(s.DestroyContext(stdContext.Background()))
+}
+
+// DestroyContext same as `Destroy` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) DestroyContext(ctx stdContext.Context) error {
+	return s.provider.deleteSession(ctx, s)
+}
+
+// Err returns the last error of the session database, if any,
+// of the current request. The error is kept in the request's values,
+// so the concurrent requests of the same session do not see each other's errors.
+// The "Context"-suffixed methods return their errors instead, i.e. `SetContext`.
+//
+// The `Sessions.Handler` reports it to the `Config.OnError`.
+func (s *Session) Err() error {
+	if s.ctx == nil {
+		return nil
+	}
+
+	err, _ := s.ctx.Values().Get(s.errKey).(error)
+	return err
+}
+
+// handleErr records a database error of the methods which do not return it to the request's values.
+func (s *Session) handleErr(err error) {
+	if err != nil && s.ctx != nil {
+		s.ctx.Values().Set(s.errKey, err)
+	}
+}
+
+// forRequest returns the session of the "ctx" request,
+// which keeps its database errors under the "errKey" of the request's values.
+func (s *Session) forRequest(ctx context.Context, errKey string) *Session {
+	return &Session{sessionEntry: s.sessionEntry, ctx: ctx, errKey: errKey}
 }
 
 // ID returns the session's ID.
@@ -47,6 +96,22 @@ func (s *Session) ID() string {
 	return s.sid
 }
 
+// LastAccess returns the time that the session was last started by a request of this process.
+// It's kept in memory only, it is not stored to the session database,
+// so it's reset after a restart and it's not shared between the instances of a load-balanced application.
+func (s *Session) LastAccess() time.Time {
+	s.mu.RLock()
+	t := s.lastAccess
+	s.mu.RUnlock()
+	return t
+}
+
+func (s *Session) access() {
+	s.mu.Lock()
+	s.lastAccess = time.Now()
+	s.mu.Unlock()
+}
+
 // IsNew returns true if this session is
 // created by the current application's process.
 func (s *Session) IsNew() bool {
@@ -55,7 +120,15 @@ func (s *Session) IsNew() bool {
 
 // Get returns a value based on its "key".
 func (s *Session) Get(key string) interface{} {
-	return s.provider.db.Get(s.sid, key)
+	value, err := s.GetContext(stdContext.Background(), key)
+	s.handleErr(err)
+	return value
+}
+
+// GetContext same as `Get` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) GetContext(ctx stdContext.Context, key string) (interface{}, error) {
+	return s.provider.db.Get(ctx, s.sid, key)
 }
 
 // when running on the session manager removes any 'old' flash messages.
@@ -91,7 +164,11 @@ func (s *Session) GetFlash(key string) interface{} {
 	if !ok {
 		return nil
 	}
-	fv.shouldRemove = true
+
+	if !fv.shouldRemove {
+		fv.shouldRemove = true
+		s.flashesChanged()
+	}
 	return fv.value
 }
 
@@ -432,49 +509,86 @@ func (s *Session) GetBooleanDefault(key string, defaultValue bool) bool {
 
 // GetAll returns a copy of all session's values.
 func (s *Session) GetAll() map[string]interface{} {
-	items := make(map[string]interface{}, s.provider.db.Len(s.sid))
+	items, err := s.GetAllContext(stdContext.Background())
+	s.handleErr(err)
+	return items
+}
+
+// GetAllContext same as `GetAll` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) GetAllContext(ctx stdContext.Context) (map[string]interface{}, error) {
+	items := make(map[string]interface{})
 	s.mu.RLock()
-	s.provider.db.Visit(s.sid, func(key string, value interface{}) {
+	err := s.provider.db.Visit(ctx, s.sid, func(key string, value interface{}) {
 		items[key] = value
 	})
 	s.mu.RUnlock()
-	return items
+	return items, err
 }
 
 // GetFlashes returns all flash messages as map[string](key) and interface{} value
 // NOTE: this will cause at remove all current flash messages on the next request of the same user.
 func (s *Session) GetFlashes() map[string]interface{} {
 	flashes := make(map[string]interface{}, len(s.flashes))
+	changed := false
 	s.mu.Lock()
 	for key, v := range s.flashes {
 		flashes[key] = v.value
+		changed = changed || !v.shouldRemove
 		v.shouldRemove = true
 	}
 	s.mu.Unlock()
+
+	if changed {
+		s.flashesChanged()
+	}
 	return flashes
 }
 
 // Visit loops each of the entries and calls the callback function func(key, value).
 func (s *Session) Visit(cb func(k string, v interface{})) {
-	s.provider.db.Visit(s.sid, cb)
+	s.handleErr(s.VisitContext(stdContext.Background(), cb))
+}
+
+// VisitContext same as `Visit` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) VisitContext(ctx stdContext.Context, cb func(k string, v interface{})) error {
+	return s.provider.db.Visit(ctx, s.sid, cb)
 }
 
 // Len returns the total number of stored values in this session.
 func (s *Session) Len() int {
-	return s.provider.db.Len(s.sid)
+	n, err := s.LenContext(stdContext.Background())
+	s.handleErr(err)
+	return n
 }
 
-func (s *Session) set(key string, value interface{}, immutable bool) {
-	s.provider.db.Set(s.sid, s.Lifetime, key, value, immutable)
+// LenContext same as `Len` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) LenContext(ctx stdContext.Context) (int, error) {
+	return s.provider.db.Len(ctx, s.sid)
+}
+
+func (s *Session) set(ctx stdContext.Context, key string, value interface{}, immutable bool) error {
+	if err := s.provider.db.Set(ctx, s.sid, s.Lifetime, key, value, immutable); err != nil {
+		return err
+	}
 
 	s.mu.Lock()
 	s.isNew = false
 	s.mu.Unlock()
+	return nil
 }
 
 // Set fills the session with an entry "value", based on its "key".
 func (s *Session) Set(key string, value interface{}) {
-	s.set(key, value, false)
+	s.handleErr(s.set(stdContext.Background(), key, value, false))
+}
+
+// SetContext same as `Set` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) SetContext(ctx stdContext.Context, key string, value interface{}) error {
+	return s.set(ctx, key, value, false)
 }
 
 // SetImmutable fills the session with an entry "value", based on its "key".
@@ -484,7 +598,13 @@ func (s *Session) Set(key string, value interface{}) {
 // Use it consistently, it's far slower than `Set`.
 // Read more about muttable and immutable go types: https://stackoverflow.com/a/8021081
 func (s *Session) SetImmutable(key string, value interface{}) {
-	s.set(key, value, true)
+	s.handleErr(s.set(stdContext.Background(), key, value, true))
+}
+
+// SetImmutableContext same as `SetImmutable` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) SetImmutableContext(ctx stdContext.Context, key string, value interface{}) error {
+	return s.set(ctx, key, value, true)
 }
 
 // SetFlash sets a flash message by its key.
@@ -511,41 +631,77 @@ func (s *Session) SetFlash(key string, value interface{}) {
 	s.mu.Lock()
 	s.flashes[key] = &flashMessage{value: value}
 	s.mu.Unlock()
+	s.flashesChanged()
 }
 
 // Delete removes an entry by its key,
 // returns true if actually something was removed.
 func (s *Session) Delete(key string) bool {
-	removed := s.provider.db.Delete(s.sid, key)
+	removed, err := s.DeleteContext(stdContext.Background(), key)
+	s.handleErr(err)
+	return removed
+}
+
+// DeleteContext same as `Delete` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) DeleteContext(ctx stdContext.Context, key string) (bool, error) {
+	removed, err := s.provider.db.Delete(ctx, s.sid, key)
 	if removed {
 		s.mu.Lock()
 		s.isNew = false
 		s.mu.Unlock()
 	}
 
-	return removed
+	return removed, err
 }
 
 // DeleteFlash removes a flash message by its key.
 func (s *Session) DeleteFlash(key string) {
 	s.mu.Lock()
+	_, changed := s.flashes[key]
 	delete(s.flashes, key)
 	s.mu.Unlock()
+
+	if changed {
+		s.flashesChanged()
+	}
 }
 
 // Clear removes all entries.
 func (s *Session) Clear() {
+	s.handleErr(s.ClearContext(stdContext.Background()))
+}
+
+// ClearContext same as `Clear` but it accepts a context
+// and it returns the error of the session database.
+func (s *Session) ClearContext(ctx stdContext.Context) error {
 	s.mu.Lock()
-	s.provider.db.Clear(s.sid)
-	s.isNew = false
+	err := s.provider.db.Clear(ctx, s.sid)
+	if err == nil {
+		s.isNew = false
+	}
 	s.mu.Unlock()
+	return err
 }
 
 // ClearFlashes removes all flash messages.
 func (s *Session) ClearFlashes() {
 	s.mu.Lock()
+	changed := len(s.flashes) > 0
 	for key := range s.flashes {
 		delete(s.flashes, key)
 	}
 	s.mu.Unlock()
+
+	if changed {
+		s.flashesChanged()
+	}
+}
+
+// flashesChanged sends the flash messages of a `CookieStore` session to the client,
+// the server-side sessions keep them in memory.
+func (s *Session) flashesChanged() {
+	if db, ok := s.provider.db.(*cookieDB); ok {
+		s.handleErr(db.setFlashes(s))
+	}
 }
@@ -2,6 +2,7 @@ package badger
 
 import (
 	"bytes"
+	stdContext "context"
 	"errors"
 	"os"
 	"runtime"
@@ -37,7 +38,12 @@ type Database struct {
 	closed uint32 // if 1 is closed.
 }
 
-var _ sessions.Database = (*Database)(nil)
+var (
+	_ sessions.DatabaseV2    = (*Database)(nil)
+	_ sessions.RegeneratorV2 = (*Database)(nil)
+	_ sessions.ListerV2      = (*Database)(nil)
+	_ sessions.Indexer       = (*Database)(nil)
+)
 
 // New creates and returns a new badger(key-value file-based) storage
 // instance based on the "directoryPath".
@@ -80,35 +86,37 @@ func NewFromDB(service *badger.DB) *Database {
 
 // Acquire receives a session's lifetime from the database,
 // if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
-func (db *Database) Acquire(sid string, expires time.Duration) sessions.LifeTime {
-	txn := db.Service.NewTransaction(true)
-	defer txn.Commit()
-
-	bsid := makePrefix(sid)
-	item, err := txn.Get(bsid)
-	if err == nil {
-		// found, return the expiration.
-		return sessions.LifeTime{Time: time.Unix(int64(item.ExpiresAt()), 0)}
+func (db *Database) Acquire(ctx stdContext.Context, sid string, expires time.Duration) (lifetime sessions.LifeTime, err error) {
+	if err = ctx.Err(); err != nil {
+		return
 	}
 
-	// not found, create an entry with ttl and return an empty lifetime, session manager will do its job.
-	if err != nil {
-		if err == badger.ErrKeyNotFound {
-			// create it and set the expiration, we don't care about the value there.
-			err = txn.SetEntry(badger.NewEntry(bsid, bsid).WithTTL(expires))
+	err = db.Service.Update(func(txn *badger.Txn) error {
+		bsid := makePrefix(sid)
+		item, err := txn.Get(bsid)
+		if err == nil {
+			// found, return the expiration.
+			if expiresAt := item.ExpiresAt(); expiresAt > 0 {
+				lifetime.Time = time.Unix(int64(expiresAt), 0)
+			}
+			return nil
 		}
-	}
 
-	if err != nil {
-		golog.Error(err)
-	}
+		if err != badger.ErrKeyNotFound {
+			return err
+		}
 
-	return sessions.LifeTime{} // session manager will handle the rest.
+		// not found, create an entry with ttl and return an empty lifetime, session manager will do its job.
+		// We don't care about the value there.
+		return txn.SetEntry(badger.NewEntry(bsid, bsid).WithTTL(expires))
+	})
+
+	return
 }
 
 // OnUpdateExpiration not implemented here, yet.
 // Note that this error will not be logged, callers should catch it manually.
-func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) error {
+func (db *Database) OnUpdateExpiration(ctx stdContext.Context, sid string, newExpires time.Duration) error {
 	return sessions.ErrNotImplemented
 }
 
@@ -124,26 +132,29 @@ func makeKey(sid, key string) []byte {
 
 // Set sets a key value of a specific session.
 // Ignore the "immutable".
-func (db *Database) Set(sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) {
+func (db *Database) Set(ctx stdContext.Context, sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	valueBytes, err := sessions.DefaultTranscoder.Marshal(value)
 	if err != nil {
-		golog.Error(err)
-		return
+		return err
 	}
 
-	err = db.Service.Update(func(txn *badger.Txn) error {
+	return db.Service.Update(func(txn *badger.Txn) error {
 		dur := lifetime.DurationUntilExpiration()
 		return txn.SetEntry(badger.NewEntry(makeKey(sid, key), valueBytes).WithTTL(dur))
 	})
-
-	if err != nil {
-		golog.Error(err)
-	}
 }
 
 // Get retrieves a session value based on the key.
-func (db *Database) Get(sid string, key string) (value interface{}) {
-	err := db.Service.View(func(txn *badger.Txn) error {
+func (db *Database) Get(ctx stdContext.Context, sid string, key string) (value interface{}, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	err = db.Service.View(func(txn *badger.Txn) error {
 		item, err := txn.Get(makeKey(sid, key))
 		if err != nil {
 			return err
@@ -154,49 +165,53 @@ func (db *Database) Get(sid string, key string) (value interface{}) {
 		})
 	})
 
-	if err != nil && err != badger.ErrKeyNotFound {
-		golog.Error(err)
-		return nil
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
 	}
 
 	return
 }
 
-// Visit loops through all session keys and values.
-func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
+// iterate calls the "cb" with the keys of the session values, the session entry is skipped.
+func (db *Database) iterate(txn *badger.Txn, opts badger.IteratorOptions, sid string, cb func(item *badger.Item, key []byte) error) error {
 	prefix := makePrefix(sid)
 
-	txn := db.Service.NewTransaction(false)
-	defer txn.Discard()
-
-	iter := txn.NewIterator(badger.DefaultIteratorOptions)
+	iter := txn.NewIterator(opts)
 	defer iter.Close()
 
-	for iter.Rewind(); iter.ValidForPrefix(prefix); iter.Next() {
+	for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
 		item := iter.Item()
-		var value interface{}
-
-		// err := item.Value(func(valueBytes []byte) {
-		// 	if err := sessions.DefaultTranscoder.Unmarshal(valueBytes, &value); err != nil {
-		// 		golog.Error(err)
-		// 	}
-		// })
-
-		// if err != nil {
-		// 	golog.Error(err)
-		// 	continue
-		// }
+		if len(item.Key()) == len(prefix) {
+			continue // the session entry, see `Acquire`.
+		}
 
-		err := item.Value(func(valueBytes []byte) error {
-			return sessions.DefaultTranscoder.Unmarshal(valueBytes, &value)
-		})
-		if err != nil {
-			golog.Error(err)
-			continue
+		if err := cb(item, item.Key()[len(prefix):]); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
 
-		cb(string(bytes.TrimPrefix(item.Key(), prefix)), value)
+// Visit loops through all session keys and values.
+func (db *Database) Visit(ctx stdContext.Context, sid string, cb func(key string, value interface{})) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+
+	return db.Service.View(func(txn *badger.Txn) error {
+		return db.iterate(txn, badger.DefaultIteratorOptions, sid, func(item *badger.Item, key []byte) error {
+			var value interface{} // new value each time, we don't know what user will do in "cb".
+			if err := item.Value(func(valueBytes []byte) error {
+				return sessions.DefaultTranscoder.Unmarshal(valueBytes, &value)
+			}); err != nil {
+				return err
+			}
+
+			cb(string(key), value)
+			return nil
+		})
+	})
 }
 
 var iterOptionsNoValues = badger.IteratorOptions{
@@ -207,64 +222,360 @@ var iterOptionsNoValues = badger.IteratorOptions{
 }
 
 // Len returns the length of the session's entries (keys).
-func (db *Database) Len(sid string) (n int) {
-	prefix := makePrefix(sid)
-
-	txn := db.Service.NewTransaction(false)
-	iter := txn.NewIterator(iterOptionsNoValues)
-
-	for iter.Rewind(); iter.ValidForPrefix(prefix); iter.Next() {
-		n++
+func (db *Database) Len(ctx stdContext.Context, sid string) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		return
 	}
 
-	iter.Close()
-	txn.Discard()
+	err = db.Service.View(func(txn *badger.Txn) error {
+		return db.iterate(txn, iterOptionsNoValues, sid, func(*badger.Item, []byte) error {
+			n++
+			return nil
+		})
+	})
+
 	return
 }
 
 // Delete removes a session key value based on its key.
-func (db *Database) Delete(sid string, key string) (deleted bool) {
-	txn := db.Service.NewTransaction(true)
-	err := txn.Delete(makeKey(sid, key))
+func (db *Database) Delete(ctx stdContext.Context, sid string, key string) (deleted bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	err = db.Service.Update(func(txn *badger.Txn) error {
+		k := makeKey(sid, key)
+		if _, err := txn.Get(k); err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+
+		deleted = true
+		return txn.Delete(k)
+	})
+
 	if err != nil {
-		golog.Error(err)
-		return false
+		deleted = false
 	}
-	return txn.Commit() == nil
+	return
 }
 
 // Clear removes all session key values but it keeps the session entry.
-func (db *Database) Clear(sid string) {
-	prefix := makePrefix(sid)
-
-	txn := db.Service.NewTransaction(true)
-	defer txn.Commit()
+func (db *Database) Clear(ctx stdContext.Context, sid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	iter := txn.NewIterator(iterOptionsNoValues)
-	defer iter.Close()
+	return db.Service.Update(func(txn *badger.Txn) error {
+		var keys [][]byte
+		if err := db.iterate(txn, iterOptionsNoValues, sid, func(item *badger.Item, _ []byte) error {
+			keys = append(keys, item.KeyCopy(nil))
+			return nil
+		}); err != nil {
+			return err
+		}
 
-	for iter.Rewind(); iter.ValidForPrefix(prefix); iter.Next() {
-		key := iter.Item().Key()
-		if err := txn.Delete(key); err != nil {
-			golog.Warnf("Database.Clear: %s: %v", key, err)
-			continue
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
 		}
-	}
+
+		return nil
+	})
 }
 
 // Release destroys the session, it clears and removes the session entry,
 // session manager will create a new session ID on the next request after this call.
-func (db *Database) Release(sid string) {
-	// clear all $sid-$key.
-	db.Clear(sid)
-	// and remove the $sid.
-	txn := db.Service.NewTransaction(true)
-	if err := txn.Delete([]byte(sid)); err != nil {
-		golog.Warnf("Database.Release.Delete: %s: %v", sid, err)
+func (db *Database) Release(ctx stdContext.Context, sid string) error {
+	// clear all $sid_$key.
+	if err := db.Clear(ctx, sid); err != nil {
+		return err
+	}
+
+	// and remove the $sid_ and its index entries.
+	return db.Service.Update(func(txn *badger.Txn) error {
+		if err := db.unindexSession(txn, sid, ""); err != nil {
+			return err
+		}
+
+		return txn.Delete(makePrefix(sid))
+	})
+}
+
+// Regenerate moves the session entries, with their ttl, to the "newSID" session.
+func (db *Database) Regenerate(ctx stdContext.Context, oldSID, newSID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	prefix := makePrefix(oldSID)
+
+	return db.Service.Update(func(txn *badger.Txn) error {
+		var entries []*badger.Entry
+		var oldKeys [][]byte
+
+		iter := txn.NewIterator(badger.DefaultIteratorOptions)
+		for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+			item := iter.Item()
+			valueBytes, err := item.ValueCopy(nil)
+			if err != nil {
+				iter.Close()
+				return err
+			}
+
+			key := item.KeyCopy(nil)
+			if len(key) == len(prefix) {
+				valueBytes = makePrefix(newSID) // the session entry holds its own key as value.
+			}
+
+			entry := badger.NewEntry(append(makePrefix(newSID), key[len(prefix):]...), valueBytes)
+			if expiresAt := item.ExpiresAt(); expiresAt > 0 {
+				entry = entry.WithTTL(time.Until(time.Unix(int64(expiresAt), 0)))
+			}
+
+			entries = append(entries, entry)
+			oldKeys = append(oldKeys, key)
+		}
+		iter.Close()
+
+		if len(entries) == 0 {
+			return sessions.ErrNotFound
+		}
+
+		for _, entry := range entries {
+			if err := txn.SetEntry(entry); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range oldKeys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		return db.unindexSession(txn, oldSID, newSID)
+	})
+}
+
+// VisitSessions loops through the session entries and their lifetime.
+func (db *Database) VisitSessions(ctx stdContext.Context, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.View(func(txn *badger.Txn) error {
+		iter := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer iter.Close()
+
+		for iter.Rewind(); iter.Valid(); iter.Next() {
+			item := iter.Item()
+			key := item.Key()
+			if len(key) == 0 || key[len(key)-1] != delim {
+				continue
+			}
+
+			// the session entry holds its own key as value, see `Acquire`.
+			isSession := false
+			if err := item.Value(func(value []byte) error {
+				isSession = bytes.Equal(value, key)
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			if !isSession {
+				continue
+			}
+
+			var lifetime sessions.LifeTime
+			if expiresAt := item.ExpiresAt(); expiresAt > 0 {
+				lifetime.Time = time.Unix(int64(expiresAt), 0)
+			}
+
+			if !cb(string(key[:len(key)-1]), lifetime) {
+				break
+			}
+		}
+
+		return nil
+	})
+}
+
+// The index entries are kept under the "\x00index\x00$key\x00$value\x00$sid" keys
+// and the "\x00indexed\x00$sid\x00" prefixed keys of a session hold its index entry keys,
+// they expire with the session.
+var (
+	indexPrefix   = []byte("\x00index\x00")
+	indexedPrefix = []byte("\x00indexed\x00")
+)
+
+func makeIndexPrefix(key, value string) []byte {
+	return append(append([]byte{}, indexPrefix...), key+"\x00"+value+"\x00"...)
+}
+
+func makeIndexedPrefix(sid string) []byte {
+	return append(append([]byte{}, indexedPrefix...), sid+"\x00"...)
+}
+
+// sessionTTL returns the time to live of the "sid" session entry, zero if it does not expire.
+func sessionTTL(txn *badger.Txn, sid string) (time.Duration, error) {
+	item, err := txn.Get(makePrefix(sid))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return 0, sessions.ErrNotFound
+		}
+		return 0, err
+	}
+
+	if expiresAt := item.ExpiresAt(); expiresAt > 0 {
+		return time.Until(time.Unix(int64(expiresAt), 0)), nil
+	}
+
+	return 0, nil
+}
+
+// setIndex adds the index entry of the "sid" session, with the session's time to live.
+func setIndex(txn *badger.Txn, sid string, indexKey []byte, ttl time.Duration) error {
+	indexedKey := append(makeIndexedPrefix(sid), indexKey...)
+	for _, entry := range []*badger.Entry{
+		badger.NewEntry(append(append([]byte{}, indexKey...), sid...), nil),
+		badger.NewEntry(indexedKey, indexKey),
+	} {
+		if ttl > 0 {
+			entry = entry.WithTTL(ttl)
+		}
+
+		if err := txn.SetEntry(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Index adds the "sid" session to the sessions of the "key" with the "value".
+// It returns the `sessions.ErrNotFound` if the session was not acquired.
+func (db *Database) Index(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.Update(func(txn *badger.Txn) error {
+		ttl, err := sessionTTL(txn, sid)
+		if err != nil {
+			return err
+		}
+
+		return setIndex(txn, sid, makeIndexPrefix(key, value), ttl)
+	})
+}
+
+// Unindex removes the "sid" session from the sessions of the "key" with the "value".
+func (db *Database) Unindex(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	indexKey := makeIndexPrefix(key, value)
+	return db.Service.Update(func(txn *badger.Txn) error {
+		if err := txn.Delete(append(append([]byte{}, indexKey...), sid...)); err != nil {
+			return err
+		}
+
+		return txn.Delete(append(makeIndexedPrefix(sid), indexKey...))
+	})
+}
+
+// unindexSession removes the index entries of the "sid" session,
+// they are added to the "newSID" session if it's not empty.
+func (db *Database) unindexSession(txn *badger.Txn, sid, newSID string) error {
+	prefix := makeIndexedPrefix(sid)
+
+	var indexKeys [][]byte
+	iter := txn.NewIterator(badger.DefaultIteratorOptions)
+	for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+		indexKey, err := iter.Item().ValueCopy(nil)
+		if err != nil {
+			iter.Close()
+			return err
+		}
+
+		indexKeys = append(indexKeys, indexKey)
+	}
+	iter.Close()
+
+	if len(indexKeys) == 0 {
+		return nil
 	}
-	if err := txn.Commit(); err != nil {
-		golog.Debugf("Database.Release.Commit: %s: %v", sid, err)
+
+	var ttl time.Duration
+	if newSID != "" {
+		var err error
+		if ttl, err = sessionTTL(txn, newSID); err != nil {
+			return err
+		}
+	}
+
+	for _, indexKey := range indexKeys {
+		for _, key := range [][]byte{
+			append(append([]byte{}, indexKey...), sid...),
+			append(append([]byte{}, prefix...), indexKey...),
+		} {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		if newSID != "" {
+			if err := setIndex(txn, newSID, indexKey, ttl); err != nil {
+				return err
+			}
+		}
 	}
+
+	return nil
+}
+
+// VisitIndex loops through the sessions of the "key" with the "value" and their lifetime.
+func (db *Database) VisitIndex(ctx stdContext.Context, key, value string, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	prefix := makeIndexPrefix(key, value)
+	return db.Service.View(func(txn *badger.Txn) error {
+		var sids []string
+		iter := txn.NewIterator(iterOptionsNoValues)
+		for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+			sids = append(sids, string(iter.Item().Key()[len(prefix):]))
+		}
+		iter.Close()
+
+		for _, sid := range sids {
+			item, err := txn.Get(makePrefix(sid))
+			if err != nil {
+				if err == badger.ErrKeyNotFound {
+					continue // the session is removed.
+				}
+				return err
+			}
+
+			var lifetime sessions.LifeTime
+			if expiresAt := item.ExpiresAt(); expiresAt > 0 {
+				lifetime.Time = time.Unix(int64(expiresAt), 0)
+			}
+
+			if !cb(sid, lifetime) {
+				break
+			}
+		}
+
+		return nil
+	})
 }
 
 // Close shutdowns the badger connection.
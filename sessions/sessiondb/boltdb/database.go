@@ -1,6 +1,8 @@
 package boltdb
 
 import (
+	"bytes"
+	stdContext "context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -29,6 +31,13 @@ type Database struct {
 	Service *bolt.DB
 }
 
+var (
+	_ sessions.DatabaseV2    = (*Database)(nil)
+	_ sessions.RegeneratorV2 = (*Database)(nil)
+	_ sessions.ListerV2      = (*Database)(nil)
+	_ sessions.Indexer       = (*Database)(nil)
+)
+
 var errPathMissing = errors.New("path is required")
 
 // New creates and returns a new BoltDB(file-based) storage
@@ -67,9 +76,14 @@ func New(path string, fileMode os.FileMode) (*Database, error) {
 func NewFromDB(service *bolt.DB, bucketName string) (*Database, error) {
 	bucket := []byte(bucketName)
 
-	err := service.Update(func(tx *bolt.Tx) (err error) {
-		_, err = tx.CreateBucketIfNotExists(bucket)
-		return
+	err := service.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucket, indexBucketName(bucket), indexedBucketName(bucket)} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
 	})
 
 	if err != nil {
@@ -139,6 +153,11 @@ func (db *Database) cleanup() error {
 						return err
 					}
 
+					// its index entries.
+					if err := db.unindexSession(tx, bsid, nil); err != nil {
+						return err
+					}
+
 					// and the session bucket, if any.
 					return b.DeleteBucket(bsid)
 				}
@@ -153,9 +172,13 @@ var expirationKey = []byte("exp") // it can be random.
 
 // Acquire receives a session's lifetime from the database,
 // if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
-func (db *Database) Acquire(sid string, expires time.Duration) (lifetime sessions.LifeTime) {
+func (db *Database) Acquire(ctx stdContext.Context, sid string, expires time.Duration) (lifetime sessions.LifeTime, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	bsid := []byte(sid)
-	err := db.Service.Update(func(tx *bolt.Tx) (err error) {
+	err = db.Service.Update(func(tx *bolt.Tx) (err error) {
 		root := db.getBucket(tx)
 
 		if expires > 0 { // should check or create the expiration bucket.
@@ -166,14 +189,12 @@ func (db *Database) Acquire(sid string, expires time.Duration) (lifetime session
 				// don't return a lifetime, let it empty, session manager will do its job.
 				b, err = root.CreateBucket(name)
 				if err != nil {
-					golog.Debugf("unable to create a session bucket for '%s': %v", sid, err)
 					return err
 				}
 
 				expirationTime := time.Now().Add(expires)
 				timeBytes, err := sessions.DefaultTranscoder.Marshal(expirationTime)
 				if err != nil {
-					golog.Debugf("unable to set an expiration value on session expiration bucket for '%s': %v", sid, err)
 					return err
 				}
 
@@ -194,7 +215,6 @@ func (db *Database) Acquire(sid string, expires time.Duration) (lifetime session
 
 			var expirationTime time.Time
 			if err = sessions.DefaultTranscoder.Unmarshal(expValue, &expirationTime); err != nil {
-				golog.Debugf("acquire: unable to retrieve expiration value for '%s', value was: '%s': %v", sid, expValue, err)
 				return
 			}
 
@@ -206,23 +226,27 @@ func (db *Database) Acquire(sid string, expires time.Duration) (lifetime session
 		_, err = root.CreateBucketIfNotExists(bsid)
 		return
 	})
+
 	if err != nil {
-		golog.Debugf("unable to acquire session '%s': %v", sid, err)
-		return sessions.LifeTime{}
+		lifetime = sessions.LifeTime{}
 	}
 
 	return
 }
 
 // OnUpdateExpiration will re-set the database's session's entry ttl.
-func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) error {
+func (db *Database) OnUpdateExpiration(ctx stdContext.Context, sid string, newExpires time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	expirationTime := time.Now().Add(newExpires)
 	timeBytes, err := sessions.DefaultTranscoder.Marshal(expirationTime)
 	if err != nil {
 		return err
 	}
 
-	err = db.Service.Update(func(tx *bolt.Tx) error {
+	return db.Service.Update(func(tx *bolt.Tx) error {
 		expirationName := getExpirationBucketName([]byte(sid))
 		root := db.getBucket(tx)
 		b := root.Bucket(expirationName)
@@ -233,12 +257,6 @@ func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) err
 
 		return b.Put(expirationKey, timeBytes)
 	})
-
-	if err != nil {
-		golog.Debugf("unable to reset the expiration value for '%s': %v", sid, err)
-	}
-
-	return err
 }
 
 func makeKey(key string) []byte {
@@ -246,18 +264,22 @@ func makeKey(key string) []byte {
 }
 
 // Set sets a key value of a specific session.
+// It returns the `sessions.ErrNotFound` if the session was not acquired.
 // Ignore the "immutable".
-func (db *Database) Set(sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) {
+func (db *Database) Set(ctx stdContext.Context, sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	valueBytes, err := sessions.DefaultTranscoder.Marshal(value)
 	if err != nil {
-		golog.Debug(err)
-		return
+		return err
 	}
 
-	err = db.Service.Update(func(tx *bolt.Tx) error {
+	return db.Service.Update(func(tx *bolt.Tx) error {
 		b := db.getBucketForSession(tx, sid)
 		if b == nil {
-			return nil
+			return sessions.ErrNotFound
 		}
 
 		// Author's notes:
@@ -266,15 +288,15 @@ func (db *Database) Set(sid string, lifetime sessions.LifeTime, key string, valu
 		// (badger does not need a `Cleanup` because we set the TTL based on the lifetime.DurationUntilExpiration()).
 		return b.Put(makeKey(key), valueBytes)
 	})
-
-	if err != nil {
-		golog.Debug(err)
-	}
 }
 
 // Get retrieves a session value based on the key.
-func (db *Database) Get(sid string, key string) (value interface{}) {
-	err := db.Service.View(func(tx *bolt.Tx) error {
+func (db *Database) Get(ctx stdContext.Context, sid string, key string) (value interface{}, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	err = db.Service.View(func(tx *bolt.Tx) error {
 		b := db.getBucketForSession(tx, sid)
 		if b == nil {
 			return nil
@@ -287,16 +309,17 @@ func (db *Database) Get(sid string, key string) (value interface{}) {
 
 		return sessions.DefaultTranscoder.Unmarshal(valueBytes, &value)
 	})
-	if err != nil {
-		golog.Debugf("session '%s' key '%s' not found", sid, key)
-	}
 
 	return
 }
 
 // Visit loops through all session keys and values.
-func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
-	err := db.Service.View(func(tx *bolt.Tx) error {
+func (db *Database) Visit(ctx stdContext.Context, sid string, cb func(key string, value interface{})) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.View(func(tx *bolt.Tx) error {
 		b := db.getBucketForSession(tx, sid)
 		if b == nil {
 			return nil
@@ -305,7 +328,6 @@ func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
 		return b.ForEach(func(k []byte, v []byte) error {
 			var value interface{}
 			if err := sessions.DefaultTranscoder.Unmarshal(v, &value); err != nil {
-				golog.Debugf("unable to retrieve value of key '%s' of '%s': %v", k, sid, err)
 				return err
 			}
 
@@ -313,15 +335,15 @@ func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
 			return nil
 		})
 	})
-
-	if err != nil {
-		golog.Debugf("Database.Visit: %s: %v", sid, err)
-	}
 }
 
 // Len returns the length of the session's entries (keys).
-func (db *Database) Len(sid string) (n int) {
-	err := db.Service.View(func(tx *bolt.Tx) error {
+func (db *Database) Len(ctx stdContext.Context, sid string) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	err = db.Service.View(func(tx *bolt.Tx) error {
 		b := db.getBucketForSession(tx, sid)
 		if b == nil {
 			return nil
@@ -331,61 +353,84 @@ func (db *Database) Len(sid string) (n int) {
 		return nil
 	})
 
-	if err != nil {
-		golog.Debugf("Database.Len: %s: %v", sid, err)
-	}
-
 	return
 }
 
 // Delete removes a session key value based on its key.
-func (db *Database) Delete(sid string, key string) (deleted bool) {
-	err := db.Service.Update(func(tx *bolt.Tx) error {
+func (db *Database) Delete(ctx stdContext.Context, sid string, key string) (deleted bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	err = db.Service.Update(func(tx *bolt.Tx) error {
 		b := db.getBucketForSession(tx, sid)
-		if b == nil {
-			return sessions.ErrNotFound
+		if b == nil || b.Get(makeKey(key)) == nil {
+			return nil
 		}
 
+		deleted = true
 		return b.Delete(makeKey(key))
 	})
 
-	return err == nil
+	if err != nil {
+		deleted = false
+	}
+	return
 }
 
 // Clear removes all session key values but it keeps the session entry.
-func (db *Database) Clear(sid string) {
-	err := db.Service.Update(func(tx *bolt.Tx) error {
+func (db *Database) Clear(ctx stdContext.Context, sid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.Update(func(tx *bolt.Tx) error {
 		b := db.getBucketForSession(tx, sid)
 		if b == nil {
 			return nil
 		}
 
-		return b.ForEach(func(k []byte, v []byte) error {
-			return b.Delete(k)
-		})
-	})
+		// the keys are collected first, a bucket should not be modified while iterating it.
+		var keys [][]byte
+		if err := b.ForEach(func(k []byte, v []byte) error {
+			keys = append(keys, k)
+			return nil
+		}); err != nil {
+			return err
+		}
 
-	if err != nil {
-		golog.Debugf("Database.Clear: %s: %v", sid, err)
-	}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 // Release destroys the session, it clears and removes the session entry,
 // session manager will create a new session ID on the next request after this call.
-func (db *Database) Release(sid string) {
-	err := db.Service.Update(func(tx *bolt.Tx) error {
+func (db *Database) Release(ctx stdContext.Context, sid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.Update(func(tx *bolt.Tx) error {
 		// delete the session bucket.
 		b := db.getBucket(tx)
 		bsid := []byte(sid)
-		// try to delete the associated expiration bucket, if exists, ignore error.
-		_ = b.DeleteBucket(getExpirationBucketName(bsid))
+		// delete the associated expiration bucket, if exists.
+		if err := b.DeleteBucket(getExpirationBucketName(bsid)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
 
-		return b.DeleteBucket(bsid)
-	})
+		if err := b.DeleteBucket(bsid); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
 
-	if err != nil {
-		golog.Debugf("Database.Release: %s: %v", sid, err)
-	}
+		return db.unindexSession(tx, bsid, nil)
+	})
 }
 
 // Close shutdowns the BoltDB connection.
@@ -393,6 +438,238 @@ func (db *Database) Close() error {
 	return closeDB(db)
 }
 
+// Regenerate moves the session bucket and its expiration bucket to the "newSID" session.
+func (db *Database) Regenerate(ctx stdContext.Context, oldSID, newSID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.Update(func(tx *bolt.Tx) error {
+		root := db.getBucket(tx)
+
+		oldName, newName := []byte(oldSID), []byte(newSID)
+		for _, names := range [][2][]byte{
+			{oldName, newName},
+			{getExpirationBucketName(oldName), getExpirationBucketName(newName)},
+		} {
+			b := root.Bucket(names[0])
+			if b == nil {
+				if bytes.Equal(names[0], oldName) {
+					return sessions.ErrNotFound
+				}
+
+				continue // does not expire.
+			}
+
+			newBucket, err := root.CreateBucket(names[1])
+			if err != nil {
+				return err
+			}
+
+			err = b.ForEach(func(k []byte, v []byte) error {
+				return newBucket.Put(k, v)
+			})
+			if err != nil {
+				return err
+			}
+
+			if err = root.DeleteBucket(names[0]); err != nil {
+				return err
+			}
+		}
+
+		return db.unindexSession(tx, oldName, newName)
+	})
+}
+
+// VisitSessions loops through the session buckets and their lifetime.
+func (db *Database) VisitSessions(ctx stdContext.Context, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.View(func(tx *bolt.Tx) error {
+		root := db.getBucket(tx)
+		suffix := getExpirationBucketName(nil)
+
+		c := root.Cursor()
+		for bsid, v := c.First(); bsid != nil; bsid, v = c.Next() {
+			if v != nil || len(bsid) == 0 {
+				continue // not a bucket.
+			}
+
+			if bytes.HasSuffix(bsid, suffix) && root.Bucket(bsid[:len(bsid)-len(suffix)]) != nil {
+				continue // the expiration bucket of a session.
+			}
+
+			var lifetime sessions.LifeTime
+			if bExp := root.Bucket(getExpirationBucketName(bsid)); bExp != nil {
+				if _, expValue := bExp.Cursor().First(); expValue != nil {
+					if err := sessions.DefaultTranscoder.Unmarshal(expValue, &lifetime.Time); err != nil {
+						return err
+					}
+				}
+			}
+
+			if !cb(string(bsid), lifetime) {
+				break
+			}
+		}
+
+		return nil
+	})
+}
+
+// the index buckets contain a bucket of session ids for each index name, see `makeIndexName`,
+// and a bucket of index names for each session id.
+func indexBucketName(table []byte) []byte {
+	return append(append([]byte{}, table...), "_index"...)
+}
+
+func indexedBucketName(table []byte) []byte {
+	return append(append([]byte{}, table...), "_indexed"...)
+}
+
+func makeIndexName(key, value string) []byte {
+	return []byte(key + "\x00" + value)
+}
+
+// Index adds the "sid" session to the sessions of the "key" with the "value".
+// It returns the `sessions.ErrNotFound` if the session was not acquired.
+func (db *Database) Index(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	bsid, name := []byte(sid), makeIndexName(key, value)
+	return db.Service.Update(func(tx *bolt.Tx) error {
+		if db.getBucket(tx).Bucket(bsid) == nil {
+			return sessions.ErrNotFound
+		}
+
+		sids, err := tx.Bucket(indexBucketName(db.table)).CreateBucketIfNotExists(name)
+		if err != nil {
+			return err
+		}
+
+		names, err := tx.Bucket(indexedBucketName(db.table)).CreateBucketIfNotExists(bsid)
+		if err != nil {
+			return err
+		}
+
+		if err = sids.Put(bsid, bsid); err != nil {
+			return err
+		}
+
+		return names.Put(name, name)
+	})
+}
+
+// Unindex removes the "sid" session from the sessions of the "key" with the "value".
+func (db *Database) Unindex(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	bsid, name := []byte(sid), makeIndexName(key, value)
+	return db.Service.Update(func(tx *bolt.Tx) error {
+		if sids := tx.Bucket(indexBucketName(db.table)).Bucket(name); sids != nil {
+			if err := sids.Delete(bsid); err != nil {
+				return err
+			}
+		}
+
+		if names := tx.Bucket(indexedBucketName(db.table)).Bucket(bsid); names != nil {
+			return names.Delete(name)
+		}
+
+		return nil
+	})
+}
+
+// unindexSession removes the "bsid" session from its indexes,
+// it's added to the same indexes as the "newSID" session if it's not nil.
+func (db *Database) unindexSession(tx *bolt.Tx, bsid, newSID []byte) error {
+	indexed := tx.Bucket(indexedBucketName(db.table))
+	names := indexed.Bucket(bsid)
+	if names == nil {
+		return nil
+	}
+
+	index := tx.Bucket(indexBucketName(db.table))
+	err := names.ForEach(func(name []byte, _ []byte) error {
+		sids := index.Bucket(name)
+		if sids == nil {
+			return nil
+		}
+
+		if err := sids.Delete(bsid); err != nil {
+			return err
+		}
+
+		if newSID == nil {
+			return nil
+		}
+
+		return sids.Put(newSID, newSID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if newSID != nil {
+		newNames, err := indexed.CreateBucket(newSID)
+		if err != nil {
+			return err
+		}
+
+		if err = names.ForEach(func(name []byte, _ []byte) error {
+			return newNames.Put(name, name)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return indexed.DeleteBucket(bsid)
+}
+
+// VisitIndex loops through the sessions of the "key" with the "value" and their lifetime.
+func (db *Database) VisitIndex(ctx stdContext.Context, key, value string, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.Service.View(func(tx *bolt.Tx) error {
+		sids := tx.Bucket(indexBucketName(db.table)).Bucket(makeIndexName(key, value))
+		if sids == nil {
+			return nil
+		}
+
+		root := db.getBucket(tx)
+		c := sids.Cursor()
+		for bsid, _ := c.First(); bsid != nil; bsid, _ = c.Next() {
+			if root.Bucket(bsid) == nil {
+				continue // the session is removed.
+			}
+
+			var lifetime sessions.LifeTime
+			if bExp := root.Bucket(getExpirationBucketName(bsid)); bExp != nil {
+				if _, expValue := bExp.Cursor().First(); expValue != nil {
+					if err := sessions.DefaultTranscoder.Unmarshal(expValue, &lifetime.Time); err != nil {
+						return err
+					}
+				}
+			}
+
+			if !cb(string(bsid), lifetime) {
+				break
+			}
+		}
+
+		return nil
+	})
+}
+
 func closeDB(db *Database) error {
 	err := db.Service.Close()
 	if err != nil {
@@ -0,0 +1,125 @@
+package boltdb_test
+
+import (
+	stdContext "context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12/sessions"
+	"github.com/kataras/iris/v12/sessions/sessiondb/boltdb"
+)
+
+func expectSessions(t *testing.T, visit func(cb func(sid string, lifetime sessions.LifeTime) bool) error, expected ...string) {
+	t.Helper()
+
+	got := []string{}
+	err := visit(func(sid string, lifetime sessions.LifeTime) bool {
+		got = append(got, sid)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+
+	if expected == nil {
+		expected = []string{}
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected sessions: %v but got: %v", expected, got)
+	}
+}
+
+func TestDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-sessions-boltdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := boltdb.New(filepath.Join(dir, "sessions.db"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := stdContext.Background()
+	lifetime := sessions.LifeTime{Time: time.Now().Add(time.Hour)}
+	for _, sid := range []string{"a", "b"} {
+		if _, err = db.Acquire(ctx, sid, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for sid, values := range map[string]map[string]string{
+		"a": {"name": "iris", "role": "admin"},
+		"b": {"name": "other"},
+	} {
+		for key, value := range values {
+			if err = db.Set(ctx, sid, lifetime, key, value, false); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	visitSessions := func(cb func(sid string, lifetime sessions.LifeTime) bool) error {
+		return db.VisitSessions(ctx, cb)
+	}
+	visitIndex := func(key, value string) func(cb func(sid string, lifetime sessions.LifeTime) bool) error {
+		return func(cb func(sid string, lifetime sessions.LifeTime) bool) error {
+			return db.VisitIndex(ctx, key, value, cb)
+		}
+	}
+
+	expectSessions(t, visitSessions, "a", "b")
+
+	for _, index := range [][3]string{{"a", "user", "kataras"}, {"b", "user", "kataras"}, {"b", "user", "makis"}} {
+		if err = db.Index(ctx, index[0], index[1], index[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectSessions(t, visitIndex("user", "kataras"), "a", "b")
+
+	if err = db.Regenerate(ctx, "a", "c"); err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(map[string]interface{})
+	if err = db.Visit(ctx, "c", func(key string, value interface{}) { values[key] = value }); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]interface{}{"name": "iris", "role": "admin"}; !reflect.DeepEqual(expected, values) {
+		t.Fatalf("expected the values: %v of the regenerated session but got: %v", expected, values)
+	}
+	if n, err := db.Len(ctx, "a"); err != nil || n != 0 {
+		t.Fatalf("expected no values of the old session but got: %d (%v)", n, err)
+	}
+	expectSessions(t, visitSessions, "b", "c")
+	expectSessions(t, visitIndex("user", "kataras"), "b", "c")
+
+	if err = db.Regenerate(ctx, "a", "d"); err != sessions.ErrNotFound {
+		t.Fatalf("expected the not found error but got: %v", err)
+	}
+
+	if err = db.Unindex(ctx, "b", "user", "kataras"); err != nil {
+		t.Fatal(err)
+	}
+	expectSessions(t, visitIndex("user", "kataras"), "c")
+
+	if err = db.Release(ctx, "c"); err != nil {
+		t.Fatal(err)
+	}
+	expectSessions(t, visitSessions, "b")
+	expectSessions(t, visitIndex("user", "kataras"))
+	expectSessions(t, visitIndex("user", "makis"), "b")
+
+	if value, err := db.Get(ctx, "b", "name"); err != nil || value != "other" {
+		t.Fatalf("expected the value of the other session to be kept but got: %v (%v)", value, err)
+	}
+}
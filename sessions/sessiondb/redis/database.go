@@ -1,7 +1,9 @@
 package redis
 
 import (
+	stdContext "context"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/kataras/iris/v12/sessions"
@@ -72,7 +74,12 @@ type Database struct {
 	c Config
 }
 
-var _ sessions.Database = (*Database)(nil)
+var (
+	_ sessions.DatabaseV2    = (*Database)(nil)
+	_ sessions.RegeneratorV2 = (*Database)(nil)
+	_ sessions.ListerV2      = (*Database)(nil)
+	_ sessions.Indexer       = (*Database)(nil)
+)
 
 // New returns a new redis database.
 func New(cfg ...Config) *Database {
@@ -126,29 +133,45 @@ func (db *Database) Config() *Config {
 
 // Acquire receives a session's lifetime from the database,
 // if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
-func (db *Database) Acquire(sid string, expires time.Duration) sessions.LifeTime {
+func (db *Database) Acquire(ctx stdContext.Context, sid string, expires time.Duration) (sessions.LifeTime, error) {
+	if err := ctx.Err(); err != nil {
+		return sessions.LifeTime{}, err
+	}
+
 	seconds, hasExpiration, found := db.c.Driver.TTL(sid)
 	if !found {
-		// fmt.Printf("db.Acquire expires: %s. Seconds: %v\n", expires, expires.Seconds())
 		// not found, create an entry with ttl and return an empty lifetime, session manager will do its job.
 		if err := db.c.Driver.Set(sid, sid, int64(expires.Seconds())); err != nil {
-			golog.Debug(err)
+			return sessions.LifeTime{}, err
 		}
 
-		return sessions.LifeTime{} // session manager will handle the rest.
+		return sessions.LifeTime{}, db.c.Driver.SetAdd(sessionsSetKey, sid)
 	}
 
 	if !hasExpiration {
-		return sessions.LifeTime{}
+		return sessions.LifeTime{}, nil
 	}
 
-	return sessions.LifeTime{Time: time.Now().Add(time.Duration(seconds) * time.Second)}
+	return sessions.LifeTime{Time: time.Now().Add(time.Duration(seconds) * time.Second)}, nil
 }
 
 // OnUpdateExpiration will re-set the database's session's entry ttl.
 // https://redis.io/commands/expire#refreshing-expires
-func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) error {
-	return db.c.Driver.UpdateTTLMany(sid, int64(newExpires.Seconds()))
+func (db *Database) OnUpdateExpiration(ctx stdContext.Context, sid string, newExpires time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := db.c.Driver.UpdateTTLMany(sid, int64(newExpires.Seconds())); err != nil {
+		return err
+	}
+
+	// the index entries of the session, if any.
+	if _, _, found := db.c.Driver.TTL(db.indexedKey(sid)); found {
+		return db.c.Driver.UpdateTTL(db.indexedKey(sid), int64(newExpires.Seconds()))
+	}
+
+	return nil
 }
 
 func (db *Database) makeKey(sid, key string) string {
@@ -157,92 +180,430 @@ func (db *Database) makeKey(sid, key string) string {
 
 // Set sets a key value of a specific session.
 // Ignore the "immutable".
-func (db *Database) Set(sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) {
+func (db *Database) Set(ctx stdContext.Context, sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	valueBytes, err := sessions.DefaultTranscoder.Marshal(value)
 	if err != nil {
-		golog.Error(err)
-		return
+		return err
 	}
 
-	// fmt.Println("database.Set")
-	// fmt.Printf("lifetime.DurationUntilExpiration(): %s. Seconds: %v\n", lifetime.DurationUntilExpiration(), lifetime.DurationUntilExpiration().Seconds())
-	if err = db.c.Driver.Set(db.makeKey(sid, key), valueBytes, int64(lifetime.DurationUntilExpiration().Seconds())); err != nil {
-		golog.Debug(err)
-	}
+	return db.c.Driver.Set(db.makeKey(sid, key), valueBytes, int64(lifetime.DurationUntilExpiration().Seconds()))
 }
 
 // Get retrieves a session value based on the key.
-func (db *Database) Get(sid string, key string) (value interface{}) {
-	db.get(db.makeKey(sid, key), &value)
+func (db *Database) Get(ctx stdContext.Context, sid string, key string) (value interface{}, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	err = db.get(db.makeKey(sid, key), &value)
 	return
 }
 
-func (db *Database) get(key string, outPtr interface{}) {
+// get decodes the value of the "key" to the "outPtr", a missing key is not an error.
+func (db *Database) get(key string, outPtr interface{}) error {
 	data, err := db.c.Driver.Get(key)
 	if err != nil {
-		// not found.
-		return
-	}
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil
+		}
 
-	if err = sessions.DefaultTranscoder.Unmarshal(data.([]byte), outPtr); err != nil {
-		golog.Debugf("unable to unmarshal value of key: '%s': %v", key, err)
+		return err
 	}
-}
 
-func (db *Database) keys(sid string) []string {
-	keys, err := db.c.Driver.GetKeys(sid)
-	if err != nil {
-		golog.Debugf("unable to get all redis keys of session '%s': %v", sid, err)
-		return nil
-	}
+	return sessions.DefaultTranscoder.Unmarshal(data.([]byte), outPtr)
+}
 
-	return keys
+// keys returns the redis keys of the session values.
+func (db *Database) keys(sid string) ([]string, error) {
+	return db.c.Driver.GetKeys(sid + db.c.Delim)
 }
 
 // Visit loops through all session keys and values.
-func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
-	keys := db.keys(sid)
+func (db *Database) Visit(ctx stdContext.Context, sid string, cb func(key string, value interface{})) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	keys, err := db.keys(sid)
+	if err != nil {
+		return err
+	}
+
+	prefix := sid + db.c.Delim
 	for _, key := range keys {
 		var value interface{} // new value each time, we don't know what user will do in "cb".
-		db.get(key, &value)
-		cb(key, value)
+		if err = db.get(key, &value); err != nil {
+			return err
+		}
+
+		cb(key[len(prefix):], value)
 	}
+
+	return nil
 }
 
 // Len returns the length of the session's entries (keys).
-func (db *Database) Len(sid string) (n int) {
-	return len(db.keys(sid))
+func (db *Database) Len(ctx stdContext.Context, sid string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	keys, err := db.keys(sid)
+	return len(keys), err
 }
 
 // Delete removes a session key value based on its key.
-func (db *Database) Delete(sid string, key string) (deleted bool) {
-	err := db.c.Driver.Delete(db.makeKey(sid, key))
-	if err != nil {
-		golog.Error(err)
+func (db *Database) Delete(ctx stdContext.Context, sid string, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
 	}
-	return err == nil
+
+	if err := db.c.Driver.Delete(db.makeKey(sid, key)); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
 // Clear removes all session key values but it keeps the session entry.
-func (db *Database) Clear(sid string) {
-	keys := db.keys(sid)
+func (db *Database) Clear(ctx stdContext.Context, sid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	keys, err := db.keys(sid)
+	if err != nil {
+		return err
+	}
+
 	for _, key := range keys {
-		if err := db.c.Driver.Delete(key); err != nil {
-			golog.Debugf("unable to delete session '%s' value of key: '%s': %v", sid, key, err)
+		if err = db.c.Driver.Delete(key); err != nil {
+			return err
 		}
 	}
+
+	return nil
 }
 
 // Release destroys the session, it clears and removes the session entry,
 // session manager will create a new session ID on the next request after this call.
-func (db *Database) Release(sid string) {
+func (db *Database) Release(ctx stdContext.Context, sid string) error {
 	// clear all $sid-$key.
-	db.Clear(sid)
+	if err := db.Clear(ctx, sid); err != nil {
+		return err
+	}
+
+	// its index entries
+	if err := db.removeIndexes(sid); err != nil {
+		return err
+	}
+
 	// and remove the $sid.
-	err := db.c.Driver.Delete(sid)
+	if err := db.c.Driver.Delete(sid); err != nil {
+		return err
+	}
+
+	return db.c.Driver.SetRemove(sessionsSetKey, sid)
+}
+
+func ttlSeconds(seconds int64, hasExpiration bool) int64 {
+	if !hasExpiration {
+		return 0
+	}
+
+	return seconds
+}
+
+// regeneratedValue is a session value which is copied by `Regenerate`.
+type regeneratedValue struct {
+	key     string // the key of the old session.
+	newKey  string
+	value   interface{}
+	seconds int64
+}
+
+// Regenerate moves the session values, with their ttl, and its indexes to the "newSID" session.
+// The values and the indexes are copied to the new session before the old ones are removed.
+// If a step fails then the new session is removed and the removed values of the old one are restored,
+// so the old session entry is kept as it was and the error is returned.
+func (db *Database) Regenerate(ctx stdContext.Context, oldSID, newSID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	seconds, hasExpiration, found := db.c.Driver.TTL(oldSID)
+	if !found {
+		return sessions.ErrNotFound
+	}
+
+	prefix := oldSID + db.c.Delim
+	keys, err := db.c.Driver.GetKeys(prefix)
+	if err != nil {
+		return err
+	}
+
+	names, err := db.c.Driver.SetMembers(db.indexedKey(oldSID))
+	if err != nil {
+		return err
+	}
+
+	// create the new session with the values and the indexes of the old one.
+	var values []regeneratedValue
+	if err = db.copySession(newSID, ttlSeconds(seconds, hasExpiration), prefix, keys, names, &values); err != nil {
+		db.removeRegenerated(newSID, values, names)
+		return err
+	}
+
+	// remove the values of the old session, its entry is removed last so it's live until then.
+	for i, v := range values {
+		if err = db.c.Driver.Delete(v.key); err != nil {
+			db.restoreValues(values[:i])
+			db.removeRegenerated(newSID, values, names)
+			return err
+		}
+	}
+
+	if err = db.c.Driver.Delete(oldSID); err != nil {
+		db.restoreValues(values)
+		db.removeRegenerated(newSID, values, names)
+		return err
+	}
+
+	// the old session is removed, a failure to remove it from the sets is not returned
+	// because the session is already moved, its references are removed on the next visit of the sets.
+	for _, name := range names {
+		if err = db.c.Driver.SetRemove(name, oldSID); err != nil {
+			golog.Errorf("redis: remove regenerated session %s from %s: %v", oldSID, name, err)
+		}
+	}
+
+	if err = db.c.Driver.Delete(db.indexedKey(oldSID)); err != nil {
+		golog.Errorf("redis: remove the indexes of the regenerated session %s: %v", oldSID, err)
+	}
+
+	if err = db.c.Driver.SetRemove(sessionsSetKey, oldSID); err != nil {
+		golog.Errorf("redis: remove regenerated session %s from %s: %v", oldSID, sessionsSetKey, err)
+	}
+
+	return nil
+}
+
+// copySession creates the "newSID" session entry, with the "seconds" ttl, and copies to it
+// the "keys" values of the old session and the "names" index sets of the old session.
+// The copied values are appended to the "values".
+func (db *Database) copySession(newSID string, seconds int64, prefix string, keys, names []string, values *[]regeneratedValue) error {
+	if err := db.c.Driver.Set(newSID, newSID, seconds); err != nil {
+		return err
+	}
+
+	if err := db.c.Driver.SetAdd(sessionsSetKey, newSID); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		value, err := db.c.Driver.Get(key)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) { // expired meanwhile.
+				continue
+			}
+			return err
+		}
+
+		keySeconds, keyHasExpiration, _ := db.c.Driver.TTL(key)
+		v := regeneratedValue{
+			key:     key,
+			newKey:  db.makeKey(newSID, key[len(prefix):]),
+			value:   value,
+			seconds: ttlSeconds(keySeconds, keyHasExpiration),
+		}
+
+		*values = append(*values, v)
+		if err = db.c.Driver.Set(v.newKey, v.value, v.seconds); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if err := db.c.Driver.SetAdd(name, newSID); err != nil {
+			return err
+		}
+
+		if err := db.c.Driver.SetAdd(db.indexedKey(newSID), name); err != nil {
+			return err
+		}
+	}
+
+	if len(names) > 0 && seconds > 0 {
+		return db.c.Driver.UpdateTTL(db.indexedKey(newSID), seconds)
+	}
+
+	return nil
+}
+
+// removeRegenerated removes the "newSID" session, its "values" and its "names" indexes
+// created by a failed `Regenerate`. It's a best effort, the errors are logged.
+func (db *Database) removeRegenerated(newSID string, values []regeneratedValue, names []string) {
+	for _, v := range values {
+		if err := db.c.Driver.Delete(v.newKey); err != nil {
+			golog.Errorf("redis: remove the value %s of the failed regenerated session: %v", v.newKey, err)
+		}
+	}
+
+	for _, name := range names {
+		if err := db.c.Driver.SetRemove(name, newSID); err != nil {
+			golog.Errorf("redis: remove the failed regenerated session %s from %s: %v", newSID, name, err)
+		}
+	}
+
+	for _, key := range []string{db.indexedKey(newSID), newSID} {
+		if err := db.c.Driver.Delete(key); err != nil {
+			golog.Errorf("redis: remove the failed regenerated session %s: %v", key, err)
+		}
+	}
+
+	if err := db.c.Driver.SetRemove(sessionsSetKey, newSID); err != nil {
+		golog.Errorf("redis: remove the failed regenerated session %s from %s: %v", newSID, sessionsSetKey, err)
+	}
+}
+
+// restoreValues sets back the removed "values" of the old session of a failed `Regenerate`.
+// It's a best effort, the errors are logged.
+func (db *Database) restoreValues(values []regeneratedValue) {
+	for _, v := range values {
+		if err := db.c.Driver.Set(v.key, v.value, v.seconds); err != nil {
+			golog.Errorf("redis: restore the value %s of the session: %v", v.key, err)
+		}
+	}
+}
+
+// The session ids are kept in the "_iris_sessions" set and the sessions of an indexed value
+// in the "_iris_index$Delim$key$Delim$value" sets, the "_iris_indexed$Delim$sid" set
+// of a session holds the names of its index sets and it expires with the session.
+// The sets are cleaned from the expired sessions when they are visited.
+const (
+	sessionsSetKey = "_iris_sessions"
+	indexSetKey    = "_iris_index"
+	indexedSetKey  = "_iris_indexed"
+)
+
+func (db *Database) indexKey(key, value string) string {
+	return indexSetKey + db.c.Delim + key + db.c.Delim + value
+}
+
+func (db *Database) indexedKey(sid string) string {
+	return indexedSetKey + db.c.Delim + sid
+}
+
+// visitSet calls the "cb" with the non-expired sessions of the "setKey" set and their lifetime,
+// the expired ones are removed from the set.
+func (db *Database) visitSet(setKey string, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	sids, err := db.c.Driver.SetMembers(setKey)
+	if err != nil {
+		return err
+	}
+	sort.Strings(sids)
+
+	for _, sid := range sids {
+		seconds, hasExpiration, found := db.c.Driver.TTL(sid)
+		if !found {
+			if err = db.c.Driver.SetRemove(setKey, sid); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var lifetime sessions.LifeTime
+		if hasExpiration {
+			lifetime.Time = time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+
+		if !cb(sid, lifetime) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// VisitSessions loops through the session entries and their lifetime,
+// they are kept in a set so the keys are not scanned.
+func (db *Database) VisitSessions(ctx stdContext.Context, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.visitSet(sessionsSetKey, cb)
+}
+
+// Index adds the "sid" session to the sessions of the "key" with the "value".
+// It returns the `sessions.ErrNotFound` if the session was not acquired.
+func (db *Database) Index(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	seconds, hasExpiration, found := db.c.Driver.TTL(sid)
+	if !found {
+		return sessions.ErrNotFound
+	}
+
+	name := db.indexKey(key, value)
+	if err := db.c.Driver.SetAdd(name, sid); err != nil {
+		return err
+	}
+
+	if err := db.c.Driver.SetAdd(db.indexedKey(sid), name); err != nil {
+		return err
+	}
+
+	if hasExpiration {
+		return db.c.Driver.UpdateTTL(db.indexedKey(sid), seconds)
+	}
+
+	return nil
+}
+
+// Unindex removes the "sid" session from the sessions of the "key" with the "value".
+func (db *Database) Unindex(ctx stdContext.Context, sid, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	name := db.indexKey(key, value)
+	if err := db.c.Driver.SetRemove(name, sid); err != nil {
+		return err
+	}
+
+	return db.c.Driver.SetRemove(db.indexedKey(sid), name)
+}
+
+// removeIndexes removes the "sid" session from its index sets.
+func (db *Database) removeIndexes(sid string) error {
+	names, err := db.c.Driver.SetMembers(db.indexedKey(sid))
 	if err != nil {
-		golog.Debugf("Database.Release.Driver.Delete: %s: %v", sid, err)
+		return err
+	}
+
+	for _, name := range names {
+		if err = db.c.Driver.SetRemove(name, sid); err != nil {
+			return err
+		}
 	}
+
+	return db.c.Driver.Delete(db.indexedKey(sid))
+}
+
+// VisitIndex loops through the sessions of the "key" with the "value" and their lifetime.
+func (db *Database) VisitIndex(ctx stdContext.Context, key, value string, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return db.visitSet(db.indexKey(key, value), cb)
 }
 
 // Close terminates the redis connection.
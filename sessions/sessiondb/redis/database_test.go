@@ -0,0 +1,306 @@
+package redis_test
+
+import (
+	stdContext "context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12/sessions"
+	"github.com/kataras/iris/v12/sessions/sessiondb/redis"
+)
+
+type memoryItem struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// memoryDriver is an in-memory redis `Driver` which fails to delete the keys that "failDelete" reports.
+type memoryDriver struct {
+	mu         sync.Mutex
+	items      map[string]memoryItem
+	sets       map[string]map[string]struct{}
+	failDelete func(key string) bool
+}
+
+var errDelete = errors.New("delete failed")
+
+func newMemoryDriver() *memoryDriver {
+	return &memoryDriver{
+		items: make(map[string]memoryItem),
+		sets:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (d *memoryDriver) Connect(c redis.Config) error { return nil }
+func (d *memoryDriver) PingPong() (bool, error)      { return true, nil }
+func (d *memoryDriver) CloseConnection() error       { return nil }
+
+func (d *memoryDriver) Set(key string, value interface{}, secondsLifetime int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item := memoryItem{value: value}
+	if secondsLifetime > 0 {
+		item.expiresAt = time.Now().Add(time.Duration(secondsLifetime) * time.Second)
+	}
+
+	d.items[key] = item
+	return nil
+}
+
+func (d *memoryDriver) get(key string) (memoryItem, bool) {
+	item, ok := d.items[key]
+	if ok && !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		delete(d.items, key)
+		return item, false
+	}
+
+	return item, ok
+}
+
+func (d *memoryDriver) Get(key string) (interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item, ok := d.get(key)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, redis.ErrKeyNotFound)
+	}
+
+	return item.value, nil
+}
+
+func (d *memoryDriver) TTL(key string) (int64, bool, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.sets[key]; ok {
+		return 0, false, true
+	}
+
+	item, ok := d.get(key)
+	if !ok {
+		return 0, false, false
+	}
+
+	if item.expiresAt.IsZero() {
+		return 0, false, true
+	}
+
+	return int64(time.Until(item.expiresAt).Seconds()), true, true
+}
+
+func (d *memoryDriver) UpdateTTL(key string, newSecondsLifeTime int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if item, ok := d.get(key); ok {
+		item.expiresAt = time.Now().Add(time.Duration(newSecondsLifeTime) * time.Second)
+		d.items[key] = item
+	}
+
+	return nil
+}
+
+func (d *memoryDriver) UpdateTTLMany(prefix string, newSecondsLifeTime int64) error {
+	keys, _ := d.GetKeys(prefix)
+	for _, key := range keys {
+		if err := d.UpdateTTL(key, newSecondsLifeTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *memoryDriver) GetAll() (interface{}, error) {
+	return d.GetKeys("")
+}
+
+func (d *memoryDriver) GetKeys(prefix string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var keys []string
+	for key := range d.items {
+		if _, ok := d.get(key); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (d *memoryDriver) Delete(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.failDelete != nil && d.failDelete(key) {
+		return errDelete
+	}
+
+	delete(d.items, key)
+	delete(d.sets, key)
+	return nil
+}
+
+func (d *memoryDriver) SetAdd(key, member string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.sets[key] == nil {
+		d.sets[key] = make(map[string]struct{})
+	}
+	d.sets[key][member] = struct{}{}
+	return nil
+}
+
+func (d *memoryDriver) SetRemove(key, member string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.sets[key], member)
+	if len(d.sets[key]) == 0 {
+		delete(d.sets, key)
+	}
+	return nil
+}
+
+func (d *memoryDriver) SetMembers(key string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	members := make([]string, 0, len(d.sets[key]))
+	for member := range d.sets[key] {
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func visitIndex(t *testing.T, db *redis.Database, key, value string) []string {
+	t.Helper()
+
+	var sids []string
+	err := db.VisitIndex(stdContext.Background(), key, value, func(sid string, lifetime sessions.LifeTime) bool {
+		sids = append(sids, sid)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sids
+}
+
+func visitSessions(t *testing.T, db *redis.Database) []string {
+	t.Helper()
+
+	var sids []string
+	err := db.VisitSessions(stdContext.Background(), func(sid string, lifetime sessions.LifeTime) bool {
+		sids = append(sids, sid)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sids
+}
+
+func expectValue(t *testing.T, db *redis.Database, sid, key string, expected interface{}) {
+	t.Helper()
+
+	value, err := db.Get(stdContext.Background(), sid, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value != expected {
+		t.Fatalf("[%s] expected value of %s to be: %v but got: %v", sid, key, expected, value)
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	ctx := stdContext.Background()
+	driver := newMemoryDriver()
+	db := redis.New(redis.Config{Driver: driver})
+
+	if _, err := db.Acquire(ctx, "old", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	lifetime := sessions.LifeTime{Time: time.Now().Add(time.Hour)}
+	for _, key := range []string{"a", "b"} {
+		if err := db.Set(ctx, "old", lifetime, key, key+"-value", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Index(ctx, "old", "user", "kataras"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectKept := func(t *testing.T) {
+		t.Helper()
+
+		expectValue(t, db, "old", "a", "a-value")
+		expectValue(t, db, "old", "b", "b-value")
+		expectValue(t, db, "new", "a", nil)
+		if sids := visitIndex(t, db, "user", "kataras"); len(sids) != 1 || sids[0] != "old" {
+			t.Fatalf("expected the old session to be indexed but got: %v", sids)
+		}
+		if sids := visitSessions(t, db); len(sids) != 1 || sids[0] != "old" {
+			t.Fatalf("expected only the old session but got: %v", sids)
+		}
+		if _, _, found := driver.TTL("new"); found {
+			t.Fatalf("expected the new session entry to be removed")
+		}
+	}
+
+	// fails to remove the old session entry.
+	driver.failDelete = func(key string) bool { return key == "old" }
+	if err := db.Regenerate(ctx, "old", "new"); err != errDelete {
+		t.Fatalf("expected the delete error but got: %v", err)
+	}
+	expectKept(t)
+
+	// fails to remove a value after another one is removed.
+	driver.failDelete = func(key string) bool { return key == "old-b" }
+	if err := db.Regenerate(ctx, "old", "new"); err != errDelete {
+		t.Fatalf("expected the delete error but got: %v", err)
+	}
+	expectKept(t)
+
+	driver.failDelete = nil
+	if err := db.Regenerate(ctx, "old", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectValue(t, db, "new", "a", "a-value")
+	expectValue(t, db, "new", "b", "b-value")
+	expectValue(t, db, "old", "a", nil)
+	if sids := visitIndex(t, db, "user", "kataras"); len(sids) != 1 || sids[0] != "new" {
+		t.Fatalf("expected the new session to be indexed but got: %v", sids)
+	}
+	if sids := visitSessions(t, db); len(sids) != 1 || sids[0] != "new" {
+		t.Fatalf("expected only the new session but got: %v", sids)
+	}
+	if err := db.Regenerate(ctx, "old", "other"); err != sessions.ErrNotFound {
+		t.Fatalf("expected the not found error but got: %v", err)
+	}
+
+	if err := db.Release(ctx, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if sids := visitIndex(t, db, "user", "kataras"); len(sids) != 0 {
+		t.Fatalf("expected no indexed sessions but got: %v", sids)
+	}
+	if sids := visitSessions(t, db); len(sids) != 0 {
+		t.Fatalf("expected no sessions but got: %v", sids)
+	}
+}
@@ -14,6 +14,12 @@ type Driver interface {
 	GetAll() (interface{}, error)
 	GetKeys(prefix string) ([]string, error)
 	Delete(key string) error
+	// SetAdd adds the "member" to the set of the "key",
+	// SetRemove removes it and SetMembers returns the members of the set,
+	// they are used to keep the sessions and their indexes without a scan of the keys.
+	SetAdd(key, member string) error
+	SetRemove(key, member string) error
+	SetMembers(key string) ([]string, error)
 }
 
 var (
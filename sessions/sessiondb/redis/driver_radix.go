@@ -298,3 +298,20 @@ func (r *RadixDriver) Delete(key string) error {
 	err := r.pool.Do(radix.Cmd(nil, "DEL", r.Config.Prefix+key))
 	return err
 }
+
+// SetAdd adds the "member" to the set of the "key" using the "SADD" command.
+func (r *RadixDriver) SetAdd(key, member string) error {
+	return r.pool.Do(radix.Cmd(nil, "SADD", r.Config.Prefix+key, member))
+}
+
+// SetRemove removes the "member" from the set of the "key" using the "SREM" command.
+func (r *RadixDriver) SetRemove(key, member string) error {
+	return r.pool.Do(radix.Cmd(nil, "SREM", r.Config.Prefix+key, member))
+}
+
+// SetMembers returns the members of the set of the "key" using the "SMEMBERS" command.
+func (r *RadixDriver) SetMembers(key string) ([]string, error) {
+	var members []string
+	err := r.pool.Do(radix.Cmd(&members, "SMEMBERS", r.Config.Prefix+key))
+	return members, err
+}
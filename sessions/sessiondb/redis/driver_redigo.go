@@ -271,6 +271,32 @@ func (r *RedigoDriver) Delete(key string) error {
 	return err
 }
 
+// SetAdd adds the "member" to the set of the "key" using the "SADD" command.
+func (r *RedigoDriver) SetAdd(key, member string) error {
+	c := r.pool.Get()
+	defer c.Close()
+
+	_, err := c.Do("SADD", r.Config.Prefix+key, member)
+	return err
+}
+
+// SetRemove removes the "member" from the set of the "key" using the "SREM" command.
+func (r *RedigoDriver) SetRemove(key, member string) error {
+	c := r.pool.Get()
+	defer c.Close()
+
+	_, err := c.Do("SREM", r.Config.Prefix+key, member)
+	return err
+}
+
+// SetMembers returns the members of the set of the "key" using the "SMEMBERS" command.
+func (r *RedigoDriver) SetMembers(key string) ([]string, error) {
+	c := r.pool.Get()
+	defer c.Close()
+
+	return redis.Strings(c.Do("SMEMBERS", r.Config.Prefix+key))
+}
+
 func dial(network string, addr string, pass string, timeout time.Duration) (redis.Conn, error) {
 	if network == "" {
 		network = DefaultRedisNetwork
@@ -0,0 +1,716 @@
+package sqldb
+
+import (
+	stdContext "context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/sessions"
+
+	"github.com/kataras/golog"
+)
+
+const (
+	// DefaultTable the sessions table option, "iris_sessions".
+	// The session values are stored in its "_values" suffixed table
+	// and the session indexes in its "_index" suffixed one.
+	DefaultTable = "iris_sessions"
+	// DefaultBatchSize the batch size option, 1, the values are written on `Database.Set`.
+	DefaultBatchSize = 1
+	// DefaultMaxPending the maximum pending values option, 10000.
+	DefaultMaxPending = 10000
+	// DefaultFlushInterval the flush interval option, 1 second.
+	DefaultFlushInterval = time.Second
+	// DefaultGCInterval the garbage collector interval option, 30 minutes.
+	DefaultGCInterval = 30 * time.Minute
+)
+
+// Config the SQL session database configuration.
+type Config struct {
+	// Dialect of the SQL database, `SQLite`, `Postgres` or `MySQL`.
+	// Defaults to `SQLite`.
+	Dialect Dialect
+	// Table the name of the sessions table.
+	// The session values are stored in the "Table_values" table
+	// and the session indexes, see `sessions.Indexer`, in the "Table_index" table.
+	// Defaults to "iris_sessions".
+	Table string
+	// BatchSize the maximum number of the pending session value writes.
+	// A value of 1 writes each value on its `Database.Set` call, which returns the error of the write.
+	// A greater value enables batching, the values are written on a single transaction
+	// when the batch is full, every `FlushInterval` or before any other operation of the database.
+	// The error of a batch is returned to the call which fills it or reads the values,
+	// the error of a batch written every `FlushInterval` is logged.
+	// The values of a failed batch are kept and written on the next one, see `MaxPending`.
+	// Defaults to 1.
+	BatchSize int
+	// MaxPending the maximum number of the pending session values when batching is enabled,
+	// including the ones of the failed batches, it can't be less than the `BatchSize`.
+	// When it's reached the `Database.Set` returns the `ErrPendingFull` error.
+	// Defaults to 10000.
+	MaxPending int
+	// FlushInterval the interval of writing the pending session values, -1 disables it.
+	// Defaults to 1 second.
+	FlushInterval time.Duration
+	// GCInterval the interval of removing the expired sessions, -1 disables it.
+	// The expired sessions are removed on `New` as well.
+	// Defaults to 30 minutes.
+	GCInterval time.Duration
+}
+
+// DefaultConfig returns the default configuration for the SQL session database.
+func DefaultConfig() Config {
+	return Config{
+		Dialect:       SQLite,
+		Table:         DefaultTable,
+		BatchSize:     DefaultBatchSize,
+		MaxPending:    DefaultMaxPending,
+		FlushInterval: DefaultFlushInterval,
+		GCInterval:    DefaultGCInterval,
+	}
+}
+
+// Database the SQL (database/sql) session database.
+type Database struct {
+	c Config
+	// Service is the underline SQL database connection pool,
+	// it's the one which is passed on `New`.
+	Service *sql.DB
+
+	queries queries
+
+	mu      sync.Mutex
+	pending []pendingValue
+	writing int        // the number of the pending values which are being written.
+	flushMu sync.Mutex // serializes the writes of the pending values.
+
+	close     chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var (
+	_ sessions.DatabaseV2    = (*Database)(nil)
+	_ sessions.RegeneratorV2 = (*Database)(nil)
+	_ sessions.ListerV2      = (*Database)(nil)
+	_ sessions.Indexer       = (*Database)(nil)
+)
+
+type pendingValue struct {
+	sid   string
+	key   string
+	value []byte
+}
+
+type queries struct {
+	createSessions     string
+	createValues       string
+	selectExpiration   string
+	insertSession      string
+	updateExpiration   string
+	deleteSession      string
+	setValue           string
+	getValue           string
+	visitValues        string
+	countValues        string
+	deleteValue        string
+	deleteValues       string
+	deleteExpiredValue string
+	deleteExpired      string
+	regenerateSession  string
+	regenerateValues   string
+	visitSessions      string
+	createIndex        string
+	insertIndex        string
+	deleteIndex        string
+	deleteIndexes      string
+	deleteExpiredIndex string
+	regenerateIndex    string
+	visitIndex         string
+}
+
+var errServiceMissing = errors.New("sql database is required")
+
+// ErrPendingFull is returned by `Database.Set` when the pending session values
+// reached the `Config.MaxPending`, i.e the writes of their batches fail.
+var ErrPendingFull = errors.New("too many pending session values")
+
+// New returns a new SQL session database based on the "service" connection pool,
+// the session tables are created if they don't exist.
+//
+// Usage:
+//
+//	service, _ := sql.Open("sqlite3", "sessions.db")
+//	db, err := sqldb.New(service, sqldb.Config{Dialect: sqldb.SQLite})
+//	[...]
+//	sess.UseDatabaseV2(db)
+func New(service *sql.DB, cfg ...Config) (*Database, error) {
+	if service == nil {
+		golog.Error(errServiceMissing)
+		return nil, errServiceMissing
+	}
+
+	c := DefaultConfig()
+	if len(cfg) > 0 {
+		c = cfg[0]
+
+		if c.Dialect == nil {
+			c.Dialect = SQLite
+		}
+
+		if c.Table == "" {
+			c.Table = DefaultTable
+		}
+
+		if c.BatchSize <= 0 {
+			c.BatchSize = DefaultBatchSize
+		}
+
+		if c.MaxPending <= 0 {
+			c.MaxPending = DefaultMaxPending
+		}
+
+		if c.FlushInterval == 0 {
+			c.FlushInterval = DefaultFlushInterval
+		}
+
+		if c.GCInterval == 0 {
+			c.GCInterval = DefaultGCInterval
+		}
+	}
+
+	if c.MaxPending < c.BatchSize {
+		c.MaxPending = c.BatchSize
+	}
+
+	db := &Database{
+		c:       c,
+		Service: service,
+		close:   make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	db.queries = db.buildQueries()
+
+	for _, query := range []string{db.queries.createSessions, db.queries.createValues, db.queries.createIndex} {
+		if _, err := service.Exec(query); err != nil {
+			golog.Errorf("unable to create the session tables: %v", err)
+			return nil, err
+		}
+	}
+
+	if err := db.GC(); err != nil {
+		return nil, err
+	}
+
+	go db.run()
+	return db, nil
+}
+
+func (db *Database) buildQueries() queries {
+	sessionsTable, valuesTable, indexTable := db.c.Table, db.c.Table+"_values", db.c.Table+"_index"
+	d := db.c.Dialect
+
+	return queries{
+		createSessions: "CREATE TABLE IF NOT EXISTS " + sessionsTable + " (" +
+			"sid VARCHAR(255) NOT NULL PRIMARY KEY, expires_at BIGINT NOT NULL)",
+		createValues: "CREATE TABLE IF NOT EXISTS " + valuesTable + " (" +
+			"sid VARCHAR(255) NOT NULL, key_name VARCHAR(255) NOT NULL, value " + d.BlobType() + ", " +
+			"PRIMARY KEY (sid, key_name))",
+		selectExpiration: db.rebind("SELECT expires_at FROM " + sessionsTable + " WHERE sid = ?"),
+		insertSession:    db.rebind("INSERT INTO " + sessionsTable + " (sid, expires_at) VALUES (?, ?)"),
+		updateExpiration: db.rebind("UPDATE " + sessionsTable + " SET expires_at = ? WHERE sid = ?"),
+		deleteSession:    db.rebind("DELETE FROM " + sessionsTable + " WHERE sid = ?"),
+		setValue: d.Upsert(valuesTable, []string{"sid", "key_name"},
+			[]string{"sid", "key_name", "value"}, []string{"value"}),
+		getValue:     db.rebind("SELECT value FROM " + valuesTable + " WHERE sid = ? AND key_name = ?"),
+		visitValues:  db.rebind("SELECT key_name, value FROM " + valuesTable + " WHERE sid = ?"),
+		countValues:  db.rebind("SELECT COUNT(*) FROM " + valuesTable + " WHERE sid = ?"),
+		deleteValue:  db.rebind("DELETE FROM " + valuesTable + " WHERE sid = ? AND key_name = ?"),
+		deleteValues: db.rebind("DELETE FROM " + valuesTable + " WHERE sid = ?"),
+		deleteExpiredValue: db.rebind("DELETE FROM " + valuesTable + " WHERE sid IN " +
+			"(SELECT sid FROM " + sessionsTable + " WHERE expires_at > 0 AND expires_at < ?)"),
+		deleteExpired:     db.rebind("DELETE FROM " + sessionsTable + " WHERE expires_at > 0 AND expires_at < ?"),
+		regenerateSession: db.rebind("UPDATE " + sessionsTable + " SET sid = ? WHERE sid = ?"),
+		regenerateValues:  db.rebind("UPDATE " + valuesTable + " SET sid = ? WHERE sid = ?"),
+		visitSessions:     "SELECT sid, expires_at FROM " + sessionsTable,
+		createIndex: "CREATE TABLE IF NOT EXISTS " + indexTable + " (" +
+			"key_name VARCHAR(255) NOT NULL, value VARCHAR(255) NOT NULL, sid VARCHAR(255) NOT NULL, " +
+			"PRIMARY KEY (key_name, value, sid))",
+		insertIndex:   db.rebind("INSERT INTO " + indexTable + " (key_name, value, sid) VALUES (?, ?, ?)"),
+		deleteIndex:   db.rebind("DELETE FROM " + indexTable + " WHERE key_name = ? AND value = ? AND sid = ?"),
+		deleteIndexes: db.rebind("DELETE FROM " + indexTable + " WHERE sid = ?"),
+		deleteExpiredIndex: db.rebind("DELETE FROM " + indexTable + " WHERE sid IN " +
+			"(SELECT sid FROM " + sessionsTable + " WHERE expires_at > 0 AND expires_at < ?)"),
+		regenerateIndex: db.rebind("UPDATE " + indexTable + " SET sid = ? WHERE sid = ?"),
+		visitIndex: db.rebind("SELECT sid, expires_at FROM " + sessionsTable + " WHERE sid IN " +
+			"(SELECT sid FROM " + indexTable + " WHERE key_name = ? AND value = ?)"),
+	}
+}
+
+// rebind replaces the "?" bind parameters of the "query" with the dialect's ones.
+func (db *Database) rebind(query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(db.c.Dialect.Placeholder(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// run writes the pending values and removes the expired sessions periodically, until `Close`.
+func (db *Database) run() {
+	defer close(db.closed)
+
+	var flush, gc <-chan time.Time
+	if db.c.FlushInterval > 0 {
+		ticker := time.NewTicker(db.c.FlushInterval)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+
+	if db.c.GCInterval > 0 {
+		ticker := time.NewTicker(db.c.GCInterval)
+		defer ticker.Stop()
+		gc = ticker.C
+	}
+
+	for {
+		select {
+		case <-db.close:
+			return
+		case <-flush:
+			if err := db.flush(stdContext.Background()); err != nil {
+				golog.Errorf("Database.flush: unable to write the session values: %v", err)
+			}
+		case <-gc:
+			db.GC()
+		}
+	}
+}
+
+// GC removes the expired sessions and their values.
+// It's called periodically, see `Config.GCInterval`.
+func (db *Database) GC() error {
+	now := time.Now().UnixNano()
+	for _, query := range []string{db.queries.deleteExpiredValue, db.queries.deleteExpiredIndex, db.queries.deleteExpired} {
+		if _, err := db.Service.Exec(query, now); err != nil {
+			golog.Debugf("Database.GC: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flush writes the pending session values on a single transaction.
+// The values of a failed write are kept for the next one.
+func (db *Database) flush(ctx stdContext.Context) error {
+	db.flushMu.Lock()
+	defer db.flushMu.Unlock()
+
+	db.mu.Lock()
+	pending := db.pending
+	db.pending = nil
+	db.writing = len(pending)
+	db.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := db.transaction(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, db.queries.setValue)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, v := range pending {
+			if _, err = stmt.ExecContext(ctx, v.sid, v.key, v.value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	db.mu.Lock()
+	if err != nil {
+		// keep the values for the next write, before the ones that were set in the meantime.
+		db.pending = append(pending, db.pending...)
+	}
+	db.writing = 0
+	db.mu.Unlock()
+
+	return err
+}
+
+// discard removes the pending values of the "sid" session, all of them if the "key" is empty,
+// so a failed write does not bring them back after their removal.
+// It returns the number of the removed values.
+func (db *Database) discard(sid, key string) int {
+	db.mu.Lock()
+	pending := db.pending[:0]
+	for _, v := range db.pending {
+		if v.sid != sid || (key != "" && v.key != key) {
+			pending = append(pending, v)
+		}
+	}
+	n := len(db.pending) - len(pending)
+	db.pending = pending
+	db.mu.Unlock()
+
+	return n
+}
+
+func (db *Database) transaction(ctx stdContext.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Service.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func expiresAt(expires time.Duration) int64 {
+	if expires <= 0 {
+		return 0 // does not expire.
+	}
+
+	return time.Now().Add(expires).UnixNano()
+}
+
+// Acquire receives a session's lifetime from the database,
+// if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
+func (db *Database) Acquire(ctx stdContext.Context, sid string, expires time.Duration) (lifetime sessions.LifeTime, err error) {
+	err = db.transaction(ctx, func(tx *sql.Tx) error {
+		var expirationTime int64
+		err := tx.QueryRowContext(ctx, db.queries.selectExpiration, sid).Scan(&expirationTime)
+		if err == nil {
+			if expirationTime == 0 {
+				return nil // does not expire.
+			}
+
+			if t := time.Unix(0, expirationTime); t.After(time.Now()) {
+				lifetime = sessions.LifeTime{Time: t}
+				return nil
+			}
+
+			// expired, remove it and start a new one.
+			for _, query := range []string{db.queries.deleteValues, db.queries.deleteIndexes, db.queries.deleteSession} {
+				if _, err = tx.ExecContext(ctx, query, sid); err != nil {
+					return err
+				}
+			}
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		// not found, create the session entry with the given "expires",
+		// don't return a lifetime, let it empty, session manager will do its job.
+		_, err = tx.ExecContext(ctx, db.queries.insertSession, sid, expiresAt(expires))
+		return err
+	})
+
+	if err != nil {
+		lifetime = sessions.LifeTime{}
+	}
+
+	return
+}
+
+// OnUpdateExpiration will re-set the database's session's entry ttl.
+func (db *Database) OnUpdateExpiration(ctx stdContext.Context, sid string, newExpires time.Duration) error {
+	result, err := db.Service.ExecContext(ctx, db.queries.updateExpiration, expiresAt(newExpires), sid)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sessions.ErrNotFound
+	}
+
+	return nil
+}
+
+// Set sets a key value of a specific session.
+// The value is written immediately and the error of the write is returned,
+// unless batching is enabled, see `Config.BatchSize`.
+// Ignore the "immutable".
+func (db *Database) Set(ctx stdContext.Context, sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) error {
+	valueBytes, err := sessions.DefaultTranscoder.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if db.c.BatchSize == 1 {
+		_, err = db.Service.ExecContext(ctx, db.queries.setValue, sid, key, valueBytes)
+		return err
+	}
+
+	db.mu.Lock()
+	if len(db.pending)+db.writing >= db.c.MaxPending {
+		db.mu.Unlock()
+		return ErrPendingFull
+	}
+	db.pending = append(db.pending, pendingValue{sid: sid, key: key, value: valueBytes})
+	full := len(db.pending) >= db.c.BatchSize
+	db.mu.Unlock()
+
+	if full {
+		return db.flush(ctx)
+	}
+
+	return nil
+}
+
+// Get retrieves a session value based on the key.
+// The pending values are written first, their error is returned.
+func (db *Database) Get(ctx stdContext.Context, sid string, key string) (value interface{}, err error) {
+	if err = db.flush(ctx); err != nil {
+		return
+	}
+
+	var valueBytes []byte
+	err = db.Service.QueryRowContext(ctx, db.queries.getValue, sid, key).Scan(&valueBytes)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+
+		return
+	}
+
+	err = sessions.DefaultTranscoder.Unmarshal(valueBytes, &value)
+	return
+}
+
+// Visit loops through all session keys and values.
+func (db *Database) Visit(ctx stdContext.Context, sid string, cb func(key string, value interface{})) error {
+	if err := db.flush(ctx); err != nil {
+		return err
+	}
+
+	rows, err := db.Service.QueryContext(ctx, db.queries.visitValues, sid)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key        string
+			valueBytes []byte
+			value      interface{}
+		)
+
+		if err = rows.Scan(&key, &valueBytes); err != nil {
+			return err
+		}
+
+		if err = sessions.DefaultTranscoder.Unmarshal(valueBytes, &value); err != nil {
+			return err
+		}
+
+		cb(key, value)
+	}
+
+	return rows.Err()
+}
+
+// Len returns the length of the session's entries (keys).
+func (db *Database) Len(ctx stdContext.Context, sid string) (n int, err error) {
+	if err = db.flush(ctx); err != nil {
+		return
+	}
+
+	err = db.Service.QueryRowContext(ctx, db.queries.countValues, sid).Scan(&n)
+	return
+}
+
+// Delete removes a session key value based on its key.
+func (db *Database) Delete(ctx stdContext.Context, sid string, key string) (deleted bool, err error) {
+	if err = db.flush(ctx); err != nil {
+		deleted = db.discard(sid, key) > 0
+	}
+
+	result, err := db.Service.ExecContext(ctx, db.queries.deleteValue, sid, key)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return deleted || (err == nil && n > 0), nil
+}
+
+// Clear removes all session key values but it keeps the session entry.
+func (db *Database) Clear(ctx stdContext.Context, sid string) error {
+	if err := db.flush(ctx); err != nil {
+		db.discard(sid, "")
+	}
+
+	_, err := db.Service.ExecContext(ctx, db.queries.deleteValues, sid)
+	return err
+}
+
+// Release destroys the session, it clears and removes the session entry,
+// session manager will create a new session ID on the next request after this call.
+func (db *Database) Release(ctx stdContext.Context, sid string) error {
+	if err := db.flush(ctx); err != nil {
+		db.discard(sid, "")
+	}
+
+	return db.transaction(ctx, func(tx *sql.Tx) error {
+		for _, query := range []string{db.queries.deleteValues, db.queries.deleteIndexes, db.queries.deleteSession} {
+			if _, err := tx.ExecContext(ctx, query, sid); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Regenerate moves the session entry, its values and its index entries to the "newSID" session.
+func (db *Database) Regenerate(ctx stdContext.Context, oldSID, newSID string) error {
+	// the pending values of the old session should be moved too.
+	if err := db.flush(ctx); err != nil {
+		return err
+	}
+
+	return db.transaction(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, db.queries.regenerateSession, newSID, oldSID)
+		if err != nil {
+			return err
+		}
+
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return sessions.ErrNotFound
+		}
+
+		for _, query := range []string{db.queries.regenerateValues, db.queries.regenerateIndex} {
+			if _, err = tx.ExecContext(ctx, query, newSID, oldSID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// VisitSessions loops through the session entries and their lifetime.
+func (db *Database) VisitSessions(ctx stdContext.Context, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	return db.visitSessions(ctx, cb, db.queries.visitSessions)
+}
+
+// Index adds the "sid" session to the sessions of the "key" with the "value".
+// It returns the `sessions.ErrNotFound` if the session was not acquired.
+func (db *Database) Index(ctx stdContext.Context, sid, key, value string) error {
+	return db.transaction(ctx, func(tx *sql.Tx) error {
+		var expirationTime int64
+		if err := tx.QueryRowContext(ctx, db.queries.selectExpiration, sid).Scan(&expirationTime); err != nil {
+			if err == sql.ErrNoRows {
+				return sessions.ErrNotFound
+			}
+			return err
+		}
+
+		// the previous entry is removed, the insert statement is the same for all dialects.
+		for _, query := range []string{db.queries.deleteIndex, db.queries.insertIndex} {
+			if _, err := tx.ExecContext(ctx, query, key, value, sid); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Unindex removes the "sid" session from the sessions of the "key" with the "value".
+func (db *Database) Unindex(ctx stdContext.Context, sid, key, value string) error {
+	_, err := db.Service.ExecContext(ctx, db.queries.deleteIndex, key, value, sid)
+	return err
+}
+
+// VisitIndex loops through the sessions of the "key" with the "value" and their lifetime.
+func (db *Database) VisitIndex(ctx stdContext.Context, key, value string, cb func(sid string, lifetime sessions.LifeTime) bool) error {
+	return db.visitSessions(ctx, cb, db.queries.visitIndex, key, value)
+}
+
+// visitSessions calls the "cb" with the session entries of the "query".
+func (db *Database) visitSessions(ctx stdContext.Context, cb func(sid string, lifetime sessions.LifeTime) bool, query string, args ...interface{}) error {
+	type entry struct {
+		sid       string
+		expiresAt int64
+	}
+
+	rows, err := db.Service.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	// the rows are read first, so the "cb" can use the database.
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err = rows.Scan(&e.sid, &e.expiresAt); err != nil {
+			rows.Close()
+			return err
+		}
+
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		var lifetime sessions.LifeTime
+		if e.expiresAt > 0 {
+			lifetime.Time = time.Unix(0, e.expiresAt)
+		}
+
+		if !cb(e.sid, lifetime) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Close writes the pending session values, stops the garbage collector
+// and closes the SQL database connection pool.
+func (db *Database) Close() error {
+	db.closeOnce.Do(func() {
+		close(db.close)
+		<-db.closed
+	})
+
+	flushErr := db.flush(stdContext.Background())
+	if err := db.Service.Close(); err != nil {
+		golog.Warnf("closing the SQL database connection: %v", err)
+		return err
+	}
+
+	return flushErr
+}
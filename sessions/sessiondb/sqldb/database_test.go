@@ -0,0 +1,393 @@
+//go:build cgo
+// +build cgo
+
+package sqldb_test
+
+import (
+	stdContext "context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/kataras/iris/v12/sessions"
+	"github.com/kataras/iris/v12/sessions/sessiondb/sqldb"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openSQLite returns a database of a new SQLite file,
+// the returned function closes the database and removes the file.
+func openSQLite(t *testing.T, cfg sqldb.Config) (*sqldb.Database, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "iris-sessions-sqldb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := sql.Open("sqlite3", "file:"+filepath.Join(dir, "sessions.db")+"?_busy_timeout=5000")
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	db, err := sqldb.New(service, cfg)
+	if err != nil {
+		service.Close()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// count returns the number of the rows of the "query".
+func count(t *testing.T, db *sqldb.Database, query string, args ...interface{}) (n int) {
+	t.Helper()
+
+	if err := db.Service.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// failWrites makes the session value writes to fail, until the returned function is called.
+func failWrites(t *testing.T, db *sqldb.Database) func() {
+	t.Helper()
+
+	_, err := db.Service.Exec("CREATE TRIGGER fail_writes BEFORE INSERT ON iris_sessions_values " +
+		"BEGIN SELECT RAISE(ABORT, 'write failed'); END")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return func() {
+		t.Helper()
+
+		if _, err := db.Service.Exec("DROP TRIGGER fail_writes"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDatabase(t *testing.T) {
+	db, closeDB := openSQLite(t, sqldb.Config{BatchSize: 10, FlushInterval: -1, GCInterval: -1})
+	defer closeDB()
+
+	ctx := stdContext.Background()
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lifetime, err := db.Acquire(ctx, "sid", time.Hour)
+	check(err)
+	if !lifetime.IsZero() {
+		t.Fatalf("expected an empty lifetime for a new session but got: %v", lifetime.Time)
+	}
+	lifetime, err = db.Acquire(ctx, "sid", time.Hour)
+	check(err)
+	if lifetime.IsZero() || !lifetime.After(time.Now()) {
+		t.Fatalf("expected the stored lifetime of the session but got: %v", lifetime.Time)
+	}
+
+	check(db.Set(ctx, "sid", sessions.LifeTime{}, "name", "iris", false))
+	check(db.Set(ctx, "sid", sessions.LifeTime{}, "secret", "value", false))
+	if n := count(t, db, "SELECT COUNT(*) FROM iris_sessions_values"); n != 0 {
+		t.Fatalf("expected the values to be batched but %d were written", n)
+	}
+
+	got, err := db.Get(ctx, "sid", "name")
+	check(err)
+	if got != "iris" {
+		t.Fatalf("expected value 'iris' but got: %v", got)
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM iris_sessions_values"); n != 2 {
+		t.Fatalf("expected the batch of 2 values to be written before a read but %d were written", n)
+	}
+	if got, err = db.Get(ctx, "sid", "unknown"); err != nil || got != nil {
+		t.Fatalf("expected no value and no error for a missing key but got: %v, %v", got, err)
+	}
+	n, err := db.Len(ctx, "sid")
+	check(err)
+	if n != 2 {
+		t.Fatalf("expected 2 values but got: %d", n)
+	}
+
+	visited := make(map[string]interface{})
+	check(db.Visit(ctx, "sid", func(key string, value interface{}) {
+		visited[key] = value
+	}))
+	if expected := map[string]interface{}{"name": "iris", "secret": "value"}; fmt.Sprint(visited) != fmt.Sprint(expected) {
+		t.Fatalf("expected visited values: %v but got: %v", expected, visited)
+	}
+
+	deleted, err := db.Delete(ctx, "sid", "name")
+	check(err)
+	deletedAgain, err := db.Delete(ctx, "sid", "name")
+	check(err)
+	if !deleted || deletedAgain {
+		t.Fatalf("expected the value to be deleted only once")
+	}
+
+	check(db.OnUpdateExpiration(ctx, "sid", 2*time.Hour))
+	if err = db.OnUpdateExpiration(ctx, "unknown", time.Hour); err != sessions.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+
+	check(db.Clear(ctx, "sid"))
+	if n, err = db.Len(ctx, "sid"); err != nil || n != 0 {
+		t.Fatalf("expected no values after Clear but got: %d, %v", n, err)
+	}
+
+	check(db.Set(ctx, "sid", sessions.LifeTime{}, "name", "iris", false))
+	check(db.Index(ctx, "sid", "name", "iris"))
+	check(db.Index(ctx, "sid", "name", "iris"))
+	if err = db.Index(ctx, "unknown", "name", "iris"); err != sessions.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+	indexed := func() (sids []string) {
+		t.Helper()
+		check(db.VisitIndex(ctx, "name", "iris", func(sid string, lifetime sessions.LifeTime) bool {
+			if lifetime.IsZero() {
+				t.Fatalf("expected the lifetime of the indexed session")
+			}
+			sids = append(sids, sid)
+			return true
+		}))
+		return
+	}
+	if sids := indexed(); len(sids) != 1 || sids[0] != "sid" {
+		t.Fatalf("expected the indexed session but got: %v", sids)
+	}
+
+	check(db.Regenerate(ctx, "sid", "new"))
+	if sids := indexed(); len(sids) != 1 || sids[0] != "new" {
+		t.Fatalf("expected the index to be moved to the new session but got: %v", sids)
+	}
+	if got, err = db.Get(ctx, "new", "name"); err != nil || got != "iris" {
+		t.Fatalf("expected the values to be moved to the new session but got: %v, %v", got, err)
+	}
+	if n, err = db.Len(ctx, "sid"); err != nil || n != 0 || count(t, db, "SELECT COUNT(*) FROM iris_sessions WHERE sid = 'sid'") != 0 {
+		t.Fatalf("expected the old session to be removed after Regenerate")
+	}
+	if err = db.Regenerate(ctx, "sid", "new"); err != sessions.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+
+	check(db.Release(ctx, "new"))
+	if count(t, db, "SELECT COUNT(*) FROM iris_sessions WHERE sid = 'new'") != 0 ||
+		count(t, db, "SELECT COUNT(*) FROM iris_sessions_values WHERE sid = 'new'") != 0 || len(indexed()) > 0 {
+		t.Fatalf("expected the session to be removed after Release")
+	}
+
+	_, err = db.Acquire(ctx, "other", time.Hour)
+	check(err)
+	check(db.Index(ctx, "other", "name", "iris"))
+	check(db.Unindex(ctx, "other", "name", "iris"))
+	if sids := indexed(); len(sids) != 0 {
+		t.Fatalf("expected the session to be removed from the index but got: %v", sids)
+	}
+
+	canceled, cancel := stdContext.WithCancel(ctx)
+	cancel()
+	if _, err = db.Get(canceled, "sid", "name"); err != stdContext.Canceled {
+		t.Fatalf("expected the error of the canceled context but got: %v", err)
+	}
+}
+
+func TestDatabaseFailedWrite(t *testing.T) {
+	db, closeDB := openSQLite(t, sqldb.Config{BatchSize: 10, FlushInterval: -1, GCInterval: -1})
+	defer closeDB()
+
+	ctx := stdContext.Background()
+	db.Acquire(ctx, "sid", time.Hour)
+	db.Set(ctx, "sid", sessions.LifeTime{}, "name", "iris", false)
+	db.Set(ctx, "sid", sessions.LifeTime{}, "secret", "value", false)
+
+	restore := failWrites(t, db)
+	if got, err := db.Get(ctx, "sid", "name"); err == nil || got != nil {
+		t.Fatalf("expected the error of the failed write but got: %v, %v", got, err)
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM iris_sessions_values"); n != 0 {
+		t.Fatalf("expected the failed write to be rolled back but %d values were written", n)
+	}
+	restore()
+	if got, err := db.Get(ctx, "sid", "name"); err != nil || got != "iris" {
+		t.Fatalf("expected the values of the failed write to be written on the next one but got: %v, %v", got, err)
+	}
+	if n, err := db.Len(ctx, "sid"); err != nil || n != 2 {
+		t.Fatalf("expected 2 values but got: %d, %v", n, err)
+	}
+
+	// a removed value is not written by the next write.
+	db.Set(ctx, "sid", sessions.LifeTime{}, "removed", "value", false)
+	restore = failWrites(t, db)
+	if deleted, err := db.Delete(ctx, "sid", "removed"); err != nil || !deleted {
+		t.Fatalf("expected the pending value to be deleted but got: %v, %v", deleted, err)
+	}
+	restore()
+	if got, err := db.Get(ctx, "sid", "removed"); err != nil || got != nil {
+		t.Fatalf("expected the deleted value to not be written but got: %v, %v", got, err)
+	}
+
+	// the session is not moved without its pending values.
+	db.Set(ctx, "sid", sessions.LifeTime{}, "pending", "value", false)
+	restore = failWrites(t, db)
+	if err := db.Regenerate(ctx, "sid", "new"); err == nil {
+		t.Fatalf("expected the error of the failed write")
+	}
+	restore()
+	if err := db.Regenerate(ctx, "sid", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.Get(ctx, "new", "pending"); err != nil || got != "value" {
+		t.Fatalf("expected the pending value to be moved to the new session but got: %v, %v", got, err)
+	}
+
+	// the writer which fills the batch receives its error.
+	db.Set(ctx, "new", sessions.LifeTime{}, "filled", "value", false)
+	restore = failWrites(t, db)
+	for i := 0; i < 8; i++ {
+		if err := db.Set(ctx, "new", sessions.LifeTime{}, fmt.Sprintf("key-%d", i), i, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Set(ctx, "new", sessions.LifeTime{}, "full", "value", false); err == nil {
+		t.Fatalf("expected the error of the failed write")
+	}
+	restore()
+	if n, err := db.Len(ctx, "new"); err != nil || n != 13 {
+		t.Fatalf("expected 13 values but got: %d, %v", n, err)
+	}
+}
+
+func TestDatabaseSetError(t *testing.T) {
+	// the values are written on Set by default.
+	db, closeDB := openSQLite(t, sqldb.Config{FlushInterval: -1, GCInterval: -1})
+	defer closeDB()
+
+	ctx := stdContext.Background()
+	db.Acquire(ctx, "sid", time.Hour)
+	if err := db.Set(ctx, "sid", sessions.LifeTime{}, "name", "iris", false); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM iris_sessions_values"); n != 1 {
+		t.Fatalf("expected the value to be written on Set but %d values were written", n)
+	}
+
+	restore := failWrites(t, db)
+	if err := db.Set(ctx, "sid", sessions.LifeTime{}, "failed", "value", false); err == nil {
+		t.Fatalf("expected the error of the failed write")
+	}
+	restore()
+	// the failed value is not kept.
+	if got, err := db.Get(ctx, "sid", "failed"); err != nil || got != nil {
+		t.Fatalf("expected the failed value to not be written but got: %v, %v", got, err)
+	}
+}
+
+func TestDatabaseMaxPending(t *testing.T) {
+	db, closeDB := openSQLite(t, sqldb.Config{BatchSize: 2, MaxPending: 3, FlushInterval: -1, GCInterval: -1})
+	defer closeDB()
+
+	ctx := stdContext.Background()
+	db.Acquire(ctx, "sid", time.Hour)
+
+	restore := failWrites(t, db)
+	db.Set(ctx, "sid", sessions.LifeTime{}, "a", "value", false)
+	if err := db.Set(ctx, "sid", sessions.LifeTime{}, "b", "value", false); err == nil {
+		t.Fatalf("expected the error of the failed write")
+	}
+	if err := db.Set(ctx, "sid", sessions.LifeTime{}, "c", "value", false); err == nil {
+		t.Fatalf("expected the error of the failed write")
+	}
+	if err := db.Set(ctx, "sid", sessions.LifeTime{}, "d", "value", false); err != sqldb.ErrPendingFull {
+		t.Fatalf("expected the pending full error but got: %v", err)
+	}
+	restore()
+
+	if n, err := db.Len(ctx, "sid"); err != nil || n != 3 {
+		t.Fatalf("expected the 3 pending values to be written but got: %d, %v", n, err)
+	}
+	if err := db.Set(ctx, "sid", sessions.LifeTime{}, "d", "value", false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDatabaseGC(t *testing.T) {
+	db, closeDB := openSQLite(t, sqldb.Config{BatchSize: 1, FlushInterval: -1, GCInterval: -1})
+	defer closeDB()
+
+	ctx := stdContext.Background()
+	db.Acquire(ctx, "expired", time.Millisecond)
+	db.Set(ctx, "expired", sessions.LifeTime{}, "name", "iris", false)
+	db.Acquire(ctx, "unlimited", 0)
+	db.Set(ctx, "unlimited", sessions.LifeTime{}, "name", "iris", false)
+	time.Sleep(5 * time.Millisecond)
+
+	if err := db.GC(); err != nil {
+		t.Fatal(err)
+	}
+
+	if count(t, db, "SELECT COUNT(*) FROM iris_sessions WHERE sid = 'expired'") != 0 ||
+		count(t, db, "SELECT COUNT(*) FROM iris_sessions_values WHERE sid = 'expired'") != 0 {
+		t.Fatalf("expected the expired session to be removed")
+	}
+	if got, err := db.Get(ctx, "unlimited", "name"); err != nil || got != "iris" {
+		t.Fatalf("expected the unlimited session to be kept but got: %v, %v", got, err)
+	}
+
+	var listed []string
+	err := db.VisitSessions(ctx, func(sid string, lifetime sessions.LifeTime) bool {
+		if !lifetime.IsZero() {
+			t.Fatalf("expected an empty lifetime for the unlimited session but got: %v", lifetime.Time)
+		}
+		listed = append(listed, sid)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0] != "unlimited" {
+		t.Fatalf("expected only the unlimited session to be listed but got: %v", listed)
+	}
+}
+
+func TestSessionsDatabase(t *testing.T) {
+	db, closeDB := openSQLite(t, sqldb.DefaultConfig())
+	defer closeDB()
+
+	sess := sessions.New(sessions.Config{Cookie: "sessionid", Expires: time.Hour})
+	sess.UseDatabaseV2(db)
+
+	app := iris.New()
+	app.Get("/set/{value}", func(ctx context.Context) {
+		sess.Start(ctx).Set("value", ctx.Params().Get("value"))
+	})
+	app.Get("/get", func(ctx context.Context) {
+		ctx.WriteString(sess.Start(ctx).GetString("value"))
+	})
+	app.Get("/destroy", func(ctx context.Context) {
+		sess.Destroy(ctx)
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/set/iris").Expect().Status(iris.StatusOK).Cookies().NotEmpty()
+	e.GET("/get").Expect().Status(iris.StatusOK).Body().Equal("iris")
+	e.GET("/destroy").Expect().Status(iris.StatusOK)
+	e.GET("/get").Expect().Status(iris.StatusOK).Body().Empty()
+}
@@ -0,0 +1,78 @@
+package sqldb
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the SQL syntax differences of a database,
+// the `SQLite`, `Postgres` and `MySQL` dialects are available.
+type Dialect interface {
+	// Placeholder returns the bind parameter of the "n"th (starting from 1) argument of a statement.
+	Placeholder(n int) string
+	// BlobType returns the column type of the serialized session values.
+	BlobType() string
+	// Upsert returns a statement which inserts a row of the "columns" to the "table"
+	// or updates its "update" columns if a row with the same "keys" already exists.
+	// The statement should use the `Placeholder` for the values of the "columns", in order.
+	Upsert(table string, keys, columns, update []string) string
+}
+
+var (
+	// SQLite is the dialect of the SQLite 3.24+ databases.
+	SQLite Dialect = sqliteDialect{}
+	// Postgres is the dialect of the PostgreSQL 9.5+ databases.
+	Postgres Dialect = postgresDialect{}
+	// MySQL is the dialect of the MySQL and MariaDB databases.
+	MySQL Dialect = mysqlDialect{}
+)
+
+func placeholders(d Dialect, n int) string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = d.Placeholder(i + 1)
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// onConflict returns the upsert statement of the SQLite and PostgreSQL databases.
+func onConflict(d Dialect, table string, keys, columns, update []string) string {
+	set := make([]string, len(update))
+	for i, column := range update {
+		set[i] = column + " = excluded." + column
+	}
+
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders(d, len(columns)) + ")" +
+		" ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(set, ", ")
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(int) string { return "?" }
+func (sqliteDialect) BlobType() string       { return "BLOB" }
+func (d sqliteDialect) Upsert(table string, keys, columns, update []string) string {
+	return onConflict(d, table, keys, columns, update)
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+func (postgresDialect) BlobType() string         { return "BYTEA" }
+func (d postgresDialect) Upsert(table string, keys, columns, update []string) string {
+	return onConflict(d, table, keys, columns, update)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Placeholder(int) string { return "?" }
+func (mysqlDialect) BlobType() string       { return "LONGBLOB" }
+func (d mysqlDialect) Upsert(table string, keys, columns, update []string) string {
+	set := make([]string, len(update))
+	for i, column := range update {
+		set[i] = column + " = VALUES(" + column + ")"
+	}
+
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders(d, len(columns)) + ")" +
+		" ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+}
@@ -0,0 +1,38 @@
+package sqldb_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/v12/sessions/sessiondb/sqldb"
+)
+
+func TestDialects(t *testing.T) {
+	tests := []struct {
+		name         string
+		dialect      sqldb.Dialect
+		placeholders [2]string
+		blobType     string
+		upsert       string
+	}{
+		{"sqlite", sqldb.SQLite, [2]string{"?", "?"}, "BLOB",
+			"INSERT INTO t (a, b, c) VALUES (?, ?, ?) ON CONFLICT (a, b) DO UPDATE SET c = excluded.c"},
+		{"postgres", sqldb.Postgres, [2]string{"$1", "$2"}, "BYTEA",
+			"INSERT INTO t (a, b, c) VALUES ($1, $2, $3) ON CONFLICT (a, b) DO UPDATE SET c = excluded.c"},
+		{"mysql", sqldb.MySQL, [2]string{"?", "?"}, "LONGBLOB",
+			"INSERT INTO t (a, b, c) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE c = VALUES(c)"},
+	}
+
+	for _, tt := range tests {
+		if got := [2]string{tt.dialect.Placeholder(1), tt.dialect.Placeholder(2)}; got != tt.placeholders {
+			t.Errorf("[%s] expected placeholders: %v but got: %v", tt.name, tt.placeholders, got)
+		}
+
+		if got := tt.dialect.BlobType(); got != tt.blobType {
+			t.Errorf("[%s] expected blob type: %s but got: %s", tt.name, tt.blobType, got)
+		}
+
+		if got := tt.dialect.Upsert("t", []string{"a", "b"}, []string{"a", "b", "c"}, []string{"c"}); got != tt.upsert {
+			t.Errorf("[%s] expected upsert statement:\n%s\nbut got:\n%s", tt.name, tt.upsert, got)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sessions
 
 import (
+	stdContext "context"
 	"net/http"
 	"time"
 
@@ -21,27 +22,55 @@ type Sessions struct {
 
 // New returns a new fast, feature-rich sessions manager
 // it can be adapted to an iris station
+//
+// It panics if the `Config.CookieStore` is set without any keys.
 func New(cfg Config) *Sessions {
-	return &Sessions{
+	s := &Sessions{
 		config:   cfg.Validate(),
 		provider: newProvider(),
 	}
+
+	if s.config.CookieStore != nil {
+		s.validateCookieStore()
+	}
+
+	return s
 }
 
 // UseDatabase adds a session database to the manager's provider,
 // a session db doesn't have write access
 func (s *Sessions) UseDatabase(db Database) {
+	s.provider.RegisterDatabase(AdaptDatabase(db))
+}
+
+// UseDatabaseV2 same as `UseDatabase` but it accepts a context-aware session database,
+// which reports its errors.
+func (s *Sessions) UseDatabaseV2(db DatabaseV2) {
 	s.provider.RegisterDatabase(db)
 }
 
 // updateCookie gains the ability of updating the session browser cookie to any method which wants to update it
 func (s *Sessions) updateCookie(ctx context.Context, sid string, expires time.Duration, options ...context.CookieOption) {
+	cookie := s.newCookie(ctx, sid, expires)
+
+	// encode the session id cookie client value right before send it.
+	cookie.Value = s.encodeCookieValue(cookie.Value)
+
+	for _, opt := range options {
+		opt(cookie)
+	}
+
+	AddCookie(ctx, cookie, s.config.AllowReclaim)
+}
+
+// newCookie returns a session cookie with the "value" which expires after "expires".
+func (s *Sessions) newCookie(ctx context.Context, value string, expires time.Duration) *http.Cookie {
 	cookie := &http.Cookie{}
 
 	// The RFC makes no mention of encoding url value, so here I think to encode both sessionid key and the value using the safe(to put and to use as cookie) url-encoding
 	cookie.Name = s.config.Cookie
 
-	cookie.Value = sid
+	cookie.Value = value
 	cookie.Path = "/"
 	cookie.Domain = formatCookieDomain(ctx, s.config.DisableSubdomainPersistence)
 	cookie.HttpOnly = true
@@ -67,45 +96,85 @@ func (s *Sessions) updateCookie(ctx context.Context, sid string, expires time.Du
 		cookie.Secure = true
 	}
 
-	// encode the session id cookie client value right before send it.
-	cookie.Value = s.encodeCookieValue(cookie.Value)
-
-	for _, opt := range options {
-		opt(cookie)
-	}
-
-	AddCookie(ctx, cookie, s.config.AllowReclaim)
+	return cookie
 }
 
 // Start creates or retrieves an existing session for the particular request.
 func (s *Sessions) Start(ctx context.Context, cookieOptions ...context.CookieOption) *Session {
+	if s.config.CookieStore != nil {
+		return s.startCookieStore(ctx, cookieOptions...)
+	}
+
 	cookieValue := s.decodeCookieValue(GetCookie(ctx, s.config.Cookie))
 
 	if cookieValue == "" { // cookie doesn't exist, let's generate a session and set a cookie.
 		sid := s.config.SessionIDGenerator(ctx)
 
-		sess := s.provider.Init(sid, s.config.Expires)
-		sess.isNew = s.provider.db.Len(sid) == 0
+		sess, err := s.provider.Init(ctx.Request().Context(), sid, s.config.Expires)
+		sess = sess.forRequest(ctx, s.errKey())
+		sess.handleErr(err)
+
+		n, err := sess.LenContext(ctx.Request().Context())
+		sess.handleErr(err)
+		sess.isNew = n == 0
 
 		s.updateCookie(ctx, sid, s.config.Expires, cookieOptions...)
 
 		return sess
 	}
 
-	return s.provider.Read(cookieValue, s.config.Expires)
+	sess, err := s.provider.Read(ctx.Request().Context(), cookieValue, s.config.Expires)
+	sess = sess.forRequest(ctx, s.errKey())
+	sess.handleErr(err)
+	return sess
 }
 
-const contextSessionKey = "_iris_session"
+const (
+	contextSessionKey      = "_iris_session"
+	contextSessionErrorKey = "_iris_session_error_"
+)
+
+// errKey returns the key of the request's values which keeps the session database errors.
+func (s *Sessions) errKey() string {
+	return contextSessionErrorKey + s.config.Cookie
+}
 
 // Handler returns a sessions middleware to register on application routes.
+//
+// The session database errors, see `Session.Err`, are reported to the `Config.OnError`
+// after the session is started and after the next handlers are executed.
+// The next handlers are not executed if the `Config.OnError` stops the execution, i.e. `ctx.StopExecution()`.
 func (s *Sessions) Handler(cookieOptions ...context.CookieOption) context.Handler {
 	return func(ctx context.Context) {
 		session := s.Start(ctx, cookieOptions...)
 		ctx.Values().Set(contextSessionKey, session)
+
+		err := session.Err()
+		if err != nil {
+			s.handleError(ctx, err)
+			if ctx.IsStopped() {
+				return
+			}
+		}
+
 		ctx.Next()
+
+		if nextErr := session.Err(); nextErr != nil && nextErr != err {
+			s.handleError(ctx, nextErr)
+		}
 	}
 }
 
+// handleError reports a session database error to the `Config.OnError`.
+func (s *Sessions) handleError(ctx context.Context, err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(ctx, err)
+		return
+	}
+
+	ctx.Application().Logger().Errorf("sessions: %v", err)
+}
+
 // Get returns a *Session from the same request life cycle,
 // can be used inside a chain of handlers of a route.
 //
@@ -140,13 +209,17 @@ func (s *Sessions) ShiftExpiration(ctx context.Context, cookieOptions ...context
 // It will return `ErrNotFound` when trying to update expiration on a non-existence or not valid session entry.
 // It will return `ErrNotImplemented` if a database is used and it does not support this feature, yet.
 func (s *Sessions) UpdateExpiration(ctx context.Context, expires time.Duration, cookieOptions ...context.CookieOption) error {
+	if s.config.CookieStore != nil {
+		return s.updateCookieStoreExpiration(ctx, expires, cookieOptions...)
+	}
+
 	cookieValue := s.decodeCookieValue(GetCookie(ctx, s.config.Cookie))
 	if cookieValue == "" {
 		return ErrNotFound
 	}
 
 	// we should also allow it to expire when the browser closed
-	err := s.provider.UpdateExpiration(cookieValue, expires)
+	err := s.provider.UpdateExpiration(ctx.Request().Context(), cookieValue, expires)
 	if err == nil || expires == -1 {
 		s.updateCookie(ctx, cookieValue, expires, cookieOptions...)
 	}
@@ -168,8 +241,49 @@ func (s *Sessions) OnDestroy(listeners ...DestroyListener) {
 	}
 }
 
+// RegenerateListener is the form of a regenerate listener.
+// Look `OnRegenerate` for more.
+type RegenerateListener func(oldSID, newSID string)
+
+// OnRegenerate registers one or more regenerate listeners.
+// A regenerate listener is fired when the entries of a session have been moved to a new session id, see `Regenerate`.
+func (s *Sessions) OnRegenerate(listeners ...RegenerateListener) {
+	for _, ln := range listeners {
+		s.provider.registerRegenerateListener(ln)
+	}
+}
+
+// Regenerate changes the id of the request's session, its values, including the flash messages
+// and the immutable entries, are kept and the session cookie is sent again with the new id.
+// The session is started if it's not already.
+// The regenerate listeners are fired on success, see `OnRegenerate`.
+//
+// It should be called when the privileges of the session change, i.e. after login,
+// to protect against session fixation attacks.
+//
+// If the session database fails to move the values then the error is returned
+// and the session keeps its old id.
+func (s *Sessions) Regenerate(ctx context.Context, cookieOptions ...context.CookieOption) (*Session, error) {
+	sess := s.Start(ctx, cookieOptions...)
+	if err := sess.provider.Regenerate(ctx.Request().Context(), sess, s.config.SessionIDGenerator(ctx)); err != nil {
+		return sess, err
+	}
+
+	if s.config.CookieStore == nil {
+		s.updateCookie(ctx, sess.sid, s.config.Expires, cookieOptions...)
+	}
+
+	return sess, nil
+}
+
 // Destroy remove the session data and remove the associated cookie.
+// The session database error, if any, is reported to the `Config.OnError`.
 func (s *Sessions) Destroy(ctx context.Context) {
+	if s.config.CookieStore != nil {
+		s.destroyCookieStore(ctx)
+		return
+	}
+
 	cookieValue := GetCookie(ctx, s.config.Cookie)
 	// decode the client's cookie value in order to find the server's session id
 	// to destroy the session data.
@@ -179,7 +293,9 @@ func (s *Sessions) Destroy(ctx context.Context) {
 	}
 	RemoveCookie(ctx, s.config)
 
-	s.provider.Destroy(cookieValue)
+	if err := s.provider.Destroy(ctx.Request().Context(), cookieValue); err != nil {
+		s.handleError(ctx, err)
+	}
 }
 
 // DestroyByID removes the session entry
@@ -187,16 +303,18 @@ func (s *Sessions) Destroy(ctx context.Context) {
 // Client's session cookie will still exist but it will be reseted on the next request.
 //
 // It's safe to use it even if you are not sure if a session with that id exists.
+// It does nothing when the `Config.CookieStore` is used.
 //
 // Note: the sid should be the original one (i.e: fetched by a store )
 // it's not decoded.
 func (s *Sessions) DestroyByID(sid string) {
-	s.provider.Destroy(sid)
+	s.provider.Destroy(stdContext.Background(), sid)
 }
 
 // DestroyAll removes all sessions
 // from the server-side memory (and database if registered).
 // Client's session cookie will still exist but it will be reseted on the next request.
+// It does nothing when the `Config.CookieStore` is used.
 func (s *Sessions) DestroyAll() {
 	s.provider.DestroyAll()
 }
@@ -1,12 +1,19 @@
 package sessions_test
 
 import (
+	stdContext "context"
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/context"
 	"github.com/kataras/iris/v12/httptest"
 	"github.com/kataras/iris/v12/sessions"
+
+	"github.com/gavv/httpexpect"
 )
 
 func TestSessions(t *testing.T) {
@@ -110,9 +117,19 @@ func testSessions(t *testing.T, sess *sessions.Sessions, app *iris.Application)
 }
 
 func TestFlashMessages(t *testing.T) {
-	app := iris.New()
+	t.Run("memory", func(t *testing.T) {
+		testFlashMessages(t, sessions.New(sessions.Config{Cookie: "mycustomsessionid"}))
+	})
+	t.Run("cookie store", func(t *testing.T) {
+		testFlashMessages(t, sessions.New(sessions.Config{
+			Cookie:      "mycustomsessionid",
+			CookieStore: &sessions.CookieStore{Keys: [][]byte{[]byte("secret")}},
+		}))
+	})
+}
 
-	sess := sessions.New(sessions.Config{Cookie: "mycustomsessionid"})
+func testFlashMessages(t *testing.T, sess *sessions.Sessions) {
+	app := iris.New()
 
 	valueSingleKey := "Name"
 	valueSingleValue := "iris-sessions"
@@ -197,3 +214,449 @@ func TestFlashMessages(t *testing.T) {
 	e.POST("/set").WithJSON(values).Expect().Status(iris.StatusOK)
 	e.GET("/get_single").Expect().Status(iris.StatusOK).Body().Equal(valueSingleValue)
 }
+
+func TestCookieStore(t *testing.T) {
+	app := iris.New()
+
+	sess := sessions.New(sessions.Config{
+		Cookie:      "mycustomsessionid",
+		CookieStore: &sessions.CookieStore{Keys: [][]byte{[]byte("secret")}},
+	})
+	testSessions(t, sess, app)
+}
+
+func TestCookieStoreKeysAndSize(t *testing.T) {
+	newExpect := func(keys ...string) *httpexpect.Expect {
+		store := &sessions.CookieStore{ChunkSize: 100, MaxSize: 600}
+		for _, key := range keys {
+			store.Keys = append(store.Keys, []byte(key))
+		}
+
+		sess := sessions.New(sessions.Config{Cookie: "session", CookieStore: store})
+
+		app := iris.New()
+		app.Logger().SetLevel("disable")
+		app.Get("/set/{value}", func(ctx context.Context) {
+			sess.Start(ctx).Set("value", ctx.Params().Get("value"))
+		})
+		app.Get("/get", func(ctx context.Context) {
+			ctx.WriteString(sess.Start(ctx).GetString("value"))
+		})
+
+		return httptest.New(t, app, httptest.URL("http://example.com"))
+	}
+
+	get := func(e *httpexpect.Expect, cookies []*http.Cookie) *httpexpect.String {
+		req := e.GET("/get")
+		for _, c := range cookies {
+			req.WithCookie(c.Name, c.Value)
+		}
+		return req.Expect().Status(iris.StatusOK).Body()
+	}
+
+	e := newExpect("old")
+	cookies := e.GET("/set/value").Expect().Status(iris.StatusOK).Raw().Cookies()
+	// the session is split into more than one cookies.
+	if len(cookies) < 2 || cookies[0].Name != "session" || cookies[1].Name != "session.1" {
+		t.Fatalf("expected the session to be split into chunks but got: %v", cookies)
+	}
+	e.GET("/get").Expect().Status(iris.StatusOK).Body().Equal("value")
+
+	// a new key is prepended, the sessions of the old key are still valid.
+	get(newExpect("new", "old"), cookies).Equal("value")
+	// the key was removed.
+	get(newExpect("new"), cookies).Empty()
+
+	// a tampered session cookie starts a new session.
+	tampered := make([]*http.Cookie, len(cookies))
+	copy(tampered, cookies)
+	tampered[1] = &http.Cookie{Name: cookies[1].Name, Value: strings.Repeat("a", len(cookies[1].Value))}
+	get(newExpect("old"), tampered).Empty()
+
+	// a session larger than the maximum size is not sent, the previous one is kept.
+	e.GET("/set/" + strings.Repeat("a", 600)).Expect().Status(iris.StatusOK)
+	e.GET("/get").Expect().Status(iris.StatusOK).Body().Equal("value")
+}
+
+// failingDatabase is a session database which fails to write the "fail" key.
+type failingDatabase struct {
+	sessions.DatabaseV2
+}
+
+var errWrite = errors.New("write failed")
+
+func (db *failingDatabase) Set(ctx stdContext.Context, sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) error {
+	if key == "fail" {
+		return errWrite
+	}
+
+	return db.DatabaseV2.Set(ctx, sid, lifetime, key, value, immutable)
+}
+
+type memoryDatabase struct {
+	values map[string]map[string]interface{}
+}
+
+func (db *memoryDatabase) Acquire(sid string, expires time.Duration) sessions.LifeTime {
+	if db.values[sid] == nil {
+		db.values[sid] = make(map[string]interface{})
+	}
+	return sessions.LifeTime{}
+}
+func (db *memoryDatabase) OnUpdateExpiration(sid string, newExpires time.Duration) error { return nil }
+func (db *memoryDatabase) Set(sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) {
+	db.values[sid][key] = value
+}
+func (db *memoryDatabase) Get(sid string, key string) interface{} { return db.values[sid][key] }
+func (db *memoryDatabase) Visit(sid string, cb func(key string, value interface{})) {
+	for k, v := range db.values[sid] {
+		cb(k, v)
+	}
+}
+func (db *memoryDatabase) Len(sid string) int { return len(db.values[sid]) }
+func (db *memoryDatabase) Delete(sid string, key string) bool {
+	_, ok := db.values[sid][key]
+	delete(db.values[sid], key)
+	return ok
+}
+func (db *memoryDatabase) Clear(sid string)   { db.values[sid] = make(map[string]interface{}) }
+func (db *memoryDatabase) Release(sid string) { delete(db.values, sid) }
+
+func TestDatabaseV2Errors(t *testing.T) {
+	adapted := sessions.AdaptDatabase(&memoryDatabase{values: make(map[string]map[string]interface{})})
+
+	canceled, cancel := stdContext.WithCancel(stdContext.Background())
+	cancel()
+	if err := adapted.Set(canceled, "sid", sessions.LifeTime{}, "key", "value", false); err != stdContext.Canceled {
+		t.Fatalf("expected the error of the canceled context but got: %v", err)
+	}
+
+	var reported []error
+	sess := sessions.New(sessions.Config{
+		Cookie: "sessionid",
+		OnError: func(ctx context.Context, err error) {
+			reported = append(reported, err)
+		},
+	})
+	sess.UseDatabaseV2(&failingDatabase{adapted})
+
+	app := iris.New()
+	app.Use(sess.Handler())
+	app.Get("/set/{key}", func(ctx context.Context) {
+		s := sessions.Get(ctx)
+		if err := s.SetContext(ctx.Request().Context(), ctx.Params().Get("key"), "value"); err != nil {
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.WriteString(s.GetString(ctx.Params().Get("key")))
+	})
+	app.Get("/legacy/{key}", func(ctx context.Context) {
+		s := sessions.Get(ctx)
+		s.Set(ctx.Params().Get("key"), "value")
+		if err := s.Err(); err != nil {
+			ctx.WriteString(err.Error())
+		}
+	})
+
+	started, failed := make(chan struct{}), make(chan struct{})
+	app.Get("/concurrent", func(ctx context.Context) {
+		s := sessions.Get(ctx)
+		close(started)
+		<-failed
+		if err := s.Err(); err != nil {
+			ctx.WriteString(err.Error())
+		}
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/set/key").Expect().Status(iris.StatusOK).Body().Equal("value")
+	e.GET("/set/fail").Expect().Status(iris.StatusOK).Body().Equal(errWrite.Error())
+	if len(reported) != 0 {
+		t.Fatalf("expected the returned errors to not be reported but got: %v", reported)
+	}
+
+	e.GET("/legacy/fail").Expect().Status(iris.StatusOK).Body().Equal(errWrite.Error())
+	if len(reported) != 1 || reported[0] != errWrite {
+		t.Fatalf("expected the recorded error to be reported once but got: %v", reported)
+	}
+
+	// the error is forgotten on the next request.
+	e.GET("/legacy/key").Expect().Status(iris.StatusOK).Body().Empty()
+	if len(reported) != 1 {
+		t.Fatalf("expected no more reported errors but got: %v", reported)
+	}
+
+	// the error is not seen by a concurrent request of the same session.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.GET("/concurrent").Expect().Status(iris.StatusOK).Body().Empty()
+	}()
+	<-started
+	e.GET("/legacy/fail").Expect().Status(iris.StatusOK).Body().Equal(errWrite.Error())
+	close(failed)
+	<-done
+}
+
+func TestRegenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   sessions.Config
+		database sessions.Database
+	}{
+		{name: "memory", config: sessions.Config{Cookie: "sessionid"}},
+		{name: "database", config: sessions.Config{Cookie: "sessionid"},
+			database: &memoryDatabase{values: make(map[string]map[string]interface{})}},
+		{name: "cookie store", config: sessions.Config{Cookie: "sessionid",
+			CookieStore: &sessions.CookieStore{Keys: [][]byte{[]byte("secret")}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := sessions.New(tt.config)
+			if tt.database != nil {
+				sess.UseDatabase(tt.database)
+			}
+
+			var regenerated [][2]string
+			sess.OnRegenerate(func(oldSID, newSID string) {
+				regenerated = append(regenerated, [2]string{oldSID, newSID})
+			})
+
+			app := iris.New()
+			app.Get("/set", func(ctx context.Context) {
+				s := sess.Start(ctx)
+				s.Set("name", "iris")
+				s.SetImmutable("immutable", "value")
+				ctx.WriteString(s.ID())
+			})
+			app.Get("/login", func(ctx context.Context) {
+				s, err := sess.Regenerate(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				s.SetFlash("message", "logged in")
+				ctx.WriteString(s.ID())
+			})
+			app.Get("/get", func(ctx context.Context) {
+				s := sess.Start(ctx)
+				ctx.Writef("%s %s %s %s", s.ID(), s.GetString("name"), s.GetString("immutable"), s.GetFlashString("message"))
+			})
+
+			e := httptest.New(t, app, httptest.URL("http://example.com"))
+			resp := e.GET("/set").Expect().Status(iris.StatusOK)
+			oldSID, oldCookie := resp.Body().Raw(), resp.Cookie("sessionid").Value().Raw()
+
+			resp = e.GET("/login").Expect().Status(iris.StatusOK)
+			resp.Cookies().NotEmpty()
+			newSID := resp.Body().Raw()
+			if newSID == oldSID || newSID == "" {
+				t.Fatalf("expected a new session id but got: %q", newSID)
+			}
+
+			if len(regenerated) != 1 || regenerated[0] != [2]string{oldSID, newSID} {
+				t.Fatalf("expected the regenerate listener to be fired once with: %s, %s but got: %v", oldSID, newSID, regenerated)
+			}
+
+			if tt.config.CookieStore != nil {
+				// the cookie store can't revoke a copy of the old cookie.
+				e.GET("/get").Expect().Status(iris.StatusOK).Body().Equal(newSID + " iris value logged in")
+				e.GET("/get").WithCookie("sessionid", oldCookie).Expect().Status(iris.StatusOK).
+					Body().Equal(oldSID + " iris value ")
+				return
+			}
+
+			e.GET("/get").Expect().Status(iris.StatusOK).Body().Equal(newSID + " iris value logged in")
+			// the old session is removed.
+			e.GET("/get").WithCookie("sessionid", oldCookie).Expect().Status(iris.StatusOK).
+				Body().Equal(oldSID + "   ")
+		})
+	}
+}
+
+// blockingDatabase is a session database which blocks its Regenerate until "release" is closed.
+type blockingDatabase struct {
+	sessions.DatabaseV2
+	regenerating, release chan struct{}
+}
+
+func (db *blockingDatabase) Regenerate(ctx stdContext.Context, oldSID, newSID string) error {
+	close(db.regenerating)
+	<-db.release
+	return db.DatabaseV2.(sessions.RegeneratorV2).Regenerate(ctx, oldSID, newSID)
+}
+
+func TestRegenerateDoesNotBlockOtherSessions(t *testing.T) {
+	db := &blockingDatabase{
+		DatabaseV2:   sessions.AdaptDatabase(&memoryDatabase{values: make(map[string]map[string]interface{})}),
+		regenerating: make(chan struct{}),
+		release:      make(chan struct{}),
+	}
+	sess := sessions.New(sessions.Config{Cookie: "sessionid"})
+	sess.UseDatabaseV2(db)
+
+	app := iris.New()
+	app.Get("/set", func(ctx context.Context) {
+		sess.Start(ctx).Set("name", "iris")
+	})
+	app.Get("/login", func(ctx context.Context) {
+		s, err := sess.Regenerate(ctx)
+		if err != nil {
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.WriteString(s.GetString("name"))
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/set").Expect().Status(iris.StatusOK)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.GET("/login").Expect().Status(iris.StatusOK).Body().Equal("iris")
+	}()
+	<-db.regenerating
+
+	// a new session starts while the database regenerates the other one.
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		httptest.New(t, app, httptest.URL("http://example.com")).GET("/set").Expect().Status(iris.StatusOK)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a session to start while another one is regenerated")
+	}
+
+	close(db.release)
+	<-done
+}
+
+func TestAdmin(t *testing.T) {
+	cfg := sessions.Config{Cookie: "sessionid", Expires: time.Hour}
+	sess := sessions.New(cfg)
+
+	var destroyed []string
+	sess.OnDestroy(func(sid string) {
+		destroyed = append(destroyed, sid)
+	})
+
+	app := iris.New()
+	app.Get("/login/{id:int}", sess.Handler(), func(ctx context.Context) {
+		id, _ := ctx.Params().GetInt("id")
+		if err := sess.Index(ctx, "user_id", id); err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			ctx.WriteString(err.Error())
+			return
+		}
+		ctx.WriteString(sessions.Get(ctx).ID())
+	})
+	app.Get("/regenerate", func(ctx context.Context) {
+		s, err := sess.Regenerate(ctx)
+		if err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			ctx.WriteString(err.Error())
+			return
+		}
+		ctx.WriteString(s.ID())
+	})
+	app.Get("/switch/{id:int}", func(ctx context.Context) {
+		id, _ := ctx.Params().GetInt("id")
+		// not indexed.
+		sess.Start(ctx).Set("user_id", id)
+	})
+	app.Get("/forget", func(ctx context.Context) {
+		// keeps the session, acts like a new device on the next request.
+		sessions.RemoveCookie(ctx, cfg)
+	})
+	admin := sess.AdminHandler()
+	app.Get("/admin", admin)
+	app.Delete("/admin", admin)
+	app.Delete("/admin/{id}", admin)
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	var sids []string
+	for _, id := range []string{"1", "1", "2"} {
+		sids = append(sids, e.GET("/login/"+id).Expect().Status(iris.StatusOK).Body().Raw())
+		if id == "2" {
+			// the index follows the new session id.
+			sids[2] = e.GET("/regenerate").Expect().Status(iris.StatusOK).Body().Raw()
+		}
+		e.GET("/forget").Expect().Status(iris.StatusOK)
+	}
+
+	// a session of a changed value is not found by the index of its previous value.
+	e.GET("/login/1").Expect().Status(iris.StatusOK)
+	e.GET("/switch/3").Expect().Status(iris.StatusOK)
+	e.GET("/forget").Expect().Status(iris.StatusOK)
+	if found, err := sess.Find(stdContext.Background(), "user_id", 3); err != nil || len(found) != 0 {
+		t.Fatalf("expected the not indexed session to not be found but got: %v, %v", found, err)
+	}
+
+	var listed []sessions.SessionInfo
+	if err := sess.VisitSessions(stdContext.Background(), func(info sessions.SessionInfo) bool {
+		listed = append(listed, info)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != len(sids)+1 {
+		t.Fatalf("expected %d sessions but got: %v", len(sids)+1, listed)
+	}
+	for _, info := range listed {
+		if info.LastAccess.IsZero() || !info.Expires.After(time.Now()) {
+			t.Fatalf("expected the last access and expiration of the session but got: %#v", info)
+		}
+	}
+
+	// the values are compared by their string form.
+	found, err := sess.Find(stdContext.Background(), "user_id", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected the 2 sessions of the user but got: %v", found)
+	}
+
+	e.GET("/admin").Expect().Status(iris.StatusOK).JSON().Array().Length().Equal(4)
+	e.GET("/admin").WithQuery("key", "user_id").WithQuery("value", 2).Expect().Status(iris.StatusOK).
+		JSON().Array().Element(0).Object().ValueEqual("id", sids[2])
+
+	e.DELETE("/admin").Expect().Status(iris.StatusBadRequest)
+	e.DELETE("/admin").WithQuery("key", "user_id").WithQuery("value", 1).Expect().Status(iris.StatusOK).
+		JSON().Object().ValueEqual("destroyed", 2)
+	if len(destroyed) != 2 {
+		t.Fatalf("expected the destroy listener to be fired for the 2 sessions but got: %v", destroyed)
+	}
+
+	e.DELETE("/admin/" + sids[2]).Expect().Status(iris.StatusNoContent)
+	e.GET("/admin").Expect().Status(iris.StatusOK).JSON().Array().Length().Equal(1)
+
+	// the sessions of a database which does not keep an index are scanned.
+	scanned := sessions.New(cfg)
+	scanned.UseDatabase(&memoryDatabase{values: make(map[string]map[string]interface{})})
+	app = iris.New()
+	app.Get("/login/{id:int}", func(ctx context.Context) {
+		id, _ := ctx.Params().GetInt("id")
+		if err := scanned.Index(ctx, "user_id", id); err != sessions.ErrNotImplemented {
+			t.Errorf("expected ErrNotImplemented but got: %v", err)
+		}
+		scanned.Start(ctx).Set("user_id", id)
+	})
+	httptest.New(t, app, httptest.URL("http://example.com")).GET("/login/1").Expect().Status(iris.StatusOK)
+	if found, err := scanned.Find(stdContext.Background(), "user_id", 1); err != nil || len(found) != 1 {
+		t.Fatalf("expected the session to be found by a scan but got: %v, %v", found, err)
+	}
+
+	cookieStore := sessions.New(sessions.Config{Cookie: "sessionid",
+		CookieStore: &sessions.CookieStore{Keys: [][]byte{[]byte("secret")}}})
+	app = iris.New()
+	app.Get("/admin", cookieStore.AdminHandler())
+	httptest.New(t, app).GET("/admin").Expect().Status(iris.StatusNotImplemented)
+}
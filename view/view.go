@@ -36,6 +36,22 @@ func (v *View) Len() int {
 	return len(v.engines)
 }
 
+// Engines returns the view engines registered so far.
+func (v *View) Engines() []Engine {
+	return v.engines
+}
+
+// Has reports whether the "e" view engine is registered.
+func (v *View) Has(e Engine) bool {
+	for _, registered := range v.engines {
+		if registered == e {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ExecuteWriter calls the correct view Engine's ExecuteWriter func
 func (v *View) ExecuteWriter(w io.Writer, filename string, layout string, bindingData interface{}) error {
 	if len(filename) > 2 {